@@ -1,43 +1,35 @@
 package main
 
 import (
-	"bytes"
-	"encoding/json"
+	"context"
 	"fmt"
 	"log"
-	"net/http"
 	"strings"
 	"sync"
 
 	"github.com/go-faker/faker/v4"
 
-	"github.com/grishkovelli/betera-mailqusrv/internal/entities"
+	"github.com/grishkovelli/betera-mailqusrv/pkg/client"
 )
 
 func main() {
 	requests := 50
+	c := client.New("http://app:3000")
 
 	wg := sync.WaitGroup{}
 	wg.Add(requests)
 	for range requests {
 		go func() {
-			body := entities.CreateEmail{
+			defer wg.Done()
+
+			_, err := c.Send(context.Background(), client.CreateEmail{
 				To:      randomEmail(),
 				Subject: faker.Word(),
 				Body:    faker.Word(),
-			}
-
-			jsn, err := json.Marshal(body)
-			if err != nil {
-				log.Fatalf("Failed serialization: %v", err)
-			}
-
-			resp, err := http.Post("http://app:3000/send-email", "application/json", bytes.NewBuffer(jsn))
+			})
 			if err != nil {
 				log.Fatalf("Failed request %v", err)
 			}
-			defer resp.Body.Close()
-			defer wg.Done()
 		}()
 	}
 
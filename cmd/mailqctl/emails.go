@@ -0,0 +1,364 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+
+	"github.com/grishkovelli/betera-mailqusrv/pkg/client"
+)
+
+// maxBatchSize is the number of emails the API queues with one batch request.
+const maxBatchSize = 100
+
+// enqueue queues one email built from flags, or the emails of a JSON file or stdin.
+func (c *cli) enqueue(ctx context.Context, args []string) error {
+	fs := c.flagSet("enqueue", "")
+	var email client.CreateEmail
+	fs.StringVar(&email.To, "to", "", "recipient address, reads emails from stdin if empty")
+	fs.StringVar(&email.Subject, "subject", "", "subject")
+	fs.StringVar(&email.Body, "body", "", "plain text body")
+	fs.StringVar(&email.HTML, "html", "", "HTML body")
+	fs.StringVar(&email.From, "from", "", "verified identity to send from")
+	fs.StringVar(&email.Provider, "provider", "", "provider to deliver through")
+	fs.StringVar(&email.Category, "category", "", "list or category of a bulk email")
+	fs.BoolVar(&email.Track, "track", false, "track opens and clicks of the HTML body")
+	file := fs.String("file", "", "JSON file with an email, an array of emails or one email per line, - for stdin")
+	key := fs.String("idempotency-key", "", "idempotency key of a single email")
+	if err := fs.Parse(args); err != nil {
+		return usageError(err)
+	}
+	if fs.NArg() > 0 || (*file != "" && email.To != "") {
+		fs.Usage()
+		return errUsage
+	}
+
+	emails := []client.CreateEmail{email}
+	if email.To == "" {
+		r := c.stdin
+		if *file != "" && *file != "-" {
+			f, err := os.Open(*file)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			r = f
+		}
+
+		var err error
+		if emails, err = readEmails(r); err != nil {
+			return err
+		}
+	}
+
+	if len(emails) == 1 {
+		var opts []client.RequestOption
+		if *key != "" {
+			opts = append(opts, client.WithIdempotencyKey(*key))
+		}
+		sent, err := c.client.Send(ctx, emails[0], opts...)
+		if err != nil {
+			return err
+		}
+
+		return c.printEmails([]client.Email{sent})
+	}
+	if *key != "" {
+		return errors.New("-idempotency-key only applies to a single email")
+	}
+
+	return c.enqueueBatch(ctx, emails)
+}
+
+// enqueueBatch queues the emails in batches, the results are printed in the order of the emails.
+func (c *cli) enqueueBatch(ctx context.Context, emails []client.CreateEmail) error {
+	results := make([]client.BatchResult, 0, len(emails))
+	for chunk := range slices.Chunk(emails, maxBatchSize) {
+		res, err := c.client.SendBatch(ctx, chunk)
+		if err != nil {
+			return fmt.Errorf("queue emails %d-%d: %w", len(results)+1, len(results)+len(chunk), err)
+		}
+		results = append(results, res...)
+	}
+
+	err := c.print(results, func(t *table) {
+		t.row("#", "ID", "TO", "STATUS", "ERROR")
+		for i, res := range results {
+			if res.Error != nil {
+				t.row(i+1, "", emails[i].To, "", res.Error.Message)
+				continue
+			}
+			t.row(i+1, res.Email.ID, res.Email.To, res.Email.Status, "")
+		}
+	})
+	if err != nil {
+		return err
+	}
+	failed := 0
+	for _, res := range results {
+		if res.Error != nil {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d emails were not queued", failed, len(results))
+	}
+
+	return nil
+}
+
+// readEmails decodes an email, an array of emails or a stream of emails.
+func readEmails(r io.Reader) ([]client.CreateEmail, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return nil, errors.New("no emails to queue")
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+
+	var emails []client.CreateEmail
+	if data[0] == '[' {
+		if err = dec.Decode(&emails); err != nil {
+			return nil, fmt.Errorf("decode emails: %w", err)
+		}
+	} else {
+		for {
+			var email client.CreateEmail
+			if err = dec.Decode(&email); errors.Is(err, io.EOF) {
+				break
+			} else if err != nil {
+				return nil, fmt.Errorf("decode email %d: %w", len(emails)+1, err)
+			}
+			emails = append(emails, email)
+		}
+	}
+	if len(emails) == 0 {
+		return nil, errors.New("no emails to queue")
+	}
+
+	return emails, nil
+}
+
+// list prints emails by status or recipient.
+func (c *cli) list(ctx context.Context, args []string) error {
+	fs := c.flagSet("list", "")
+	status := fs.String("status", "", "status of the emails, required without -to")
+	to := fs.String("to", "", "recipient address")
+	cursor := fs.Int("cursor", 0, "list emails with an ID greater than this one")
+	all := fs.Bool("all", false, "fetch all pages")
+	if err := fs.Parse(args); err != nil {
+		return usageError(err)
+	}
+	if fs.NArg() > 0 || (*status == "" && *to == "") {
+		fs.Usage()
+		return errUsage
+	}
+
+	var emails []client.Email
+	next := *cursor
+	for {
+		var page []client.Email
+		var err error
+		if *to != "" {
+			page, err = c.client.ListByRecipient(ctx, *to, client.Status(*status), next)
+		} else {
+			page, err = c.client.List(ctx, client.Status(*status), next)
+		}
+		if err != nil {
+			return err
+		}
+		if len(page) == 0 {
+			next = 0
+			break
+		}
+
+		emails = append(emails, page...)
+		next = page[len(page)-1].ID
+		if !*all {
+			break
+		}
+	}
+
+	if err := c.printEmails(emails); err != nil {
+		return err
+	}
+	if next > 0 && c.format == formatTable {
+		fmt.Fprintf(c.stderr, "next page: -cursor %d\n", next)
+	}
+
+	return nil
+}
+
+// show prints one email.
+func (c *cli) show(ctx context.Context, args []string) error {
+	fs := c.flagSet("show", "<id>")
+	if err := fs.Parse(args); err != nil {
+		return usageError(err)
+	}
+	ids, err := parseIDs(fs, 1)
+	if err != nil {
+		return err
+	}
+
+	email, err := c.client.Get(ctx, ids[0])
+	if err != nil {
+		return err
+	}
+
+	return c.print(email, func(t *table) {
+		t.row("ID", email.ID)
+		t.row("TO", email.To)
+		t.row("SUBJECT", email.Subject)
+		t.row("STATUS", email.Status)
+		t.row("ATTEMPTS", email.Attempts)
+		t.row("PROVIDER", email.Provider)
+		t.row("SENT PROVIDER", email.SentProvider)
+		t.row("FROM", email.From)
+		t.row("FROM NAME", email.FromName)
+		t.row("REPLY TO", email.ReplyTo)
+		t.row("CATEGORY", email.Category)
+		t.row("TRACK", email.Track)
+		t.row("MESSAGE ID", email.MessageID)
+		t.row("REQUEST ID", email.RequestID)
+		t.row("BODY", email.Body)
+		t.row("HTML", email.HTML)
+	})
+}
+
+// transition moves each email with action, emails that could not be moved do not stop the others.
+func (c *cli) transition(
+	ctx context.Context,
+	action string,
+	args []string,
+	move func(ctx context.Context, id int, opts ...client.RequestOption) (client.Email, error),
+) error {
+	fs := c.flagSet(action, "<id>...")
+	if err := fs.Parse(args); err != nil {
+		return usageError(err)
+	}
+	ids, err := parseIDs(fs, 0)
+	if err != nil {
+		return err
+	}
+
+	var emails []client.Email
+	var errs []error
+	for _, id := range ids {
+		email, err := move(ctx, id)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s email %d: %w", action, id, err))
+			continue
+		}
+		emails = append(emails, email)
+	}
+
+	if len(emails) > 0 {
+		if err = c.printEmails(emails); err != nil {
+			return err
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// purge cancels all queued emails matching a filter.
+func (c *cli) purge(ctx context.Context, args []string) error {
+	fs := c.flagSet("purge", "")
+	status := fs.String("status", "", "status of the emails to cancel, pending or failed")
+	to := fs.String("to", "", "recipient address of the emails to cancel")
+	yes := fs.Bool("yes", false, "confirm cancelling all matching emails")
+	if err := fs.Parse(args); err != nil {
+		return usageError(err)
+	}
+	if fs.NArg() > 0 || (*status == "" && *to == "") {
+		fs.Usage()
+		return errUsage
+	}
+	if !*yes {
+		return errors.New("purge cancels every matching email and cannot be undone, confirm with -yes")
+	}
+
+	cancelled, err := c.client.BulkCancel(ctx, client.EmailFilter{Status: client.Status(*status), To: *to})
+	if err != nil {
+		return err
+	}
+
+	return c.print(map[string]int64{"cancelled": cancelled}, func(t *table) {
+		t.row("CANCELLED", cancelled)
+	})
+}
+
+// stats prints email counts per status and engagement.
+func (c *cli) stats(ctx context.Context, args []string) error {
+	fs := c.flagSet("stats", "")
+	if err := fs.Parse(args); err != nil {
+		return usageError(err)
+	}
+	if fs.NArg() > 0 {
+		fs.Usage()
+		return errUsage
+	}
+
+	stats, err := c.client.Stats(ctx)
+	if err != nil {
+		return err
+	}
+
+	return c.print(stats, func(t *table) {
+		t.row("STATUS", "COUNT")
+		for _, s := range []client.Status{
+			client.Pending, client.Processing, client.Sent, client.Failed, client.Dead,
+			client.Rejected, client.Cancelled, client.Suppressed, client.Bounced,
+		} {
+			t.row(s, stats.Statuses[s])
+		}
+		t.row("", "")
+		t.row("OPENS", stats.Opens)
+		t.row("UNIQUE OPENS", stats.UniqueOpens)
+		t.row("CLICKS", stats.Clicks)
+		t.row("UNIQUE CLICKS", stats.UniqueClicks)
+	})
+}
+
+// printEmails prints emails as a table with a row per email.
+func (c *cli) printEmails(emails []client.Email) error {
+	return c.print(emails, func(t *table) {
+		t.row("ID", "TO", "SUBJECT", "STATUS", "ATTEMPTS", "PROVIDER")
+		for _, e := range emails {
+			t.row(e.ID, e.To, truncate(e.Subject), e.Status, e.Attempts, e.SentProvider)
+		}
+	})
+}
+
+// parseIDs parses the positional arguments as email or suppression IDs, n > 0 requires exactly n of them.
+func parseIDs(fs *flag.FlagSet, n int) ([]int, error) {
+	args := fs.Args()
+	if len(args) == 0 || (n > 0 && len(args) != n) {
+		fs.Usage()
+		return nil, errUsage
+	}
+
+	ids := make([]int, len(args))
+	for i, arg := range args {
+		id, err := strconv.Atoi(arg)
+		if err != nil || id <= 0 {
+			return nil, fmt.Errorf("invalid id: %s", arg)
+		}
+		ids[i] = id
+	}
+
+	return ids, nil
+}
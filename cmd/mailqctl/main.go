@@ -0,0 +1,228 @@
+// Command mailqctl manages the mail queue through the HTTP API.
+//
+// Usage:
+//
+//	mailqctl [global flags] <command> [flags] [args]
+//
+// Global flags default to the MAILQCTL_* environment variables, see mailqctl -h.
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/grishkovelli/betera-mailqusrv/pkg/client"
+)
+
+// Exit codes.
+const (
+	exitFailure = 1
+	exitUsage   = 2
+)
+
+// Output formats.
+const (
+	formatTable = "table"
+	formatJSON  = "json"
+)
+
+const defaultTimeout = 30 * time.Second
+
+// errUsage reports invalid command line arguments, the usage has already been printed.
+var errUsage = errors.New("invalid usage")
+
+const usage = `Usage: mailqctl [global flags] <command> [flags] [args]
+
+Commands:
+  enqueue              queue emails from flags, a JSON file (-file) or stdin
+  list                 list emails by status or recipient
+  show <id>            show one email
+  retry <id>...        retry failed, dead or suppressed emails
+  cancel <id>...       cancel pending or failed emails
+  purge                cancel all queued emails matching a filter
+  stats                show email counts per status and engagement
+  suppressions <cmd>   list, add, show or remove suppressed addresses
+
+Run mailqctl <command> -h for the flags of a command.
+
+Global flags:
+`
+
+// cli holds the state shared by all commands.
+type cli struct {
+	client *client.Client
+	format string
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	err := run(ctx, os.Args[1:], os.Getenv, os.Stdin, os.Stdout, os.Stderr)
+	stop()
+
+	switch {
+	case errors.Is(err, errUsage):
+		os.Exit(exitUsage)
+	case err != nil:
+		fmt.Fprintln(os.Stderr, "mailqctl:", err)
+		os.Exit(exitFailure)
+	}
+}
+
+// run parses the global flags and runs the command.
+func run(
+	ctx context.Context,
+	args []string,
+	getenv func(string) string,
+	stdin io.Reader,
+	stdout, stderr io.Writer,
+) error {
+	fs := flag.NewFlagSet("mailqctl", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprint(stderr, usage)
+		fs.PrintDefaults()
+	}
+
+	baseURL := fs.String("url", envOr(getenv, "MAILQCTL_URL", "http://localhost:3000"),
+		"base URL of the API (MAILQCTL_URL)")
+	token := fs.String("token", getenv("MAILQCTL_TOKEN"),
+		"bearer token sent in the Authorization header (MAILQCTL_TOKEN)")
+	user := fs.String("user", getenv("MAILQCTL_USER"), "basic auth user name (MAILQCTL_USER)")
+	password := fs.String("password", getenv("MAILQCTL_PASSWORD"), "basic auth password (MAILQCTL_PASSWORD)")
+	format := fs.String("output", envOr(getenv, "MAILQCTL_OUTPUT", formatTable),
+		"output format, table or json (MAILQCTL_OUTPUT)")
+	timeout := fs.Duration("timeout", defaultTimeout, "timeout of the whole command, 0 disables it")
+	var headers headerFlag
+	fs.Var(&headers, "header", "extra request header as `Name: value`, can be repeated")
+
+	if err := fs.Parse(args); err != nil {
+		return usageError(err)
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return errUsage
+	}
+	if *format != formatTable && *format != formatJSON {
+		fmt.Fprintf(stderr, "unknown output format %q\n", *format)
+		return errUsage
+	}
+	if *token != "" && (*user != "" || *password != "") {
+		fmt.Fprintln(stderr, "-token and -user/-password are mutually exclusive")
+		return errUsage
+	}
+
+	opts := []client.Option{}
+	for _, h := range headers {
+		opts = append(opts, client.WithHeader(h[0], h[1]))
+	}
+	switch {
+	case *token != "":
+		opts = append(opts, client.WithHeader("Authorization", "Bearer "+*token))
+	case *user != "" || *password != "":
+		creds := base64.StdEncoding.EncodeToString([]byte(*user + ":" + *password))
+		opts = append(opts, client.WithHeader("Authorization", "Basic "+creds))
+	}
+
+	if *timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
+	}
+
+	c := &cli{
+		client: client.New(*baseURL, opts...),
+		format: *format,
+		stdin:  stdin,
+		stdout: stdout,
+		stderr: stderr,
+	}
+
+	return c.dispatch(ctx, fs.Arg(0), fs.Args()[1:])
+}
+
+// dispatch runs the named command.
+func (c *cli) dispatch(ctx context.Context, name string, args []string) error {
+	switch name {
+	case "enqueue":
+		return c.enqueue(ctx, args)
+	case "list":
+		return c.list(ctx, args)
+	case "show":
+		return c.show(ctx, args)
+	case "retry":
+		return c.transition(ctx, "retry", args, c.client.Retry)
+	case "cancel":
+		return c.transition(ctx, "cancel", args, c.client.Cancel)
+	case "purge":
+		return c.purge(ctx, args)
+	case "stats":
+		return c.stats(ctx, args)
+	case "suppressions":
+		return c.suppressions(ctx, args)
+	default:
+		fmt.Fprintf(c.stderr, "unknown command %q, run mailqctl -h for the list of commands\n", name)
+		return errUsage
+	}
+}
+
+// flagSet creates the flag set of a command, its usage line lists the positional arguments.
+func (c *cli) flagSet(name, args string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+	fs.Usage = func() {
+		fmt.Fprintln(c.stderr, strings.TrimSpace("Usage: mailqctl "+name+" [flags] "+args))
+		fs.PrintDefaults()
+	}
+
+	return fs
+}
+
+// usageError converts flag parsing errors, -h is not an error.
+func usageError(err error) error {
+	if errors.Is(err, flag.ErrHelp) {
+		return nil
+	}
+
+	return errUsage
+}
+
+// envOr returns the environment variable or def if it is empty.
+func envOr(getenv func(string) string, key, def string) string {
+	if v := getenv(key); v != "" {
+		return v
+	}
+
+	return def
+}
+
+// headerFlag collects repeated -header flags.
+type headerFlag [][2]string
+
+func (h *headerFlag) String() string {
+	return ""
+}
+
+func (h *headerFlag) Set(value string) error {
+	name, v, ok := strings.Cut(value, ":")
+	name = strings.TrimSpace(name)
+	if !ok || name == "" {
+		return fmt.Errorf("header %q is not in the Name: value format", value)
+	}
+	*h = append(*h, [2]string{name, strings.TrimSpace(v)})
+
+	return nil
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// request is a request received by the stub API.
+type request struct {
+	method string
+	uri    string
+	auth   string
+	body   string
+}
+
+// stubAPI answers requests by method and path with canned JSON and records them.
+func stubAPI(t *testing.T, responses map[string]string) (*httptest.Server, *[]request) {
+	t.Helper()
+
+	var requests []request
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests = append(requests, request{r.Method, r.URL.RequestURI(), r.Header.Get("Authorization"), string(body)})
+
+		resp, ok := responses[r.Method+" "+r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			resp = `{"code":"not_found","message":"not found"}`
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, resp)
+	}))
+	t.Cleanup(ts.Close)
+
+	return ts, &requests
+}
+
+func runCLI(t *testing.T, ts *httptest.Server, stdin string, args ...string) (string, string, error) {
+	t.Helper()
+
+	env := map[string]string{"MAILQCTL_URL": ts.URL}
+	var stdout, stderr bytes.Buffer
+	getenv := func(key string) string { return env[key] }
+	err := run(t.Context(), args, getenv, strings.NewReader(stdin), &stdout, &stderr)
+
+	return stdout.String(), stderr.String(), err
+}
+
+func TestEnqueue(t *testing.T) {
+	ts, requests := stubAPI(t, map[string]string{
+		"POST /v1/send-email": `{"id":1,"to_address":"a@example.com","subject":"Hi","status":"pending"}`,
+		"POST /v1/send-email/batch": `{"results":[` +
+			`{"email":{"id":2,"to_address":"a@example.com","status":"pending"}},` +
+			`{"error":{"code":"unprocessable","message":"recipient is suppressed"}}]}`,
+	})
+
+	out, _, err := runCLI(t, ts, "", "-token", "secret", "enqueue", "-to", "a@example.com", "-subject", "Hi")
+	if err != nil {
+		t.Fatalf("enqueue from flags: %v", err)
+	}
+	if want := "1   a@example.com  Hi       pending"; !strings.Contains(out, want) {
+		t.Errorf("output = %q, want a row %q", out, want)
+	}
+	if got := (*requests)[0].auth; got != "Bearer secret" {
+		t.Errorf("Authorization = %q, want %q", got, "Bearer secret")
+	}
+
+	stdin := `{"to_address":"a@example.com","subject":"Hi","body":"Body"}` + "\n" +
+		`{"to_address":"b@example.com","subject":"Hi","body":"Body"}`
+	out, _, err = runCLI(t, ts, stdin, "-output", "json", "enqueue")
+	if err == nil || err.Error() != "1 of 2 emails were not queued" {
+		t.Errorf("enqueue from stdin error = %v, want the failed email reported", err)
+	}
+	var results []map[string]any
+	if err = json.Unmarshal([]byte(out), &results); err != nil || len(results) != 2 {
+		t.Errorf("output = %q, want the JSON results of both emails", out)
+	}
+	if got := (*requests)[1].body; !strings.Contains(got, `"b@example.com"`) {
+		t.Errorf("batch body = %s, want both emails", got)
+	}
+
+	if _, _, err = runCLI(t, ts, `{"to":"a@example.com"}`, "enqueue"); err == nil {
+		t.Error("enqueue with an unknown field succeeded")
+	}
+}
+
+func TestList(t *testing.T) {
+	ts, requests := stubAPI(t, map[string]string{
+		"GET /v1/emails": `[{"id":7,"to_address":"a@example.com","subject":"Hi","status":"sent","attempts":1}]`,
+	})
+
+	out, stderr, err := runCLI(t, ts, "", "-user", "admin", "-password", "secret", "list", "-to", "a@example.com")
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if !strings.HasPrefix(out, "ID  TO") || !strings.Contains(out, "7   a@example.com") {
+		t.Errorf("output = %q, want a table of the email", out)
+	}
+	if stderr != "next page: -cursor 7\n" {
+		t.Errorf("stderr = %q, want the next cursor", stderr)
+	}
+
+	req := (*requests)[0]
+	if req.uri != "/v1/emails?to_address=a%40example.com" {
+		t.Errorf("request URI = %q, want the recipient filter", req.uri)
+	}
+	if req.auth != "Basic YWRtaW46c2VjcmV0" {
+		t.Errorf("Authorization = %q, want basic auth", req.auth)
+	}
+
+	if _, _, err = runCLI(t, ts, "", "list"); !errors.Is(err, errUsage) {
+		t.Errorf("list without a filter error = %v, want %v", err, errUsage)
+	}
+}
+
+func TestPurge(t *testing.T) {
+	ts, requests := stubAPI(t, map[string]string{"POST /v1/emails/cancel": `{"updated":3}`})
+
+	if _, _, err := runCLI(t, ts, "", "purge", "-status", "pending"); err == nil {
+		t.Error("purge without -yes succeeded")
+	}
+	if len(*requests) != 0 {
+		t.Fatalf("purge without -yes sent %d requests", len(*requests))
+	}
+
+	out, _, err := runCLI(t, ts, "", "-output", "json", "purge", "-status", "pending", "-yes")
+	if err != nil {
+		t.Fatalf("purge: %v", err)
+	}
+	if out != "{\n  \"cancelled\": 3\n}\n" {
+		t.Errorf("output = %q, want the number of cancelled emails", out)
+	}
+	if got := (*requests)[0].body; got != `{"status":"pending"}` {
+		t.Errorf("filter = %s, want the status filter", got)
+	}
+}
+
+func TestSuppressions(t *testing.T) {
+	ts, requests := stubAPI(t, map[string]string{
+		"POST /v1/suppressions": `{"id":4,"address":"a@example.com","reason":"manual","expires_at":null,` +
+			`"category":"news","created_at":"2025-01-02T03:04:05Z"}`,
+		"DELETE /v1/suppressions/4": ``,
+	})
+
+	out, _, err := runCLI(t, ts, "", "suppressions", "add", "-category", "news", "a@example.com")
+	if err != nil {
+		t.Fatalf("suppressions add: %v", err)
+	}
+	if want := "4   a@example.com  manual  news      never    2025-01-02T03:04:05Z"; !strings.Contains(out, want) {
+		t.Errorf("output = %q, want a row %q", out, want)
+	}
+
+	_, _, err = runCLI(t, ts, "", "suppressions", "remove", "4", "5")
+	if err == nil || !strings.Contains(err.Error(), "remove suppression 5") {
+		t.Errorf("suppressions remove error = %v, want the missing suppression reported", err)
+	}
+	if got := len(*requests); got != 3 {
+		t.Errorf("sent %d requests, want 3", got)
+	}
+}
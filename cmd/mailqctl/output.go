@@ -0,0 +1,50 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+)
+
+// maxCellWidth limits long free text like subjects in table rows.
+const maxCellWidth = 40
+
+// table aligns rows into columns.
+type table struct {
+	w *tabwriter.Writer
+}
+
+// row writes a row, cells are formatted with fmt and tabs and newlines in them are replaced by spaces.
+func (t *table) row(cells ...any) {
+	s := make([]string, len(cells))
+	for i, cell := range cells {
+		s[i] = strings.NewReplacer("\t", " ", "\r", " ", "\n", " ").Replace(fmt.Sprint(cell))
+	}
+	fmt.Fprintln(t.w, strings.Join(s, "\t"))
+}
+
+// print writes v as indented JSON or, in the table format, the rows written by fill.
+func (c *cli) print(v any, fill func(t *table)) error {
+	if c.format == formatJSON {
+		enc := json.NewEncoder(c.stdout)
+		enc.SetIndent("", "  ")
+
+		return enc.Encode(v)
+	}
+
+	t := &table{tabwriter.NewWriter(c.stdout, 0, 0, 2, ' ', 0)} //nolint:mnd // padding between columns
+	fill(t)
+
+	return t.w.Flush()
+}
+
+// truncate shortens s to maxCellWidth runes.
+func truncate(s string) string {
+	r := []rune(s)
+	if len(r) <= maxCellWidth {
+		return s
+	}
+
+	return string(r[:maxCellWidth-1]) + "…"
+}
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/grishkovelli/betera-mailqusrv/pkg/client"
+)
+
+// suppressions runs a suppression list subcommand.
+func (c *cli) suppressions(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		fmt.Fprintln(c.stderr, "Usage: mailqctl suppressions list|add|show|remove [flags] [args]")
+		return errUsage
+	}
+
+	switch args[0] {
+	case "list":
+		return c.listSuppressions(ctx, args[1:])
+	case "add":
+		return c.addSuppression(ctx, args[1:])
+	case "show":
+		return c.showSuppression(ctx, args[1:])
+	case "remove":
+		return c.removeSuppressions(ctx, args[1:])
+	default:
+		fmt.Fprintf(c.stderr, "unknown suppressions command %q, use list, add, show or remove\n", args[0])
+		return errUsage
+	}
+}
+
+// listSuppressions prints suppressed addresses.
+func (c *cli) listSuppressions(ctx context.Context, args []string) error {
+	fs := c.flagSet("suppressions list", "")
+	cursor := fs.Int("cursor", 0, "list suppressions with an ID greater than this one")
+	all := fs.Bool("all", false, "fetch all pages")
+	if err := fs.Parse(args); err != nil {
+		return usageError(err)
+	}
+	if fs.NArg() > 0 {
+		fs.Usage()
+		return errUsage
+	}
+
+	var suppressions []client.Suppression
+	next := *cursor
+	for {
+		page, err := c.client.ListSuppressions(ctx, next)
+		if err != nil {
+			return err
+		}
+		if len(page) == 0 {
+			next = 0
+			break
+		}
+
+		suppressions = append(suppressions, page...)
+		next = page[len(page)-1].ID
+		if !*all {
+			break
+		}
+	}
+
+	if err := c.printSuppressions(suppressions); err != nil {
+		return err
+	}
+	if next > 0 && c.format == formatTable {
+		fmt.Fprintf(c.stderr, "next page: -cursor %d\n", next)
+	}
+
+	return nil
+}
+
+// addSuppression suppresses an address.
+func (c *cli) addSuppression(ctx context.Context, args []string) error {
+	fs := c.flagSet("suppressions add", "<address>")
+	reason := fs.String("reason", string(client.Manual), "reason: bounce, complaint, unsubscribe or manual")
+	category := fs.String("category", "", "category to suppress the address for, empty means all emails")
+	expires := fs.String("expires", "", "when the suppression ends, as RFC 3339 time or a duration like 720h")
+	if err := fs.Parse(args); err != nil {
+		return usageError(err)
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return errUsage
+	}
+
+	s := client.CreateSuppression{
+		Address:  fs.Arg(0),
+		Reason:   client.SuppressionReason(*reason),
+		Category: *category,
+	}
+	if *expires != "" {
+		at, err := parseExpiry(*expires, time.Now())
+		if err != nil {
+			return err
+		}
+		s.ExpiresAt = &at
+	}
+
+	created, err := c.client.Suppress(ctx, s)
+	if err != nil {
+		return err
+	}
+
+	return c.printSuppressions([]client.Suppression{created})
+}
+
+// showSuppression prints one suppression.
+func (c *cli) showSuppression(ctx context.Context, args []string) error {
+	fs := c.flagSet("suppressions show", "<id>")
+	if err := fs.Parse(args); err != nil {
+		return usageError(err)
+	}
+	ids, err := parseIDs(fs, 1)
+	if err != nil {
+		return err
+	}
+
+	s, err := c.client.GetSuppression(ctx, ids[0])
+	if err != nil {
+		return err
+	}
+
+	return c.print(s, func(t *table) {
+		t.row("ID", s.ID)
+		t.row("ADDRESS", s.Address)
+		t.row("REASON", s.Reason)
+		t.row("CATEGORY", s.Category)
+		t.row("EXPIRES", formatExpiry(s.ExpiresAt))
+		t.row("CREATED", s.CreatedAt.Format(time.RFC3339))
+	})
+}
+
+// removeSuppressions removes suppressions, failed removals do not stop the others.
+func (c *cli) removeSuppressions(ctx context.Context, args []string) error {
+	fs := c.flagSet("suppressions remove", "<id>...")
+	if err := fs.Parse(args); err != nil {
+		return usageError(err)
+	}
+	ids, err := parseIDs(fs, 0)
+	if err != nil {
+		return err
+	}
+
+	removed := []int{}
+	var errs []error
+	for _, id := range ids {
+		if err = c.client.DeleteSuppression(ctx, id); err != nil {
+			errs = append(errs, fmt.Errorf("remove suppression %d: %w", id, err))
+			continue
+		}
+		removed = append(removed, id)
+	}
+
+	if len(removed) > 0 {
+		err = c.print(map[string][]int{"removed": removed}, func(t *table) {
+			t.row("REMOVED")
+			for _, id := range removed {
+				t.row(id)
+			}
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// printSuppressions prints suppressions as a table with a row per suppression.
+func (c *cli) printSuppressions(suppressions []client.Suppression) error {
+	return c.print(suppressions, func(t *table) {
+		t.row("ID", "ADDRESS", "REASON", "CATEGORY", "EXPIRES", "CREATED")
+		for _, s := range suppressions {
+			t.row(s.ID, s.Address, s.Reason, s.Category, formatExpiry(s.ExpiresAt), s.CreatedAt.Format(time.RFC3339))
+		}
+	})
+}
+
+// parseExpiry parses an RFC 3339 time or a duration from now.
+func parseExpiry(s string, now time.Time) (time.Time, error) {
+	if d, err := time.ParseDuration(s); err == nil {
+		if d <= 0 {
+			return time.Time{}, fmt.Errorf("expiry must be in the future: %s", s)
+		}
+		return now.Add(d), nil
+	}
+
+	at, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid expiry %q, use an RFC 3339 time or a duration like 720h", s)
+	}
+
+	return at, nil
+}
+
+// formatExpiry formats the end of a suppression, never if it does not end.
+func formatExpiry(at *time.Time) string {
+	if at == nil {
+		return "never"
+	}
+
+	return at.Format(time.RFC3339)
+}
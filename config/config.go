@@ -3,6 +3,7 @@ package config
 import (
 	"fmt"
 	"log"
+	"log/slog"
 
 	"github.com/caarlos0/env/v11"
 	"github.com/joho/godotenv"
@@ -26,21 +27,158 @@ func (d *DB) URL() string {
 }
 
 type Server struct {
-	Port              string `env:"PORT"`                // Server port number
-	PageSize          int    `env:"PAGE_SIZE"`           // Integer value for pagination size
-	ReadHeaderTimeout int    `env:"READ_HEADER_TIMEOUT"` // Used to limit execution time of the http.Handler
+	Port              string `env:"PORT"`                                     // Server port number
+	PageSize          int    `env:"PAGE_SIZE"`                                // Integer value for pagination size
+	ReadHeaderTimeout int    `env:"READ_HEADER_TIMEOUT"`                      // Used to limit execution time of the http.Handler
+	ReadTimeout       int    `env:"READ_TIMEOUT"        envDefault:"15"`      // Seconds to read a whole request
+	WriteTimeout      int    `env:"WRITE_TIMEOUT"       envDefault:"30"`      // Seconds to write a response, must exceed the request timeouts
+	IdleTimeout       int    `env:"IDLE_TIMEOUT"        envDefault:"60"`      // Seconds keep-alive connections are kept open
+	RequestTimeout    int    `env:"REQUEST_TIMEOUT"     envDefault:"10"`      // Seconds a handler may run, 0 means unlimited
+	BulkTimeout       int    `env:"BULK_TIMEOUT"        envDefault:"25"`      // Seconds a bulk cancel or retry may run, 0 means unlimited
+	ShutdownTimeout   int    `env:"SHUTDOWN_TIMEOUT"    envDefault:"15"`      // Seconds to wait for in-flight requests on shutdown
+	MaxBodySize       int64  `env:"MAX_BODY_SIZE"       envDefault:"1048576"` // Maximum request body size in bytes, 0 means unlimited
+	StrictJSON        bool   `env:"STRICT_JSON"`                              // Reject request bodies with unknown fields
+	APIToken          string `env:"API_TOKEN"`                                // Bearer token required by API routes, the API is open if empty
+}
+
+type GRPC struct {
+	Port          string `env:"PORT"`                          // gRPC server port, the gRPC server is disabled if empty
+	WatchInterval int    `env:"WATCH_INTERVAL" envDefault:"1"` // Seconds between status checks of watched emails
+}
+
+type SSE struct {
+	KeepaliveInterval int `env:"KEEPALIVE_INTERVAL" envDefault:"15"`  // Seconds between keepalive comments of idle streams
+	Retention         int `env:"RETENTION"          envDefault:"168"` // Hours status changes are kept for replays, 0 means forever
+	ReplayOverlap     int `env:"REPLAY_OVERLAP"     envDefault:"10"`  // Seconds of changes before the last event that are replayed again
+}
+
+type Admin struct {
+	Username string `env:"USERNAME" envDefault:"admin"` // Basic auth username of the dashboard
+	Password string `env:"PASSWORD"`                    // Basic auth password, the dashboard is disabled if empty
 }
 
 type Worker struct {
 	PoolSize           int `env:"POOL_SIZE"`            // Integer value for worker pool size
 	BatchSize          int `env:"BATCH_SIZE"`           // Integer value for batch processing size
 	StuckCheckInterval int `env:"STUCK_CHECK_INTERVAL"` // Integer value for checking stuck jobs interval
+	StuckTimeout       int `env:"STUCK_TIMEOUT"`        // Seconds an email stays in processing before it is requeued, 0 derives it
+	MaxAttempts        int `env:"MAX_ATTEMPTS"`         // Delivery attempts before an email is marked dead, 0 means unlimited
+}
+
+type Mail struct {
+	Domain       string `env:"DOMAIN"        envDefault:"mailqu.local"`         // Domain used for Message-IDs and bounce addresses
+	BouncePrefix string `env:"BOUNCE_PREFIX" envDefault:"bounces"`              // Local part prefix of VERP bounce addresses
+	BounceSecret string `env:"BOUNCE_SECRET"`                                   // Bearer token of bounce ingestion, disabled if empty
+	From         string `env:"FROM"          envDefault:"noreply@mailqu.local"` // From address of the default identity
+	FromName     string `env:"FROM_NAME"`                                       // Display name of the default identity
+	ReplyTo      string `env:"REPLY_TO"`                                        // Reply-To address of the default identity
+}
+
+type SMTP struct {
+	Addr     string `env:"ADDR"`     // SMTP relay host:port, the sender is disabled if empty
+	Username string `env:"USERNAME"` // PLAIN auth username, auth is skipped if empty
+	Password string `env:"PASSWORD"` // PLAIN auth password
+}
+
+type SendGrid struct {
+	URL    string `env:"URL"     envDefault:"https://api.sendgrid.com"` // API base URL
+	APIKey string `env:"API_KEY"`                                       // API key, the sender is disabled if empty
+}
+
+type Mailgun struct {
+	URL    string `env:"URL"     envDefault:"https://api.mailgun.net"` // API base URL
+	Domain string `env:"DOMAIN"`                                       // Sending domain
+	APIKey string `env:"API_KEY"`                                      // API key, the sender is disabled if empty
+}
+
+type SES struct {
+	URL             string `env:"URL"`                           // API base URL, defaults to the regional endpoint
+	Region          string `env:"REGION" envDefault:"us-east-1"` // AWS region
+	AccessKeyID     string `env:"ACCESS_KEY_ID"`                 // AWS access key, the sender is disabled if empty
+	SecretAccessKey string `env:"SECRET_ACCESS_KEY"`             // AWS secret key
+}
+
+type Sender struct {
+	Routes           []string `env:"ROUTES"`                                  // Failover order of providers as name[:priority[:weight]], required
+	Timeout          int      `env:"TIMEOUT"           envDefault:"10"`       // Timeout in seconds of a single delivery
+	BreakerThreshold int      `env:"BREAKER_THRESHOLD" envDefault:"5"`        // Consecutive transient failures that open a provider's circuit
+	BreakerTimeout   int      `env:"BREAKER_TIMEOUT"   envDefault:"30"`       // Seconds an open circuit skips the provider
+	PermanentErrors  []string `env:"PERMANENT_ERRORS"`                        // Errors that are never retried, as provider:code-prefix
+	TransientErrors  []string `env:"TRANSIENT_ERRORS"  envDefault:"smtp:552"` // Errors that are always retried, as provider:code-prefix
+	SMTP             SMTP     `envPrefix:"SMTP_"`
+	SendGrid         SendGrid `envPrefix:"SENDGRID_"`
+	Mailgun          Mailgun  `envPrefix:"MAILGUN_"`
+	SES              SES      `envPrefix:"SES_"`
+}
+
+// Suppression modes define how new emails to suppressed recipients are handled.
+const (
+	SuppressionReject = "reject" // Reject the request
+	SuppressionMark   = "mark"   // Accept the email and mark it as suppressed without sending
+)
+
+type Suppression struct {
+	Mode string `env:"MODE" envDefault:"reject"` // How to handle emails to suppressed recipients: reject or mark
+}
+
+// Check modes define how recipient addresses failing a validation check are handled.
+const (
+	CheckReject = "reject" // Reject the request
+	CheckWarn   = "warn"   // Accept the email and log a warning
+	CheckOff    = "off"    // Skip the check
+)
+
+type Validation struct {
+	IDN               string   `env:"IDN"                envDefault:"reject"` // Mode of the IDN to punycode conversion of domains
+	Disposable        string   `env:"DISPOSABLE"         envDefault:"reject"` // Mode of the disposable domain blocklist
+	DisposableDomains []string `env:"DISPOSABLE_DOMAINS"`                     // Domains blocked in addition to the built-in list
+	MX                string   `env:"MX"                 envDefault:"off"`    // Mode of the MX record lookup of domains
+	MXTimeout         int      `env:"MX_TIMEOUT"         envDefault:"3"`      // Timeout in seconds of MX lookups
+}
+
+type DKIM struct {
+	Keys []string `env:"KEYS"` // Signing keys per From domain as domain:selector:path-to-pem-key
+}
+
+type Links struct {
+	BaseURL string `env:"BASE_URL" envDefault:"http://localhost:3000"` // Public URL of the API used in tracking and unsubscribe links
+	Secret  string `env:"SECRET"`                                      // HMAC key of link tokens, links are disabled if empty
+}
+
+type Tracing struct {
+	Endpoint    string  `env:"ENDPOINT"`                            // OTLP/HTTP collector host:port, tracing is disabled if empty
+	Insecure    bool    `env:"INSECURE"`                            // Export over plain HTTP
+	ServiceName string  `env:"SERVICE_NAME" envDefault:"mailqusrv"` // service.name resource attribute
+	SampleRatio float64 `env:"SAMPLE_RATIO" envDefault:"1"`         // Fraction of new traces that are sampled
+}
+
+// Log formats.
+const (
+	LogJSON = "json"
+	LogText = "text"
+)
+
+type Log struct {
+	Format       string     `env:"FORMAT"        envDefault:"json"`                                // Output format: json or text
+	Level        slog.Level `env:"LEVEL"         envDefault:"info"`                                // Minimum level: debug, info, warn or error
+	RedactParams []string   `env:"REDACT_PARAMS" envDefault:"token,key,secret,password,signature"` // Query parameters whose values are not logged
 }
 
 type Config struct {
-	DB     DB     `envPrefix:"DB_"`
-	Server Server `envPrefix:"SERVER_"`
-	Worker Worker `envPrefix:"WORKER_"`
+	DB          DB          `envPrefix:"DB_"`
+	Server      Server      `envPrefix:"SERVER_"`
+	GRPC        GRPC        `envPrefix:"GRPC_"`
+	SSE         SSE         `envPrefix:"SSE_"`
+	Admin       Admin       `envPrefix:"ADMIN_"`
+	Worker      Worker      `envPrefix:"WORKER_"`
+	Suppression Suppression `envPrefix:"SUPPRESSION_"`
+	Validation  Validation  `envPrefix:"VALIDATION_"`
+	Mail        Mail        `envPrefix:"MAIL_"`
+	Sender      Sender      `envPrefix:"SENDER_"`
+	DKIM        DKIM        `envPrefix:"DKIM_"`
+	Links       Links       `envPrefix:"LINKS_"`
+	Tracing     Tracing     `envPrefix:"TRACING_"`
+	Log         Log         `envPrefix:"LOG_"`
 }
 
 // NewConfig creates and returns a new Config instance by loading environment variables
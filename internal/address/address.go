@@ -0,0 +1,168 @@
+// Package address normalizes recipient addresses and checks whether they can receive email:
+// internationalized domains are converted to punycode, disposable domains are blocked
+// and domains may be required to have mail exchangers.
+package address
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"strings"
+	"time"
+
+	"golang.org/x/net/idna"
+
+	"github.com/grishkovelli/betera-mailqusrv/config"
+)
+
+// Validation checks, the syntax check cannot be turned off.
+const (
+	CheckSyntax     = "syntax"
+	CheckIDN        = "idn"
+	CheckDisposable = "disposable"
+	CheckMX         = "mx"
+)
+
+// ErrUndeliverable is returned for addresses rejected by a validation check.
+var ErrUndeliverable = errors.New("undeliverable address")
+
+// Error describes why an address was rejected.
+type Error struct {
+	Address string // Rejected address
+	Check   string // Check that failed
+	Reason  string // Human readable reason
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s %s: %s", ErrUndeliverable, e.Address, e.Reason)
+}
+
+func (e *Error) Unwrap() error {
+	return ErrUndeliverable
+}
+
+// Resolver looks up DNS records of recipient domains, *net.Resolver satisfies it.
+type Resolver interface {
+	LookupMX(ctx context.Context, name string) ([]*net.MX, error)
+	LookupHost(ctx context.Context, host string) ([]string, error)
+}
+
+// Validator runs the configured checks. Checks in warn mode only log their failures
+// and checks in off mode are skipped, any other mode rejects the address.
+type Validator struct {
+	cfg        config.Validation
+	disposable map[string]bool
+	resolver   Resolver
+	logger     *slog.Logger
+}
+
+// New creates a validator that blocks the built-in and the configured disposable domains
+// and looks up mail exchangers through the resolver.
+func New(cfg config.Validation, resolver Resolver, logger *slog.Logger) *Validator {
+	v := &Validator{cfg: cfg, disposable: map[string]bool{}, resolver: resolver, logger: logger}
+	for _, d := range append(DisposableDomains(), cfg.DisposableDomains...) {
+		v.disposable[strings.ToLower(strings.TrimSpace(d))] = true
+	}
+
+	return v
+}
+
+// Validate returns the normalized address, with the domain lowercased and converted to punycode,
+// or an *Error if a check in reject mode fails.
+func (v *Validator) Validate(ctx context.Context, addr string) (string, error) {
+	at := strings.LastIndexByte(addr, '@')
+	if at < 0 {
+		return "", &Error{Address: addr, Check: CheckSyntax, Reason: "missing domain"}
+	}
+	local, domain := addr[:at], strings.ToLower(strings.TrimSuffix(addr[at+1:], "."))
+
+	if v.cfg.IDN != config.CheckOff {
+		ascii, err := idna.Lookup.ToASCII(domain)
+		if err != nil {
+			reason := fmt.Sprintf("invalid domain %s: %v", domain, err)
+			if err = v.fail(ctx, addr, CheckIDN, v.cfg.IDN, reason); err != nil {
+				return "", err
+			}
+		} else {
+			domain = ascii
+		}
+	}
+	addr = local + "@" + domain
+
+	if v.cfg.Disposable != config.CheckOff && v.isDisposable(domain) {
+		reason := fmt.Sprintf("%s is a disposable email domain", domain)
+		if err := v.fail(ctx, addr, CheckDisposable, v.cfg.Disposable, reason); err != nil {
+			return "", err
+		}
+	}
+
+	if v.cfg.MX != config.CheckOff {
+		if reason := v.checkMX(ctx, domain); reason != "" {
+			if err := v.fail(ctx, addr, CheckMX, v.cfg.MX, reason); err != nil {
+				return "", err
+			}
+		}
+	}
+
+	return addr, nil
+}
+
+// fail handles a failed check according to its mode.
+func (v *Validator) fail(ctx context.Context, addr, check, mode, reason string) error {
+	if mode == config.CheckWarn {
+		v.logger.WarnContext(ctx, "address check failed", "addr", addr, "check", check, "reason", reason)
+		return nil
+	}
+
+	return &Error{Address: addr, Check: check, Reason: reason}
+}
+
+// isDisposable reports whether the domain or one of its parent domains is blocked.
+func (v *Validator) isDisposable(domain string) bool {
+	for d := domain; d != ""; {
+		if v.disposable[d] {
+			return true
+		}
+		_, d, _ = strings.Cut(d, ".")
+	}
+
+	return false
+}
+
+// checkMX returns why the domain cannot receive email, or an empty string if it can.
+// Domains without MX records fall back to their address records as described in RFC 5321.
+// Lookup failures other than missing records do not reject addresses, so a DNS outage
+// does not block sending.
+func (v *Validator) checkMX(ctx context.Context, domain string) string {
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(v.cfg.MXTimeout)*time.Second)
+	defer cancel()
+
+	mxs, err := v.resolver.LookupMX(ctx, domain)
+	switch {
+	case err == nil && len(mxs) == 1 && mxs[0].Host == ".":
+		// null MX, RFC 7505
+		return fmt.Sprintf("%s does not accept email", domain)
+	case err == nil && len(mxs) > 0:
+		return ""
+	case err != nil && !isNotFound(err):
+		v.logger.WarnContext(ctx, "mx lookup failed", "domain", domain, "error", err)
+		return ""
+	}
+
+	if _, err = v.resolver.LookupHost(ctx, domain); err != nil {
+		if isNotFound(err) {
+			return fmt.Sprintf("%s has no mail servers", domain)
+		}
+		v.logger.WarnContext(ctx, "host lookup failed", "domain", domain, "error", err)
+	}
+
+	return ""
+}
+
+// isNotFound reports whether a lookup failed because the records do not exist.
+func isNotFound(err error) bool {
+	var dnsErr *net.DNSError
+	return errors.As(err, &dnsErr) && dnsErr.IsNotFound
+}
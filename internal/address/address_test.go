@@ -0,0 +1,123 @@
+package address
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grishkovelli/betera-mailqusrv/config"
+)
+
+// fakeResolver serves MX and host records from maps, unknown names are not found.
+type fakeResolver struct {
+	mx    map[string][]*net.MX
+	hosts map[string][]string
+	err   error
+}
+
+func (r *fakeResolver) LookupMX(_ context.Context, name string) ([]*net.MX, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	if mx, ok := r.mx[name]; ok {
+		return mx, nil
+	}
+
+	return nil, &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+}
+
+func (r *fakeResolver) LookupHost(_ context.Context, host string) ([]string, error) {
+	if hosts, ok := r.hosts[host]; ok {
+		return hosts, nil
+	}
+
+	return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+}
+
+func newValidator(cfg config.Validation, r Resolver) *Validator {
+	return New(cfg, r, slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
+
+func TestValidator_Validate(t *testing.T) {
+	resolver := &fakeResolver{
+		mx: map[string][]*net.MX{
+			"example.com":      {{Host: "mx.example.com.", Pref: 10}},
+			"xn--bcher-kva.de": {{Host: "mx.xn--bcher-kva.de.", Pref: 10}},
+			"nomail.example":   {{Host: ".", Pref: 0}},
+		},
+		hosts: map[string][]string{"a-only.example": {"192.0.2.1"}},
+	}
+	reject := config.Validation{
+		IDN:               config.CheckReject,
+		Disposable:        config.CheckReject,
+		DisposableDomains: []string{"Throwaway.Example"},
+		MX:                config.CheckReject,
+	}
+
+	tests := []struct {
+		name      string
+		cfg       config.Validation
+		addr      string
+		want      string
+		wantCheck string
+	}{
+		{name: "valid", cfg: reject, addr: "John.Doe@Example.COM", want: "John.Doe@example.com"},
+		{name: "idn", cfg: reject, addr: "user@Bücher.de", want: "user@xn--bcher-kva.de"},
+		{name: "invalid idn", cfg: reject, addr: "user@exa_mple.com", wantCheck: CheckIDN},
+		{name: "missing domain", cfg: reject, addr: "user", wantCheck: CheckSyntax},
+		{name: "disposable", cfg: reject, addr: "user@mailinator.com", wantCheck: CheckDisposable},
+		{name: "disposable subdomain", cfg: reject, addr: "user@eu.mailinator.com", wantCheck: CheckDisposable},
+		{name: "configured disposable", cfg: reject, addr: "user@throwaway.example", wantCheck: CheckDisposable},
+		{name: "no mail servers", cfg: reject, addr: "user@missing.example", wantCheck: CheckMX},
+		{name: "null mx", cfg: reject, addr: "user@nomail.example", wantCheck: CheckMX},
+		{name: "address record fallback", cfg: reject, addr: "user@a-only.example", want: "user@a-only.example"},
+		{
+			name: "warn mode",
+			cfg:  config.Validation{IDN: config.CheckWarn, Disposable: config.CheckWarn, MX: config.CheckWarn},
+			addr: "user@Mailinator.com",
+			want: "user@mailinator.com",
+		},
+		{
+			name: "off mode",
+			cfg:  config.Validation{IDN: config.CheckOff, Disposable: config.CheckOff, MX: config.CheckOff},
+			addr: "user@Bücher.DE",
+			want: "user@bücher.de",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := newValidator(tt.cfg, resolver).Validate(t.Context(), tt.addr)
+			if tt.wantCheck != "" {
+				require.ErrorIs(t, err, ErrUndeliverable)
+				var ae *Error
+				require.ErrorAs(t, err, &ae)
+				assert.Equal(t, tt.wantCheck, ae.Check)
+				assert.NotEmpty(t, ae.Reason)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestValidator_LookupFailure(t *testing.T) {
+	resolver := &fakeResolver{err: &net.DNSError{Err: "server misbehaving", Name: "example.com", IsTemporary: true}}
+	v := newValidator(config.Validation{MX: config.CheckReject}, resolver)
+
+	got, err := v.Validate(t.Context(), "user@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, "user@example.com", got)
+
+	resolver.err = errors.New("connection refused")
+	_, err = v.Validate(t.Context(), "user@example.com")
+	require.NoError(t, err)
+}
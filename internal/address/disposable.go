@@ -0,0 +1,32 @@
+package address
+
+// DisposableDomains returns the built-in blocklist of disposable email domains.
+// Subdomains of blocked domains are blocked as well.
+func DisposableDomains() []string {
+	return []string{
+		"10minutemail.com",
+		"33mail.com",
+		"burnermail.io",
+		"discard.email",
+		"dispostable.com",
+		"emailondeck.com",
+		"fakeinbox.com",
+		"getnada.com",
+		"guerrillamail.com",
+		"guerrillamail.net",
+		"guerrillamailblock.com",
+		"maildrop.cc",
+		"mailinator.com",
+		"mailnesia.com",
+		"mintemail.com",
+		"mohmal.com",
+		"sharklasers.com",
+		"spamgourmet.com",
+		"temp-mail.org",
+		"tempail.com",
+		"tempmail.com",
+		"throwawaymail.com",
+		"trashmail.com",
+		"yopmail.com",
+	}
+}
@@ -0,0 +1,28 @@
+// Package admin serves the embedded web dashboard. The dashboard is a static page working
+// on the JSON API routes that are mounted under APIPrefix.
+package admin
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+)
+
+// APIPrefix is the path under which the dashboard expects the API routes.
+const APIPrefix = "/admin/api"
+
+//go:embed static
+var static embed.FS
+
+// Handler serves the dashboard files at the root path, mount it with http.StripPrefix.
+func Handler() http.Handler {
+	files, _ := fs.Sub(static, "static") // never fails, the directory is embedded
+	fileServer := http.FileServerFS(files)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Security-Policy", "default-src 'self'; frame-ancestors 'none'")
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		w.Header().Set("Cache-Control", "no-cache")
+		fileServer.ServeHTTP(w, r)
+	})
+}
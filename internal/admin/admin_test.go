@@ -0,0 +1,41 @@
+package admin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHandler(t *testing.T) {
+	tests := []struct {
+		path        string
+		wantType    string
+		wantContent string
+	}{
+		{path: "/", wantType: "text/html; charset=utf-8", wantContent: `<script src="app.js" defer></script>`},
+		{path: "/app.js", wantType: "text/javascript; charset=utf-8", wantContent: `const api = "api";`},
+		{path: "/style.css", wantType: "text/css; charset=utf-8", wantContent: ".cards"},
+	}
+
+	h := http.StripPrefix("/admin", Handler())
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin"+tt.path, nil))
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+			}
+			if got := w.Header().Get("Content-Type"); got != tt.wantType {
+				t.Errorf("content type = %q, want %q", got, tt.wantType)
+			}
+			if !strings.Contains(w.Header().Get("Content-Security-Policy"), "default-src 'self'") {
+				t.Errorf("content security policy = %q", w.Header().Get("Content-Security-Policy"))
+			}
+			if !strings.Contains(w.Body.String(), tt.wantContent) {
+				t.Errorf("body has no %q", tt.wantContent)
+			}
+		})
+	}
+}
@@ -0,0 +1,165 @@
+"use strict";
+
+// API routes are served next to the dashboard under /admin/api.
+const api = "api";
+
+const statuses = ["pending", "processing", "sent", "failed", "dead", "rejected", "cancelled", "suppressed", "bounced"];
+const failureStatuses = ["failed", "dead", "rejected"];
+
+// Statuses the email state machine allows to move from with each action.
+const cancellable = ["pending", "failed"];
+const retryable = ["failed", "dead", "suppressed"];
+
+const detailFields = [
+  ["to_address", "Recipient"],
+  ["subject", "Subject"],
+  ["status", "Status"],
+  ["attempts", "Attempts"],
+  ["provider", "Provider"],
+  ["sent_provider", "Sent through"],
+  ["from_address", "From"],
+  ["from_name", "From name"],
+  ["reply_to", "Reply-To"],
+  ["category", "Category"],
+  ["message_id", "Message-ID"],
+  ["request_id", "Request ID"],
+  ["track", "Tracked"],
+  ["body", "Body"],
+];
+
+let selected = null;
+let search = { to: "", cursor: 0 };
+
+async function request(method, path) {
+  const resp = await fetch(`${api}${path}`, { method, headers: { Accept: "application/json" } });
+  const body = await resp.json().catch(() => null);
+  if (!resp.ok) {
+    throw new Error(body?.message ?? resp.statusText);
+  }
+  return body;
+}
+
+function showError(err) {
+  const el = document.getElementById("error");
+  el.textContent = err ? err.message : "";
+  el.hidden = !err;
+}
+
+function cell(text, className) {
+  const td = document.createElement("td");
+  td.textContent = text;
+  if (className) {
+    td.className = className;
+  }
+  return td;
+}
+
+function renderRows(tbody, emails, append) {
+  if (!append) {
+    tbody.replaceChildren();
+  }
+  for (const email of emails) {
+    const tr = document.createElement("tr");
+    tr.append(
+      cell(email.id),
+      cell(email.to_address),
+      cell(email.subject),
+      cell(email.status, `status-${email.status}`),
+      cell(email.attempts),
+    );
+    tr.addEventListener("click", () => showDetail(email.id).catch(showError));
+    tbody.append(tr);
+  }
+}
+
+async function loadDepth() {
+  const stats = await request("GET", "/stats");
+  const cards = statuses.map((status) => {
+    const card = document.createElement("div");
+    card.className = `card status-${status}`;
+    const count = document.createElement("strong");
+    count.textContent = stats.statuses[status] ?? 0;
+    card.append(count, status);
+    return card;
+  });
+  document.getElementById("depth").replaceChildren(...cards);
+}
+
+async function loadFailures() {
+  const pages = await Promise.all(
+    failureStatuses.map((status) => request("GET", `/emails?status=${status}`)),
+  );
+  const emails = pages.flat().sort((a, b) => b.id - a.id);
+  renderRows(document.getElementById("failures"), emails, false);
+}
+
+async function loadSearch(append) {
+  if (!search.to) {
+    return;
+  }
+  const query = new URLSearchParams({ to_address: search.to });
+  if (search.cursor > 0) {
+    query.set("cursor", search.cursor);
+  }
+  const emails = await request("GET", `/emails?${query}`);
+  renderRows(document.getElementById("results"), emails, append);
+  if (emails.length > 0) {
+    search.cursor = emails[emails.length - 1].id;
+  }
+  document.getElementById("more").hidden = emails.length === 0;
+}
+
+async function showDetail(id) {
+  const email = await request("GET", `/emails/${id}`);
+  selected = email;
+
+  document.getElementById("detail-id").textContent = `#${email.id}`;
+  const fields = detailFields.flatMap(([key, label]) => {
+    const dt = document.createElement("dt");
+    dt.textContent = label;
+    const dd = document.createElement("dd");
+    dd.textContent = email[key] === "" ? "—" : String(email[key]);
+    if (key === "status") {
+      dd.className = `status-${email.status}`;
+    }
+    return [dt, dd];
+  });
+  document.getElementById("detail-fields").replaceChildren(...fields);
+  document.getElementById("cancel").disabled = !cancellable.includes(email.status);
+  document.getElementById("retry").disabled = !retryable.includes(email.status);
+
+  const detail = document.getElementById("detail");
+  detail.hidden = false;
+  detail.scrollIntoView({ behavior: "smooth" });
+}
+
+async function changeStatus(action) {
+  if (!selected) {
+    return;
+  }
+  await request("POST", `/emails/${selected.id}/${action}`);
+  await Promise.all([showDetail(selected.id), refresh()]);
+}
+
+async function refresh() {
+  await Promise.all([loadDepth(), loadFailures()]);
+}
+
+function run(fn) {
+  showError(null);
+  fn().catch(showError);
+}
+
+document.addEventListener("DOMContentLoaded", () => {
+  document.getElementById("refresh").addEventListener("click", () => run(refresh));
+  document.getElementById("search").addEventListener("submit", (event) => {
+    event.preventDefault();
+    search = { to: new FormData(event.target).get("to_address"), cursor: 0 };
+    run(() => loadSearch(false));
+  });
+  document.getElementById("more").addEventListener("click", () => run(() => loadSearch(true)));
+  document.getElementById("retry").addEventListener("click", () => run(() => changeStatus("retry")));
+  document.getElementById("cancel").addEventListener("click", () => run(() => changeStatus("cancel")));
+
+  run(refresh);
+});
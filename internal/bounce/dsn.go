@@ -0,0 +1,157 @@
+package bounce
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"net/textproto"
+	"strings"
+)
+
+// ErrNotDSN is returned when a message is not an RFC 3464 delivery status notification.
+var ErrNotDSN = errors.New("message is not a delivery status notification")
+
+// DSN is a parsed RFC 3464 delivery status notification.
+type DSN struct {
+	ReportingMTA      string      // MTA that generated the notification
+	OriginalMessageID string      // Message-ID of the message the notification is about, if included
+	ReturnPaths       []string    // Addresses the notification was delivered to, used for VERP matching
+	Recipients        []Recipient // Per-recipient delivery status
+}
+
+// Recipient is the delivery status of a single recipient of the original message.
+type Recipient struct {
+	Address    string // Final recipient address
+	Action     string // failed, delayed, delivered, relayed or expanded
+	Status     string // Enhanced status code, e.g. 5.1.1
+	Diagnostic string // Diagnostic code reported by the remote MTA
+}
+
+// Failed reports whether delivery to the recipient failed.
+func (r Recipient) Failed() bool {
+	return r.Action == "failed"
+}
+
+// Permanent reports whether the failure is permanent (a hard bounce).
+func (r Recipient) Permanent() bool {
+	return r.Failed() && strings.HasPrefix(r.Status, "5.")
+}
+
+// Parse reads a raw RFC 5322 message and parses it as a delivery status notification.
+func Parse(r io.Reader) (*DSN, error) {
+	msg, err := mail.ReadMessage(r)
+	if err != nil {
+		return nil, fmt.Errorf("read message: %w", err)
+	}
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/report" ||
+		!strings.EqualFold(params["report-type"], "delivery-status") || params["boundary"] == "" {
+		return nil, ErrNotDSN
+	}
+
+	dsn := &DSN{ReturnPaths: returnPaths(msg.Header)}
+
+	mr := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		part, err := mr.NextPart()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read part: %w", err)
+		}
+
+		partType, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		switch partType {
+		case "message/delivery-status", "message/global-delivery-status":
+			if err = dsn.parseStatus(part); err != nil {
+				return nil, err
+			}
+		case "message/rfc822", "text/rfc822-headers", "message/global-headers":
+			dsn.parseOriginal(part)
+		}
+	}
+
+	if len(dsn.Recipients) == 0 {
+		return nil, fmt.Errorf("%w: no recipient status fields", ErrNotDSN)
+	}
+
+	return dsn, nil
+}
+
+// parseStatus parses the per-message and per-recipient fields of the delivery-status part.
+func (d *DSN) parseStatus(r io.Reader) error {
+	tp := textproto.NewReader(bufio.NewReader(r))
+
+	perMessage, err := tp.ReadMIMEHeader()
+	if err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("read delivery status: %w", err)
+	}
+	d.ReportingMTA = fieldValue(perMessage.Get("Reporting-Mta"))
+
+	for !errors.Is(err, io.EOF) {
+		var fields textproto.MIMEHeader
+		fields, err = tp.ReadMIMEHeader()
+		if err != nil && !errors.Is(err, io.EOF) {
+			return fmt.Errorf("read delivery status: %w", err)
+		}
+		if len(fields) == 0 {
+			continue
+		}
+
+		addr := fieldValue(fields.Get("Final-Recipient"))
+		if addr == "" {
+			addr = fieldValue(fields.Get("Original-Recipient"))
+		}
+
+		d.Recipients = append(d.Recipients, Recipient{
+			Address:    strings.ToLower(strings.Trim(addr, "<>")),
+			Action:     strings.ToLower(strings.TrimSpace(fields.Get("Action"))),
+			Status:     strings.TrimSpace(fields.Get("Status")),
+			Diagnostic: fieldValue(fields.Get("Diagnostic-Code")),
+		})
+	}
+
+	return nil
+}
+
+// parseOriginal extracts identifying headers of the original message.
+func (d *DSN) parseOriginal(r io.Reader) {
+	msg, err := mail.ReadMessage(r)
+	if err != nil {
+		return
+	}
+
+	d.OriginalMessageID = strings.TrimSpace(msg.Header.Get("Message-Id"))
+	d.ReturnPaths = append(d.ReturnPaths, returnPaths(msg.Header)...)
+}
+
+// fieldValue strips the type prefix from typed DSN fields such as "rfc822; user@example.com".
+func fieldValue(v string) string {
+	if _, after, ok := strings.Cut(v, ";"); ok {
+		v = after
+	}
+
+	return strings.TrimSpace(v)
+}
+
+// returnPaths collects the addresses from headers that may carry a VERP address.
+func returnPaths(h mail.Header) []string {
+	var res []string
+	for _, key := range []string{"Return-Path", "X-Original-To", "Delivered-To", "To"} {
+		addrs, err := h.AddressList(key)
+		if err != nil {
+			continue
+		}
+		for _, a := range addrs {
+			res = append(res, strings.ToLower(a.Address))
+		}
+	}
+
+	return res
+}
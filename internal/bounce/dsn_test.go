@@ -0,0 +1,176 @@
+package bounce
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+const hardBounce = "Return-Path: <>\r\n" +
+	"To: bounces+42@mailqu.local\r\n" +
+	"From: MAILER-DAEMON@mx.example.com\r\n" +
+	"Subject: Undelivered Mail Returned to Sender\r\n" +
+	"MIME-Version: 1.0\r\n" +
+	"Content-Type: multipart/report; report-type=delivery-status;\r\n" +
+	"\tboundary=\"BOUNDARY\"\r\n" +
+	"\r\n" +
+	"--BOUNDARY\r\n" +
+	"Content-Type: text/plain\r\n" +
+	"\r\n" +
+	"The mail system could not deliver your message.\r\n" +
+	"--BOUNDARY\r\n" +
+	"Content-Type: message/delivery-status\r\n" +
+	"\r\n" +
+	"Reporting-MTA: dns; mx.example.com\r\n" +
+	"Arrival-Date: Mon, 1 Jan 2024 10:00:00 +0000\r\n" +
+	"\r\n" +
+	"Final-Recipient: rfc822; User@Example.com\r\n" +
+	"Original-Recipient: rfc822; user@example.com\r\n" +
+	"Action: failed\r\n" +
+	"Status: 5.1.1\r\n" +
+	"Diagnostic-Code: smtp; 550 5.1.1 <user@example.com>: Recipient address rejected\r\n" +
+	"\r\n" +
+	"--BOUNDARY\r\n" +
+	"Content-Type: text/rfc822-headers\r\n" +
+	"\r\n" +
+	"Message-ID: <abc123@mailqu.local>\r\n" +
+	"To: user@example.com\r\n" +
+	"Subject: Hello\r\n" +
+	"--BOUNDARY--\r\n"
+
+const softBounce = "To: noreply@mailqu.local\r\n" +
+	"Content-Type: multipart/report; report-type=delivery-status; boundary=b\r\n" +
+	"\r\n" +
+	"--b\r\n" +
+	"Content-Type: message/delivery-status\r\n" +
+	"\r\n" +
+	"Reporting-MTA: dns; mx.example.org\r\n" +
+	"\r\n" +
+	"Final-Recipient: rfc822; full@example.org\r\n" +
+	"Action: delayed\r\n" +
+	"Status: 4.2.2\r\n" +
+	"\r\n" +
+	"Final-Recipient: rfc822; other@example.org\r\n" +
+	"Action: failed\r\n" +
+	"Status: 4.4.7\r\n" +
+	"--b--\r\n"
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name          string
+		raw           string
+		wantErr       error
+		wantMessageID string
+		wantMTA       string
+		wantRecipient []Recipient
+		wantPaths     []string
+	}{
+		{
+			name:          "hard bounce with original headers",
+			raw:           hardBounce,
+			wantMessageID: "<abc123@mailqu.local>",
+			wantMTA:       "mx.example.com",
+			wantRecipient: []Recipient{{
+				Address:    "user@example.com",
+				Action:     "failed",
+				Status:     "5.1.1",
+				Diagnostic: "550 5.1.1 <user@example.com>: Recipient address rejected",
+			}},
+			wantPaths: []string{"bounces+42@mailqu.local", "user@example.com"},
+		},
+		{
+			name:    "multiple recipients",
+			raw:     softBounce,
+			wantMTA: "mx.example.org",
+			wantRecipient: []Recipient{
+				{Address: "full@example.org", Action: "delayed", Status: "4.2.2"},
+				{Address: "other@example.org", Action: "failed", Status: "4.4.7"},
+			},
+			wantPaths: []string{"noreply@mailqu.local"},
+		},
+		{
+			name:    "plain message",
+			raw:     "To: user@example.com\r\nContent-Type: text/plain\r\n\r\nhello\r\n",
+			wantErr: ErrNotDSN,
+		},
+		{
+			name: "report without recipients",
+			raw: "Content-Type: multipart/report; report-type=delivery-status; boundary=b\r\n\r\n" +
+				"--b\r\nContent-Type: text/plain\r\n\r\nhello\r\n--b--\r\n",
+			wantErr: ErrNotDSN,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dsn, err := Parse(strings.NewReader(tt.raw))
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Parse() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+
+			if dsn.OriginalMessageID != tt.wantMessageID {
+				t.Errorf("OriginalMessageID = %q, want %q", dsn.OriginalMessageID, tt.wantMessageID)
+			}
+			if dsn.ReportingMTA != tt.wantMTA {
+				t.Errorf("ReportingMTA = %q, want %q", dsn.ReportingMTA, tt.wantMTA)
+			}
+			if len(dsn.Recipients) != len(tt.wantRecipient) {
+				t.Fatalf("Recipients = %+v, want %+v", dsn.Recipients, tt.wantRecipient)
+			}
+			for i, r := range tt.wantRecipient {
+				if dsn.Recipients[i] != r {
+					t.Errorf("Recipients[%d] = %+v, want %+v", i, dsn.Recipients[i], r)
+				}
+			}
+			if strings.Join(dsn.ReturnPaths, ",") != strings.Join(tt.wantPaths, ",") {
+				t.Errorf("ReturnPaths = %v, want %v", dsn.ReturnPaths, tt.wantPaths)
+			}
+		})
+	}
+}
+
+func TestRecipient_Permanent(t *testing.T) {
+	tests := []struct {
+		recipient Recipient
+		want      bool
+	}{
+		{Recipient{Action: "failed", Status: "5.1.1"}, true},
+		{Recipient{Action: "failed", Status: "4.4.7"}, false},
+		{Recipient{Action: "delayed", Status: "5.0.0"}, false},
+		{Recipient{Action: "delivered", Status: "2.0.0"}, false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.recipient.Permanent(); got != tt.want {
+			t.Errorf("Permanent(%+v) = %v, want %v", tt.recipient, got, tt.want)
+		}
+	}
+}
+
+func TestParseVERP(t *testing.T) {
+	tests := []struct {
+		addr   string
+		wantID int
+		wantOK bool
+	}{
+		{VERPAddress("bounces", "mailqu.local", 42), 42, true},
+		{"Bounces+7@mailqu.local", 7, true},
+		{"bounces@mailqu.local", 0, false},
+		{"bounces+abc@mailqu.local", 0, false},
+		{"other+7@mailqu.local", 0, false},
+		{"bounces+7", 0, false},
+	}
+
+	for _, tt := range tests {
+		id, ok := ParseVERP("bounces", tt.addr)
+		if id != tt.wantID || ok != tt.wantOK {
+			t.Errorf("ParseVERP(%q) = %v, %v, want %v, %v", tt.addr, id, ok, tt.wantID, tt.wantOK)
+		}
+	}
+}
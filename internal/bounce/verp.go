@@ -0,0 +1,33 @@
+package bounce
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// VERPAddress returns the variable envelope return path for an email,
+// e.g. bounces+42@example.com for prefix "bounces", email 42 and domain example.com.
+func VERPAddress(prefix, domain string, emailID int) string {
+	return fmt.Sprintf("%s+%d@%s", prefix, emailID, domain)
+}
+
+// ParseVERP extracts the email ID from a variable envelope return path created by VERPAddress.
+func ParseVERP(prefix, addr string) (int, bool) {
+	local, _, ok := strings.Cut(addr, "@")
+	if !ok {
+		return 0, false
+	}
+
+	tag, ok := strings.CutPrefix(strings.ToLower(local), strings.ToLower(prefix)+"+")
+	if !ok {
+		return 0, false
+	}
+
+	id, err := strconv.Atoi(tag)
+	if err != nil || id <= 0 {
+		return 0, false
+	}
+
+	return id, true
+}
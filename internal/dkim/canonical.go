@@ -0,0 +1,109 @@
+package dkim
+
+import (
+	"bytes"
+	"strings"
+)
+
+// field is a single header field of a raw message, value keeps its original folding.
+type field struct {
+	name  string
+	value string
+}
+
+// is reports whether the field has the given name, ignoring case.
+func (f field) is(name string) bool {
+	return strings.EqualFold(strings.TrimSpace(f.name), name)
+}
+
+// splitMessage splits a raw CRLF message into its header fields and body.
+func splitMessage(msg []byte) ([]field, []byte) {
+	head, body, ok := bytes.Cut(msg, []byte("\r\n\r\n"))
+	if !ok {
+		head, body = bytes.TrimSuffix(msg, []byte("\r\n")), nil
+	}
+
+	var fields []field
+	for _, line := range strings.Split(string(head), "\r\n") {
+		if line == "" {
+			continue
+		}
+		// continuation lines of folded headers start with whitespace
+		if (line[0] == ' ' || line[0] == '\t') && len(fields) > 0 {
+			fields[len(fields)-1].value += "\r\n" + line
+			continue
+		}
+
+		name, value, _ := strings.Cut(line, ":")
+		fields = append(fields, field{name: name, value: value})
+	}
+
+	return fields, body
+}
+
+// relaxedHeader canonicalizes a header field with the relaxed algorithm of RFC 6376, section 3.4.2:
+// the name is lowercased, the value is unfolded and runs of whitespace are reduced to a single space.
+func relaxedHeader(name, value string) string {
+	value = strings.NewReplacer("\r\n", "", "\n", "").Replace(value)
+
+	return strings.ToLower(strings.TrimSpace(name)) + ":" + compressSpace(value, true) + "\r\n"
+}
+
+// relaxedBody canonicalizes a body with the relaxed algorithm of RFC 6376, section 3.4.4:
+// whitespace is reduced within lines and removed at their ends, trailing empty lines are dropped.
+func relaxedBody(body []byte) []byte {
+	lines := strings.Split(string(body), "\r\n")
+	for i, line := range lines {
+		lines[i] = compressSpace(line, false)
+	}
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+
+	return []byte(strings.Join(lines, "\r\n") + "\r\n")
+}
+
+// compressSpace reduces runs of spaces and tabs to a single space and removes trailing ones.
+// Leading whitespace is removed as well when trimLeft is set.
+func compressSpace(s string, trimLeft bool) string {
+	var b strings.Builder
+	space := false
+	for _, c := range s {
+		if c == ' ' || c == '\t' {
+			space = true
+			continue
+		}
+		if space && (b.Len() > 0 || !trimLeft) {
+			b.WriteByte(' ')
+		}
+		space = false
+		b.WriteRune(c)
+	}
+
+	return b.String()
+}
+
+// signedData returns the data covered by a signature: the canonical form of the named headers,
+// each instance picked from the bottom of the message up, followed by the signature header
+// without the trailing CRLF. Names of missing instances are skipped.
+func signedData(fields []field, names []string, signature string) []byte {
+	used := make([]bool, len(fields))
+
+	var buf bytes.Buffer
+	for _, name := range names {
+		for i := len(fields) - 1; i >= 0; i-- {
+			if used[i] || !fields[i].is(name) {
+				continue
+			}
+			used[i] = true
+			buf.WriteString(relaxedHeader(fields[i].name, fields[i].value))
+			break
+		}
+	}
+
+	buf.WriteString(strings.TrimSuffix(relaxedHeader(HeaderName, signature), "\r\n"))
+	return buf.Bytes()
+}
@@ -0,0 +1,251 @@
+// Package dkim signs outgoing messages with DomainKeys Identified Mail signatures (RFC 6376)
+// using RSA-SHA256 or Ed25519-SHA256 (RFC 8463) keys and relaxed/relaxed canonicalization.
+package dkim
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/mail"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// HeaderName is the name of the signature header.
+const HeaderName = "DKIM-Signature"
+
+// keySpecParts is the number of parts in a domain:selector:path key spec.
+const keySpecParts = 3
+
+// Signature algorithms.
+const (
+	RSASHA256     = "rsa-sha256"
+	Ed25519SHA256 = "ed25519-sha256"
+)
+
+// DKIM errors.
+var (
+	ErrInvalidKey      = errors.New("invalid dkim key")
+	ErrUnsupportedKey  = errors.New("unsupported dkim key type")
+	ErrInvalidKeySpec  = errors.New("invalid dkim key spec")
+	ErrInvalidFromAddr = errors.New("invalid from address")
+)
+
+// DefaultHeaders are the headers covered by signatures. Headers missing from a message are skipped.
+func DefaultHeaders() []string {
+	return []string{
+		"From", "Reply-To", "To", "Subject", "Date", "Message-ID",
+		"MIME-Version", "Content-Type", "Content-Transfer-Encoding",
+		"List-Unsubscribe", "List-Unsubscribe-Post",
+	}
+}
+
+// Signer signs messages of a single domain with one key.
+type Signer struct {
+	domain    string
+	selector  string
+	key       crypto.Signer
+	algorithm string
+	headers   []string
+	now       func() time.Time
+}
+
+// NewSigner creates a signer for the domain. The algorithm is chosen by the key type,
+// which must be *rsa.PrivateKey or ed25519.PrivateKey.
+func NewSigner(domain, selector string, key crypto.Signer) (*Signer, error) {
+	s := &Signer{
+		domain:   strings.ToLower(domain),
+		selector: selector,
+		key:      key,
+		headers:  DefaultHeaders(),
+		now:      time.Now,
+	}
+
+	switch key.(type) {
+	case *rsa.PrivateKey:
+		s.algorithm = RSASHA256
+	case ed25519.PrivateKey:
+		s.algorithm = Ed25519SHA256
+	default:
+		return nil, fmt.Errorf("%w: %T", ErrUnsupportedKey, key)
+	}
+
+	return s, nil
+}
+
+// ParsePrivateKey parses a PEM encoded PKCS #1 RSA key or a PKCS #8 RSA or Ed25519 key.
+func ParsePrivateKey(data []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%w: no PEM block found", ErrInvalidKey)
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidKey, err)
+	}
+
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("%w: %T", ErrUnsupportedKey, key)
+	}
+
+	return signer, nil
+}
+
+// Sign returns the DKIM-Signature header value for a raw CRLF message.
+func (s *Signer) Sign(msg []byte) (string, error) {
+	fields, body := splitMessage(msg)
+
+	bodyHash := sha256.Sum256(relaxedBody(body))
+
+	names := make([]string, 0, len(s.headers))
+	for _, name := range s.headers {
+		if slices.ContainsFunc(fields, func(f field) bool { return f.is(name) }) {
+			names = append(names, strings.ToLower(name))
+		}
+	}
+
+	value := fmt.Sprintf("v=1; a=%s; c=relaxed/relaxed;\r\n d=%s; s=%s; t=%s;\r\n h=%s;\r\n bh=%s;\r\n b=",
+		s.algorithm,
+		s.domain,
+		s.selector,
+		strconv.FormatInt(s.now().Unix(), 10),
+		foldList(names),
+		base64.StdEncoding.EncodeToString(bodyHash[:]),
+	)
+
+	hash := sha256.Sum256(signedData(fields, names, value))
+
+	var (
+		sig []byte
+		err error
+	)
+	if s.algorithm == Ed25519SHA256 {
+		// RFC 8463 signs the SHA-256 hash with PureEdDSA
+		sig, err = s.key.Sign(rand.Reader, hash[:], crypto.Hash(0))
+	} else {
+		sig, err = s.key.Sign(rand.Reader, hash[:], crypto.SHA256)
+	}
+	if err != nil {
+		return "", fmt.Errorf("dkim sign: %w", err)
+	}
+
+	return value + fold(base64.StdEncoding.EncodeToString(sig)), nil
+}
+
+// Keyring selects the signer by the domain of the From address.
+type Keyring struct {
+	signers map[string]*Signer
+}
+
+// NewKeyring creates a keyring from signers of different domains.
+func NewKeyring(signers ...*Signer) *Keyring {
+	k := &Keyring{signers: make(map[string]*Signer, len(signers))}
+	for _, s := range signers {
+		k.signers[s.domain] = s
+	}
+
+	return k
+}
+
+// LoadKeyring creates a keyring from specs in the domain:selector:path format,
+// where path points to a PEM encoded private key.
+func LoadKeyring(specs []string) (*Keyring, error) {
+	signers := make([]*Signer, 0, len(specs))
+	for _, spec := range specs {
+		parts := strings.SplitN(strings.TrimSpace(spec), ":", keySpecParts)
+		if len(parts) != keySpecParts || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidKeySpec, spec)
+		}
+
+		data, err := os.ReadFile(parts[2])
+		if err != nil {
+			return nil, fmt.Errorf("read dkim key of %s: %w", parts[0], err)
+		}
+
+		key, err := ParsePrivateKey(data)
+		if err != nil {
+			return nil, fmt.Errorf("dkim key of %s: %w", parts[0], err)
+		}
+
+		signer, err := NewSigner(parts[0], parts[1], key)
+		if err != nil {
+			return nil, err
+		}
+		signers = append(signers, signer)
+	}
+
+	return NewKeyring(signers...), nil
+}
+
+// Sign returns the DKIM-Signature header value for a raw message sent from the given address.
+// Messages from domains without a key are left unsigned and an empty value is returned.
+func (k *Keyring) Sign(from string, msg []byte) (string, error) {
+	if k == nil || len(k.signers) == 0 {
+		return "", nil
+	}
+
+	addr, err := mail.ParseAddress(from)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrInvalidFromAddr, err)
+	}
+
+	domain := strings.ToLower(addr.Address[strings.LastIndexByte(addr.Address, '@')+1:])
+	signer, ok := k.signers[domain]
+	if !ok {
+		return "", nil
+	}
+
+	return signer.Sign(msg)
+}
+
+// foldWidth is the maximum length of folded signature lines.
+const foldWidth = 72
+
+// fold splits a long base64 value into indented lines to keep the header within line length limits.
+func fold(s string) string {
+	var b strings.Builder
+	for len(s) > foldWidth {
+		b.WriteString(s[:foldWidth])
+		b.WriteString("\r\n ")
+		s = s[foldWidth:]
+	}
+	b.WriteString(s)
+
+	return b.String()
+}
+
+// foldList joins header names with colons, folding the list before names that do not fit on the line.
+func foldList(names []string) string {
+	var b strings.Builder
+	line := 0
+	for i, name := range names {
+		if i > 0 {
+			b.WriteByte(':')
+			line++
+			if line+len(name) > foldWidth {
+				b.WriteString("\r\n ")
+				line = 0
+			}
+		}
+		b.WriteString(name)
+		line += len(name)
+	}
+
+	return b.String()
+}
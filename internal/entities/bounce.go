@@ -0,0 +1,8 @@
+package entities
+
+// BounceResult describes the outcome of processing a delivery status notification.
+type BounceResult struct {
+	EmailID    int      `json:"email_id"`   // Email the notification was matched to
+	Bounced    bool     `json:"bounced"`    // Whether the email was marked as bounced
+	Suppressed []string `json:"suppressed"` // Hard-bounced addresses added to the suppression list
+}
@@ -1,25 +1,65 @@
 package entities
 
-// Email status constants.
-const (
-	Failed     = "failed"     // Email delivery failed
-	Pending    = "pending"    // Email is waiting to be processed
-	Processing = "processing" // Email is currently being processed
-	Sent       = "sent"       // Email was successfully sent
+import "errors"
+
+// Email errors.
+var (
+	ErrEmailNotFound       = errors.New("email not found")
+	ErrEmptyFilter         = errors.New("filter must not be empty")
+	ErrIdempotencyConflict = errors.New("idempotency key was already used for a different email")
 )
 
 // Email represents an email record in the system.
 type Email struct {
-	ID      int    `db:"id"         json:"id"`         // Unique identifier
-	To      string `db:"to_address" json:"to_address"` // Recipient email address
-	Subject string `db:"subject"    json:"subject"`    // Email subject
-	Body    string `db:"body"       json:"body"`       // Email body content
-	Status  string `db:"status"     json:"status"`     // Current status of the email
+	ID             int    `db:"id"              json:"id"`            // Unique identifier
+	To             string `db:"to_address"      json:"to_address"`    // Recipient email address
+	Subject        string `db:"subject"         json:"subject"`       // Email subject
+	Body           string `db:"body"            json:"body"`          // Email body content
+	Status         Status `db:"status"          json:"status"`        // Current status of the email
+	Attempts       int    `db:"attempts"        json:"attempts"`      // Number of delivery attempts
+	MessageID      string `db:"message_id"      json:"message_id"`    // Message-ID header value, used to match bounces
+	Provider       string `db:"provider"        json:"provider"`      // Provider to deliver through, empty means routing
+	SentProvider   string `db:"sent_provider"   json:"sent_provider"` // Provider that accepted the email
+	From           string `db:"from_address"    json:"from_address"`  // From address, empty for emails queued before identities
+	FromName       string `db:"from_name"       json:"from_name"`     // Display name of the From header
+	ReplyTo        string `db:"reply_to"        json:"reply_to"`      // Reply-To address
+	HTML           string `db:"html_body"       json:"html"`          // HTML body, sent as an alternative to the plain text body
+	Track          bool   `db:"track"           json:"track"`         // Whether opens and clicks of the HTML body are tracked
+	Category       string `db:"category"        json:"category"`      // List or category of a bulk email, empty for transactional emails
+	RequestID      string `db:"request_id"      json:"request_id"`    // ID of the API request that created the email
+	TraceParent    string `db:"traceparent"     json:"-"`             // W3C trace context of the API request that created the email
+	IdempotencyKey string `db:"idempotency_key" json:"-"`             // Idempotency-Key of the request that created the email
 }
 
 // CreateEmail represents the data needed to create a new email.
 type CreateEmail struct {
-	To      string `json:"to_address" validate:"email,required"` // Recipient email address
-	Subject string `json:"subject"    validate:"required"`       // Email subject
-	Body    string `json:"body"       validate:"required"`       // Email body content
+	To             string `json:"to_address" validate:"email,required"`                            // Recipient email address
+	Subject        string `json:"subject"    validate:"required"`                                  // Email subject
+	Body           string `json:"body"       validate:"required"`                                  // Email body content
+	Provider       string `json:"provider"   validate:"omitempty,oneof=smtp sendgrid mailgun ses"` // Provider to deliver through
+	From           string `json:"from"       validate:"omitempty,email"`                           // Verified identity to send from, empty means the default one
+	HTML           string `json:"html"`                                                            // Optional HTML body
+	Track          bool   `json:"track"`                                                           // Track opens and clicks of the HTML body
+	Category       string `json:"category"   validate:"omitempty,max=64"`                          // List or category of a bulk email, enables one-click unsubscribe
+	IdempotencyKey string `json:"-"`                                                               // Idempotency-Key header, replays return the email created first
+}
+
+// MaxBatchSize is the maximum number of emails queued by a single batch request.
+const MaxBatchSize = 100
+
+// CreateEmails represents the data needed to queue a batch of emails.
+type CreateEmails struct {
+	Emails []CreateEmail `json:"emails" validate:"required,min=1,max=100,dive"` // Emails to queue, at most MaxBatchSize
+}
+
+// EmailFilter selects emails for bulk operations. Empty fields are ignored.
+type EmailFilter struct {
+	IDs    []int  `json:"ids"        validate:"omitempty,dive,gt=0"`                                                                       // Email identifiers
+	Status Status `json:"status"     validate:"omitempty,oneof=pending failed dead processing sent cancelled suppressed bounced rejected"` // Current status
+	To     string `json:"to_address" validate:"omitempty,email"`                                                                           // Recipient email address
+}
+
+// IsEmpty reports whether no filter fields are set.
+func (f EmailFilter) IsEmpty() bool {
+	return len(f.IDs) == 0 && f.Status == "" && f.To == ""
 }
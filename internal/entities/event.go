@@ -0,0 +1,31 @@
+package entities
+
+import "time"
+
+// EventType is the kind of engagement with a delivered email.
+type EventType string
+
+// Event type constants.
+const (
+	Open  EventType = "open"  // Recipient opened the email
+	Click EventType = "click" // Recipient followed a link in the email
+)
+
+// Event is a single tracked engagement with an email.
+type Event struct {
+	ID        int       `db:"id"         json:"id"`         // Unique identifier
+	EmailID   int       `db:"email_id"   json:"email_id"`   // Email the event belongs to
+	Type      EventType `db:"type"       json:"type"`       // Kind of the event
+	URL       string    `db:"url"        json:"url"`        // Followed link, empty for opens
+	UserAgent string    `db:"user_agent" json:"user_agent"` // User agent of the request
+	CreatedAt time.Time `db:"created_at" json:"created_at"` // When the event happened
+}
+
+// Stats aggregates email statuses and engagement.
+type Stats struct {
+	Statuses     map[Status]int64 `json:"statuses"`      // Number of emails per status
+	Opens        int64            `json:"opens"`         // Total number of opens
+	UniqueOpens  int64            `json:"unique_opens"`  // Number of emails opened at least once
+	Clicks       int64            `json:"clicks"`        // Total number of clicks
+	UniqueClicks int64            `json:"unique_clicks"` // Number of emails with at least one click
+}
@@ -0,0 +1,36 @@
+package entities
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// Identity errors.
+var (
+	ErrIdentityNotFound = errors.New("identity not found")
+	ErrIdentityExists   = errors.New("identity already exists")
+	ErrUnverifiedFrom   = errors.New("from address is not a verified identity")
+)
+
+// Identity is a from address or a whole from domain that emails may be sent from.
+type Identity struct {
+	ID          int       `db:"id"           json:"id"`           // Unique identifier
+	Address     string    `db:"address"      json:"address"`      // From address or domain
+	DisplayName string    `db:"display_name" json:"display_name"` // Default display name of the From header
+	ReplyTo     string    `db:"reply_to"     json:"reply_to"`     // Default Reply-To address
+	Verified    bool      `db:"verified"     json:"verified"`     // Whether emails may be sent from the identity
+	CreatedAt   time.Time `db:"created_at"   json:"created_at"`   // When the identity was registered
+}
+
+// IsDomain reports whether the identity covers all addresses of a domain.
+func (i Identity) IsDomain() bool {
+	return !strings.Contains(i.Address, "@")
+}
+
+// CreateIdentity represents the data needed to register an identity.
+type CreateIdentity struct {
+	Address     string `json:"address"      validate:"required,email|fqdn"` // From address or domain
+	DisplayName string `json:"display_name" validate:"max=255"`             // Default display name of the From header
+	ReplyTo     string `json:"reply_to"     validate:"omitempty,email"`     // Default Reply-To address
+}
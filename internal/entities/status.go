@@ -0,0 +1,124 @@
+package entities
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// Status is the delivery status of an email.
+type Status string
+
+// Email status constants.
+const (
+	Bounced    Status = "bounced"    // Email was returned by the recipient's mail server
+	Cancelled  Status = "cancelled"  // Email was cancelled by an operator
+	Dead       Status = "dead"       // Email exhausted all delivery attempts
+	Failed     Status = "failed"     // Email delivery failed
+	Pending    Status = "pending"    // Email is waiting to be processed
+	Processing Status = "processing" // Email is currently being processed
+	Rejected   Status = "rejected"   // Email was permanently rejected by the provider
+	Sent       Status = "sent"       // Email was successfully sent
+	Suppressed Status = "suppressed" // Email was not sent because the recipient is suppressed
+)
+
+// Status errors.
+var (
+	ErrInvalidTransition = errors.New("invalid status transition")
+	ErrUnknownStatus     = errors.New("unknown status")
+)
+
+// TransitionError is returned when an email cannot be moved from its current status to another one.
+type TransitionError struct {
+	ID   int    // Email identifier, 0 when the error is not related to a single email
+	From Status // Current status of the email
+	To   Status // Requested status
+}
+
+func (e *TransitionError) Error() string {
+	if e.ID == 0 {
+		return fmt.Sprintf("%s: %s -> %s", ErrInvalidTransition, e.From, e.To)
+	}
+
+	return fmt.Sprintf("%s: email %d %s -> %s", ErrInvalidTransition, e.ID, e.From, e.To)
+}
+
+// Is makes errors.Is(err, ErrInvalidTransition) match any TransitionError.
+func (e *TransitionError) Is(target error) bool {
+	return target == ErrInvalidTransition
+}
+
+// Statuses returns all known statuses.
+func Statuses() []Status {
+	return []Status{Pending, Processing, Sent, Failed, Dead, Cancelled, Suppressed, Bounced, Rejected}
+}
+
+// ParseStatus converts a string to a Status, returning ErrUnknownStatus for unknown values.
+func ParseStatus(s string) (Status, error) {
+	status := Status(s)
+	if !status.Valid() {
+		return "", fmt.Errorf("%w: %s", ErrUnknownStatus, s)
+	}
+
+	return status, nil
+}
+
+// Valid reports whether the status is one of the known statuses.
+func (s Status) Valid() bool {
+	return slices.Contains(Statuses(), s)
+}
+
+// Next returns the statuses an email may be moved to from s. This is the transition table
+// of the email state machine:
+//
+//	pending    -> processing, cancelled, suppressed
+//	processing -> sent, failed, dead, rejected, suppressed
+//	sent       -> bounced
+//	failed     -> processing, pending (retry), cancelled, bounced
+//	dead       -> pending (retry)
+//	suppressed -> pending (retry)
+//	cancelled, bounced, rejected are terminal
+//
+// Emails being delivered cannot be requeued, otherwise another worker would send them a second time.
+// Emails stuck in processing after a worker crash are reset to pending by the stuck email check,
+// which bypasses the state machine.
+func (s Status) Next() []Status {
+	switch s {
+	case Pending:
+		return []Status{Processing, Cancelled, Suppressed}
+	case Processing:
+		return []Status{Sent, Failed, Dead, Rejected, Suppressed}
+	case Sent:
+		return []Status{Bounced}
+	case Failed:
+		return []Status{Processing, Pending, Cancelled, Bounced}
+	case Dead, Suppressed:
+		return []Status{Pending}
+	case Cancelled, Bounced, Rejected:
+		return nil
+	default:
+		return nil
+	}
+}
+
+// CanTransitionTo reports whether an email in status s may be moved to the to status.
+func (s Status) CanTransitionTo(to Status) bool {
+	return slices.Contains(s.Next(), to)
+}
+
+// Terminal reports whether no transitions are allowed from s.
+func (s Status) Terminal() bool {
+	return len(s.Next()) == 0
+}
+
+// SourceStatuses returns all statuses from which an email may be moved to the to status.
+func SourceStatuses(to Status) []Status {
+	var from []Status
+	for _, s := range Statuses() {
+		if s.CanTransitionTo(to) {
+			from = append(from, s)
+		}
+	}
+
+	return from
+}
@@ -0,0 +1,24 @@
+package entities
+
+import "time"
+
+// StatusChange is an entry of the status change log of emails. Creating an email logs
+// a change without From.
+type StatusChange struct {
+	ID        int64     `db:"id"          json:"id"`          // Unique identifier, increasing with every change
+	EmailID   int       `db:"email_id"    json:"email_id"`    // Email whose status changed
+	From      Status    `db:"from_status" json:"from_status"` // Previous status, empty for new emails
+	Status    Status    `db:"status"      json:"status"`      // New status
+	CreatedAt time.Time `db:"created_at"  json:"created_at"`  // When the status changed
+}
+
+// StatusChangeFilter selects status changes. Empty fields are ignored.
+type StatusChangeFilter struct {
+	EmailID int    // Email identifier
+	Status  Status // New status
+}
+
+// Match reports whether the change is selected by the filter.
+func (f StatusChangeFilter) Match(c StatusChange) bool {
+	return (f.EmailID == 0 || c.EmailID == f.EmailID) && (f.Status == "" || c.Status == f.Status)
+}
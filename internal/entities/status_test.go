@@ -0,0 +1,85 @@
+package entities
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestStatus_CanTransitionTo(t *testing.T) {
+	tests := []struct {
+		from Status
+		to   Status
+		want bool
+	}{
+		{Pending, Processing, true},
+		{Pending, Cancelled, true},
+		{Pending, Sent, false},
+		{Processing, Sent, true},
+		{Processing, Failed, true},
+		{Processing, Dead, true},
+		{Processing, Rejected, true},
+		{Rejected, Pending, false},
+		{Failed, Rejected, false},
+		{Processing, Pending, false},
+		{Processing, Cancelled, false},
+		{Failed, Processing, true},
+		{Failed, Pending, true},
+		{Failed, Cancelled, true},
+		{Dead, Pending, true},
+		{Dead, Processing, false},
+		{Pending, Suppressed, true},
+		{Processing, Suppressed, true},
+		{Suppressed, Pending, true},
+		{Suppressed, Processing, false},
+		{Sent, Pending, false},
+		{Sent, Bounced, true},
+		{Failed, Bounced, true},
+		{Bounced, Pending, false},
+		{Sent, Cancelled, false},
+		{Cancelled, Pending, false},
+		{Status("unknown"), Pending, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.from)+"->"+string(tt.to), func(t *testing.T) {
+			if got := tt.from.CanTransitionTo(tt.to); got != tt.want {
+				t.Errorf("CanTransitionTo() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSourceStatuses(t *testing.T) {
+	got := SourceStatuses(Pending)
+	want := []Status{Failed, Dead, Suppressed}
+
+	if len(got) != len(want) {
+		t.Fatalf("SourceStatuses() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("SourceStatuses() = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestParseStatus(t *testing.T) {
+	if s, err := ParseStatus("sent"); err != nil || s != Sent {
+		t.Errorf("ParseStatus() = %v, %v, want %v", s, err, Sent)
+	}
+
+	if _, err := ParseStatus("unknown"); !errors.Is(err, ErrUnknownStatus) {
+		t.Errorf("ParseStatus() error = %v, want %v", err, ErrUnknownStatus)
+	}
+}
+
+func TestTransitionError(t *testing.T) {
+	var err error = &TransitionError{ID: 1, From: Sent, To: Pending}
+
+	if !errors.Is(err, ErrInvalidTransition) {
+		t.Error("TransitionError does not match ErrInvalidTransition")
+	}
+	if want := "invalid status transition: email 1 sent -> pending"; err.Error() != want {
+		t.Errorf("Error() = %q, want %q", err.Error(), want)
+	}
+}
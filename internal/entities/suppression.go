@@ -0,0 +1,41 @@
+package entities
+
+import (
+	"errors"
+	"time"
+)
+
+// SuppressionReason explains why an address is suppressed.
+type SuppressionReason string
+
+// Suppression reason constants.
+const (
+	Bounce      SuppressionReason = "bounce"      // Address hard-bounced
+	Complaint   SuppressionReason = "complaint"   // Recipient marked a message as spam
+	Unsubscribe SuppressionReason = "unsubscribe" // Recipient unsubscribed
+	Manual      SuppressionReason = "manual"      // Address was added by an operator
+)
+
+// Suppression errors.
+var (
+	ErrSuppressionNotFound = errors.New("suppression not found")
+	ErrRecipientSuppressed = errors.New("recipient is suppressed")
+)
+
+// Suppression represents an address that must not receive emails.
+type Suppression struct {
+	ID        int               `db:"id"         json:"id"`         // Unique identifier
+	Address   string            `db:"address"    json:"address"`    // Suppressed email address
+	Reason    SuppressionReason `db:"reason"     json:"reason"`     // Why the address is suppressed
+	ExpiresAt *time.Time        `db:"expires_at" json:"expires_at"` // When the suppression ends, nil means never
+	Category  string            `db:"category"   json:"category"`   // List or category the address is suppressed for, empty means all emails
+	CreatedAt time.Time         `db:"created_at" json:"created_at"` // When the address was suppressed
+}
+
+// CreateSuppression represents the data needed to suppress an address.
+type CreateSuppression struct {
+	Address   string            `json:"address"    validate:"email,required"`                                     // Email address to suppress
+	Reason    SuppressionReason `json:"reason"     validate:"required,oneof=bounce complaint unsubscribe manual"` // Why the address is suppressed
+	ExpiresAt *time.Time        `json:"expires_at"`                                                               // When the suppression ends, nil means never
+	Category  string            `json:"category"   validate:"omitempty,max=64"`                                   // List or category to suppress the address for, empty means all emails
+}
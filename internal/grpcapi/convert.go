@@ -0,0 +1,71 @@
+package grpcapi
+
+import (
+	"github.com/grishkovelli/betera-mailqusrv/internal/entities"
+	mailquv1 "github.com/grishkovelli/betera-mailqusrv/pkg/api/mailqu/v1"
+)
+
+// statuses maps the email statuses to their protobuf enum values.
+func statuses() map[entities.Status]mailquv1.Status {
+	return map[entities.Status]mailquv1.Status{
+		entities.Pending:    mailquv1.Status_STATUS_PENDING,
+		entities.Processing: mailquv1.Status_STATUS_PROCESSING,
+		entities.Sent:       mailquv1.Status_STATUS_SENT,
+		entities.Failed:     mailquv1.Status_STATUS_FAILED,
+		entities.Dead:       mailquv1.Status_STATUS_DEAD,
+		entities.Cancelled:  mailquv1.Status_STATUS_CANCELLED,
+		entities.Suppressed: mailquv1.Status_STATUS_SUPPRESSED,
+		entities.Bounced:    mailquv1.Status_STATUS_BOUNCED,
+		entities.Rejected:   mailquv1.Status_STATUS_REJECTED,
+	}
+}
+
+// statusToProto converts an email status, unknown statuses become STATUS_UNSPECIFIED.
+func statusToProto(s entities.Status) mailquv1.Status {
+	return statuses()[s]
+}
+
+// statusFromProto converts a protobuf status, reporting false for STATUS_UNSPECIFIED and unknown values.
+func statusFromProto(s mailquv1.Status) (entities.Status, bool) {
+	for status, v := range statuses() {
+		if v == s {
+			return status, true
+		}
+	}
+
+	return "", false
+}
+
+func emailToProto(e entities.Email) *mailquv1.Email {
+	return &mailquv1.Email{
+		Id:           int64(e.ID),
+		ToAddress:    e.To,
+		Subject:      e.Subject,
+		Body:         e.Body,
+		Status:       statusToProto(e.Status),
+		Attempts:     int32(e.Attempts), //nolint:gosec // attempts are bounded by the worker configuration
+		MessageId:    e.MessageID,
+		Provider:     e.Provider,
+		SentProvider: e.SentProvider,
+		FromAddress:  e.From,
+		FromName:     e.FromName,
+		ReplyTo:      e.ReplyTo,
+		Html:         e.HTML,
+		Track:        e.Track,
+		Category:     e.Category,
+		RequestId:    e.RequestID,
+	}
+}
+
+func createEmailFromProto(e *mailquv1.CreateEmail) entities.CreateEmail {
+	return entities.CreateEmail{
+		To:       e.GetToAddress(),
+		Subject:  e.GetSubject(),
+		Body:     e.GetBody(),
+		Provider: e.GetProvider(),
+		From:     e.GetFrom(),
+		HTML:     e.GetHtml(),
+		Track:    e.GetTrack(),
+		Category: e.GetCategory(),
+	}
+}
@@ -0,0 +1,171 @@
+package grpcapi
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/grishkovelli/betera-mailqusrv/config"
+	"github.com/grishkovelli/betera-mailqusrv/internal/entities"
+	mailquv1 "github.com/grishkovelli/betera-mailqusrv/pkg/api/mailqu/v1"
+)
+
+// maxIdempotencyKeyLen matches the limit of the Idempotency-Key header of the HTTP API.
+const maxIdempotencyKeyLen = 200
+
+// emailService defines the email operations exposed over gRPC.
+type emailService interface {
+	Create(ctx context.Context, p entities.CreateEmail) (entities.Email, error)
+	Get(ctx context.Context, id int) (entities.Email, error)
+	GetByStatus(ctx context.Context, status entities.Status, limit, cursor int) ([]entities.Email, error)
+}
+
+// EmailServer implements the MailQueue gRPC service on top of the email service.
+type EmailServer struct {
+	mailquv1.UnimplementedMailQueueServer
+
+	pageSize      int
+	watchInterval time.Duration
+	emailService  emailService
+}
+
+// NewEmailServer creates a new instance of EmailServer.
+func NewEmailServer(cfg config.Config, srv emailService) *EmailServer {
+	return &EmailServer{
+		pageSize:      cfg.Server.PageSize,
+		watchInterval: time.Duration(cfg.GRPC.WatchInterval) * time.Second,
+		emailService:  srv,
+	}
+}
+
+// SendEmail validates and queues an email.
+func (s *EmailServer) SendEmail(ctx context.Context, req *mailquv1.SendEmailRequest) (*mailquv1.Email, error) {
+	if len(req.GetIdempotencyKey()) > maxIdempotencyKeyLen {
+		return nil, errInvalidIdempotencyKey
+	}
+
+	p := createEmailFromProto(req.GetEmail())
+	if err := validateStruct(p); err != nil {
+		return nil, statusError(err)
+	}
+	p.IdempotencyKey = req.GetIdempotencyKey()
+
+	email, err := s.emailService.Create(ctx, p)
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	return emailToProto(email), nil
+}
+
+// BatchSend validates the whole batch up front and queues its emails one by one,
+// emails that cannot be queued are reported per item and do not affect the others.
+func (s *EmailServer) BatchSend(
+	ctx context.Context,
+	req *mailquv1.BatchSendRequest,
+) (*mailquv1.BatchSendResponse, error) {
+	key := req.GetIdempotencyKey()
+	if len(key) > maxIdempotencyKeyLen {
+		return nil, errInvalidIdempotencyKey
+	}
+
+	params := entities.CreateEmails{Emails: make([]entities.CreateEmail, len(req.GetEmails()))}
+	for i, e := range req.GetEmails() {
+		params.Emails[i] = createEmailFromProto(e)
+	}
+	if err := validateStruct(params); err != nil {
+		return nil, statusError(err)
+	}
+
+	results := make([]*mailquv1.BatchResult, len(params.Emails))
+	for i, p := range params.Emails {
+		if key != "" {
+			p.IdempotencyKey = key + ":" + strconv.Itoa(i)
+		}
+
+		email, err := s.emailService.Create(ctx, p)
+		if err != nil {
+			st := status.Convert(statusError(err))
+			results[i] = &mailquv1.BatchResult{Result: &mailquv1.BatchResult_Error{Error: &mailquv1.Error{
+				Code:    uint32(st.Code()), //nolint:gosec // codes are small positive numbers
+				Message: st.Message(),
+			}}}
+			continue
+		}
+		results[i] = &mailquv1.BatchResult{Result: &mailquv1.BatchResult_Email{Email: emailToProto(email)}}
+	}
+
+	return &mailquv1.BatchSendResponse{Results: results}, nil
+}
+
+// GetEmail returns a single email.
+func (s *EmailServer) GetEmail(ctx context.Context, req *mailquv1.GetEmailRequest) (*mailquv1.Email, error) {
+	email, err := s.emailService.Get(ctx, int(req.GetId()))
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	return emailToProto(email), nil
+}
+
+// ListEmails returns a page of emails with the given status.
+func (s *EmailServer) ListEmails(
+	ctx context.Context,
+	req *mailquv1.ListEmailsRequest,
+) (*mailquv1.ListEmailsResponse, error) {
+	st, ok := statusFromProto(req.GetStatus())
+	if !ok {
+		return nil, status.Errorf(codes.InvalidArgument, "%s: %s", entities.ErrUnknownStatus, req.GetStatus())
+	}
+
+	emails, err := s.emailService.GetByStatus(ctx, st, s.pageSize, int(req.GetCursor()))
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	resp := &mailquv1.ListEmailsResponse{Emails: make([]*mailquv1.Email, len(emails))}
+	for i, e := range emails {
+		resp.Emails[i] = emailToProto(e)
+	}
+	// a full page may be followed by more emails
+	if len(emails) > 0 && len(emails) == s.pageSize {
+		resp.NextCursor = int64(emails[len(emails)-1].ID)
+	}
+
+	return resp, nil
+}
+
+// WatchEmail sends the email and then polls it, sending it again whenever its status changes.
+// The stream ends once the email reaches a terminal status or the client goes away.
+func (s *EmailServer) WatchEmail(req *mailquv1.WatchEmailRequest, stream mailquv1.MailQueue_WatchEmailServer) error {
+	ctx := stream.Context()
+	ticker := time.NewTicker(s.watchInterval)
+	defer ticker.Stop()
+
+	var last entities.Status
+	for {
+		email, err := s.emailService.Get(ctx, int(req.GetId()))
+		if err != nil {
+			return statusError(err)
+		}
+
+		if email.Status != last {
+			if err = stream.Send(emailToProto(email)); err != nil {
+				return err
+			}
+			last = email.Status
+		}
+		if email.Status.Terminal() {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return statusError(ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"log"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/grishkovelli/betera-mailqusrv/internal/address"
+	"github.com/grishkovelli/betera-mailqusrv/internal/entities"
+)
+
+var errInvalidIdempotencyKey = status.Errorf(
+	codes.InvalidArgument, "idempotency_key must be at most %d characters long", maxIdempotencyKeyLen,
+)
+
+// validateStruct validates s, fields are reported by their JSON names which match the protobuf field names.
+func validateStruct(s any) error {
+	v := validator.New(validator.WithRequiredStructEnabled())
+	v.RegisterTagNameFunc(func(f reflect.StructField) string {
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+
+	return v.Struct(s)
+}
+
+// statusError converts service errors to gRPC status errors. Invalid fields are reported
+// as BadRequest details, internal errors are logged and not exposed to the client.
+func statusError(err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	var (
+		validationErrs validator.ValidationErrors
+		addrErr        *address.Error
+	)
+	switch {
+	case errors.As(err, &validationErrs):
+		violations := make([]*errdetails.BadRequest_FieldViolation, len(validationErrs))
+		for i, fe := range validationErrs {
+			field := fe.Field()
+			if _, path, ok := strings.Cut(fe.Namespace(), "."); ok {
+				field = path
+			}
+			violations[i] = &errdetails.BadRequest_FieldViolation{Field: field, Description: fe.Error()}
+		}
+		return withDetails(codes.InvalidArgument, "request validation failed", &errdetails.BadRequest{
+			FieldViolations: violations,
+		})
+	case errors.As(err, &addrErr):
+		violation := &errdetails.BadRequest_FieldViolation{Field: "to_address", Description: addrErr.Reason}
+		return withDetails(codes.InvalidArgument, err.Error(), &errdetails.BadRequest{
+			FieldViolations: []*errdetails.BadRequest_FieldViolation{violation},
+		})
+	case errors.Is(err, entities.ErrEmailNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, entities.ErrUnknownStatus), errors.Is(err, entities.ErrEmptyFilter):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, entities.ErrIdempotencyConflict):
+		return status.Error(codes.AlreadyExists, err.Error())
+	case errors.Is(err, entities.ErrInvalidTransition),
+		errors.Is(err, entities.ErrRecipientSuppressed),
+		errors.Is(err, entities.ErrUnverifiedFrom):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	default:
+		log.Printf("grpc: %v", err)
+		return status.Error(codes.Internal, "internal server error")
+	}
+}
+
+// withDetails creates a status error with details, falling back to the plain status if they cannot be encoded.
+func withDetails(code codes.Code, msg string, details *errdetails.BadRequest) error {
+	st := status.New(code, msg)
+	if detailed, err := st.WithDetails(details); err == nil {
+		st = detailed
+	}
+
+	return st.Err()
+}
@@ -0,0 +1,175 @@
+// Package grpcapi serves the MailQueue gRPC service along with gRPC health checking and reflection.
+package grpcapi
+
+import (
+	"context"
+	"expvar"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+
+	"github.com/grishkovelli/betera-mailqusrv/internal/requestid"
+	"github.com/grishkovelli/betera-mailqusrv/internal/signing"
+	mailquv1 "github.com/grishkovelli/betera-mailqusrv/pkg/api/mailqu/v1"
+)
+
+// Metadata keys.
+const (
+	requestIDKey     = "x-request-id"  // Request ID read from calls and echoed in their headers
+	authorizationKey = "authorization" // Bearer token of calls
+)
+
+// NewServer creates a gRPC server serving emails, the health service and reflection. Calls carry
+// a request ID and are logged, panics are recovered into Internal errors and counted in panics.
+// If token is not empty, calls other than health checks require it as a bearer token.
+func NewServer(emails *EmailServer, token string, logger *slog.Logger, panics *expvar.Map) *grpc.Server {
+	unary := []grpc.UnaryServerInterceptor{unaryInterceptor(logger, panics)}
+	stream := []grpc.StreamServerInterceptor{streamInterceptor(logger, panics)}
+	if token != "" {
+		unary = append(unary, unaryAuthInterceptor(token))
+		stream = append(stream, streamAuthInterceptor(token))
+	}
+
+	s := grpc.NewServer(grpc.ChainUnaryInterceptor(unary...), grpc.ChainStreamInterceptor(stream...))
+
+	mailquv1.RegisterMailQueueServer(s, emails)
+
+	healthSrv := health.NewServer()
+	healthSrv.SetServingStatus("", healthpb.HealthCheckResponse_SERVING)
+	healthSrv.SetServingStatus(mailquv1.MailQueue_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_SERVING)
+	healthpb.RegisterHealthServer(s, healthSrv)
+
+	reflection.Register(s)
+
+	return s
+}
+
+func unaryInterceptor(logger *slog.Logger, panics *expvar.Map) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, h grpc.UnaryHandler) (any, error) {
+		var resp any
+		err := serve(ctx, info.FullMethod, logger, panics, func(ctx context.Context) error {
+			var err error
+			resp, err = h(ctx, req)
+			return err
+		})
+
+		return resp, err
+	}
+}
+
+func streamInterceptor(logger *slog.Logger, panics *expvar.Map) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, h grpc.StreamHandler) error {
+		return serve(ss.Context(), info.FullMethod, logger, panics, func(ctx context.Context) error {
+			return h(srv, &serverStream{ServerStream: ss, ctx: ctx})
+		})
+	}
+}
+
+func unaryAuthInterceptor(token string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, h grpc.UnaryHandler) (any, error) {
+		if err := authorize(ctx, info.FullMethod, token); err != nil {
+			return nil, err
+		}
+
+		return h(ctx, req)
+	}
+}
+
+func streamAuthInterceptor(token string) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, h grpc.StreamHandler) error {
+		if err := authorize(ss.Context(), info.FullMethod, token); err != nil {
+			return err
+		}
+
+		return h(srv, ss)
+	}
+}
+
+// authorize requires the token in the authorization metadata of a call as `Bearer <token>`,
+// health checks are answered without it so that probes do not need the token.
+func authorize(ctx context.Context, method, token string) error {
+	if strings.HasPrefix(method, "/"+healthpb.Health_ServiceDesc.ServiceName+"/") {
+		return nil
+	}
+
+	got := ""
+	if values := metadata.ValueFromIncomingContext(ctx, authorizationKey); len(values) > 0 {
+		got = values[0]
+	}
+	got, ok := strings.CutPrefix(got, "Bearer ")
+	if !ok || !signing.Equal(got, token) {
+		return status.Error(codes.Unauthenticated, "missing or invalid bearer token")
+	}
+
+	return nil
+}
+
+// serve runs a call with its request ID in the context, recovers its panics and logs it.
+func serve(
+	ctx context.Context,
+	method string,
+	logger *slog.Logger,
+	panics *expvar.Map,
+	call func(ctx context.Context) error,
+) (err error) {
+	id := ""
+	if ids := metadata.ValueFromIncomingContext(ctx, requestIDKey); len(ids) > 0 {
+		id = ids[0]
+	}
+	if !requestid.Valid(id) {
+		id = requestid.New()
+	}
+	ctx = requestid.NewContext(ctx, id)
+	_ = grpc.SetHeader(ctx, metadata.Pairs(requestIDKey, id))
+
+	start := time.Now()
+	defer func() {
+		if rec := recover(); rec != nil {
+			panics.Add("grpc", 1)
+			logger.ErrorContext(ctx, "grpc handler panic",
+				"method", method,
+				"panic", fmt.Sprint(rec),
+				"stack", string(debug.Stack()),
+			)
+			err = status.Error(codes.Internal, "internal server error")
+		}
+
+		code := status.Code(err)
+		level := slog.LevelInfo
+		switch code { //nolint:exhaustive // other codes are logged at info level
+		case codes.Internal, codes.Unknown, codes.DataLoss, codes.Unavailable:
+			level = slog.LevelError
+		case codes.InvalidArgument, codes.NotFound, codes.FailedPrecondition, codes.DeadlineExceeded:
+			level = slog.LevelWarn
+		}
+		logger.LogAttrs(ctx, level, "grpc call",
+			slog.Group("grpc",
+				slog.String("method", method),
+				slog.String("code", code.String()),
+				slog.Duration("duration", time.Since(start)),
+			))
+	}()
+
+	return call(ctx)
+}
+
+// serverStream replaces the context of a stream with one carrying the request ID.
+type serverStream struct {
+	grpc.ServerStream
+
+	ctx context.Context //nolint:containedctx // streams expose their context through Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
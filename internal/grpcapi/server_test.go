@@ -0,0 +1,354 @@
+package grpcapi
+
+import (
+	"context"
+	"expvar"
+	"io"
+	"log/slog"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/grishkovelli/betera-mailqusrv/internal/entities"
+	"github.com/grishkovelli/betera-mailqusrv/internal/requestid"
+	mailquv1 "github.com/grishkovelli/betera-mailqusrv/pkg/api/mailqu/v1"
+)
+
+type MockEmailService struct {
+	mock.Mock
+}
+
+var _ emailService = (*MockEmailService)(nil)
+
+func (m *MockEmailService) Create(ctx context.Context, p entities.CreateEmail) (entities.Email, error) {
+	args := m.Called(ctx, p)
+	return args.Get(0).(entities.Email), args.Error(1)
+}
+
+func (m *MockEmailService) Get(ctx context.Context, id int) (entities.Email, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(entities.Email), args.Error(1)
+}
+
+func (m *MockEmailService) GetByStatus(
+	ctx context.Context,
+	status entities.Status,
+	limit, cursor int,
+) ([]entities.Email, error) {
+	args := m.Called(ctx, status, limit, cursor)
+	return args.Get(0).([]entities.Email), args.Error(1)
+}
+
+// newConn serves srv over an in-memory listener and returns a client connection to it.
+func newConn(t *testing.T, srv emailService) *grpc.ClientConn {
+	t.Helper()
+
+	return newTokenConn(t, srv, "")
+}
+
+// newTokenConn connects to a server that requires the given API token.
+func newTokenConn(t *testing.T, srv emailService, token string) *grpc.ClientConn {
+	t.Helper()
+
+	emails := &EmailServer{pageSize: 2, watchInterval: time.Millisecond, emailService: srv}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	s := NewServer(emails, token, logger, new(expvar.Map))
+
+	lis := bufconn.Listen(1 << 20)
+	go func() { _ = s.Serve(lis) }()
+	t.Cleanup(s.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return conn
+}
+
+func TestEmailServer_SendEmail(t *testing.T) {
+	tests := []struct {
+		name       string
+		req        *mailquv1.SendEmailRequest
+		mockError  error
+		wantCode   codes.Code
+		wantFields []string
+	}{
+		{
+			name: "successful send",
+			req: &mailquv1.SendEmailRequest{
+				Email:          &mailquv1.CreateEmail{ToAddress: "test@example.com", Subject: "Subject", Body: "Body"},
+				IdempotencyKey: "order-1",
+			},
+			wantCode: codes.OK,
+		},
+		{
+			name: "invalid fields",
+			req: &mailquv1.SendEmailRequest{
+				Email: &mailquv1.CreateEmail{ToAddress: "invalid", Body: "Body"},
+			},
+			wantCode:   codes.InvalidArgument,
+			wantFields: []string{"to_address", "subject"},
+		},
+		{
+			name: "suppressed recipient",
+			req: &mailquv1.SendEmailRequest{
+				Email: &mailquv1.CreateEmail{ToAddress: "test@example.com", Subject: "Subject", Body: "Body"},
+			},
+			mockError: entities.ErrRecipientSuppressed,
+			wantCode:  codes.FailedPrecondition,
+		},
+		{
+			name: "idempotency key of another email",
+			req: &mailquv1.SendEmailRequest{
+				Email:          &mailquv1.CreateEmail{ToAddress: "test@example.com", Subject: "Subject", Body: "Body"},
+				IdempotencyKey: "order-1",
+			},
+			mockError: entities.ErrIdempotencyConflict,
+			wantCode:  codes.AlreadyExists,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := new(MockEmailService)
+			if tt.wantFields == nil {
+				srv.On("Create", mock.Anything, entities.CreateEmail{
+					To:             "test@example.com",
+					Subject:        "Subject",
+					Body:           "Body",
+					IdempotencyKey: tt.req.GetIdempotencyKey(),
+				}).Return(entities.Email{ID: 1, To: "test@example.com", Status: entities.Pending}, tt.mockError)
+			}
+			client := mailquv1.NewMailQueueClient(newConn(t, srv))
+
+			email, err := client.SendEmail(t.Context(), tt.req)
+			st := status.Convert(err)
+			require.Equal(t, tt.wantCode, st.Code(), st.Message())
+			srv.AssertExpectations(t)
+
+			if tt.wantCode == codes.OK {
+				assert.Equal(t, int64(1), email.GetId())
+				assert.Equal(t, mailquv1.Status_STATUS_PENDING, email.GetStatus())
+			}
+			if tt.wantFields != nil {
+				require.Len(t, st.Details(), 1)
+				var fields []string
+				for _, v := range st.Details()[0].(*errdetails.BadRequest).GetFieldViolations() {
+					fields = append(fields, v.GetField())
+				}
+				assert.Equal(t, tt.wantFields, fields)
+			}
+		})
+	}
+}
+
+func TestEmailServer_BatchSend(t *testing.T) {
+	srv := new(MockEmailService)
+	srv.On("Create", mock.Anything, mock.MatchedBy(func(p entities.CreateEmail) bool {
+		return p.IdempotencyKey == "batch:0"
+	})).Return(entities.Email{ID: 1, Status: entities.Pending}, nil)
+	srv.On("Create", mock.Anything, mock.MatchedBy(func(p entities.CreateEmail) bool {
+		return p.IdempotencyKey == "batch:1"
+	})).Return(entities.Email{}, entities.ErrRecipientSuppressed)
+	client := mailquv1.NewMailQueueClient(newConn(t, srv))
+
+	email := &mailquv1.CreateEmail{ToAddress: "test@example.com", Subject: "Subject", Body: "Body"}
+	resp, err := client.BatchSend(t.Context(), &mailquv1.BatchSendRequest{
+		Emails:         []*mailquv1.CreateEmail{email, email},
+		IdempotencyKey: "batch",
+	})
+	require.NoError(t, err)
+	require.Len(t, resp.GetResults(), 2)
+	assert.Equal(t, int64(1), resp.GetResults()[0].GetEmail().GetId())
+	assert.Equal(t, uint32(codes.FailedPrecondition), resp.GetResults()[1].GetError().GetCode())
+
+	_, err = client.BatchSend(t.Context(), &mailquv1.BatchSendRequest{})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestEmailServer_GetEmail(t *testing.T) {
+	srv := new(MockEmailService)
+	srv.On("Get", mock.Anything, 1).Return(entities.Email{ID: 1, Status: entities.Sent}, nil)
+	srv.On("Get", mock.Anything, 2).Return(entities.Email{}, entities.ErrEmailNotFound)
+	srv.On("Get", mock.Anything, 3).Return(entities.Email{}, assert.AnError)
+	client := mailquv1.NewMailQueueClient(newConn(t, srv))
+
+	email, err := client.GetEmail(t.Context(), &mailquv1.GetEmailRequest{Id: 1})
+	require.NoError(t, err)
+	assert.Equal(t, mailquv1.Status_STATUS_SENT, email.GetStatus())
+
+	_, err = client.GetEmail(t.Context(), &mailquv1.GetEmailRequest{Id: 2})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	_, err = client.GetEmail(t.Context(), &mailquv1.GetEmailRequest{Id: 3})
+	assert.Equal(t, codes.Internal, status.Code(err))
+	assert.Equal(t, "internal server error", status.Convert(err).Message())
+}
+
+func TestEmailServer_ListEmails(t *testing.T) {
+	srv := new(MockEmailService)
+	srv.On("GetByStatus", mock.Anything, entities.Failed, 2, 0).
+		Return([]entities.Email{{ID: 1, Status: entities.Failed}, {ID: 4, Status: entities.Failed}}, nil)
+	srv.On("GetByStatus", mock.Anything, entities.Failed, 2, 4).
+		Return([]entities.Email{{ID: 7, Status: entities.Failed}}, nil)
+	client := mailquv1.NewMailQueueClient(newConn(t, srv))
+
+	resp, err := client.ListEmails(t.Context(), &mailquv1.ListEmailsRequest{Status: mailquv1.Status_STATUS_FAILED})
+	require.NoError(t, err)
+	assert.Len(t, resp.GetEmails(), 2)
+	assert.Equal(t, int64(4), resp.GetNextCursor())
+
+	resp, err = client.ListEmails(t.Context(), &mailquv1.ListEmailsRequest{
+		Status: mailquv1.Status_STATUS_FAILED,
+		Cursor: resp.GetNextCursor(),
+	})
+	require.NoError(t, err)
+	assert.Len(t, resp.GetEmails(), 1)
+	assert.Zero(t, resp.GetNextCursor())
+
+	_, err = client.ListEmails(t.Context(), &mailquv1.ListEmailsRequest{})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestEmailServer_WatchEmail(t *testing.T) {
+	srv := new(MockEmailService)
+	srv.On("Get", mock.Anything, 1).Return(entities.Email{ID: 1, Status: entities.Pending}, nil).Times(3)
+	srv.On("Get", mock.Anything, 1).Return(entities.Email{ID: 1, Status: entities.Processing}, nil).Once()
+	srv.On("Get", mock.Anything, 1).Return(entities.Email{ID: 1, Status: entities.Cancelled}, nil).Once()
+	client := mailquv1.NewMailQueueClient(newConn(t, srv))
+
+	stream, err := client.WatchEmail(t.Context(), &mailquv1.WatchEmailRequest{Id: 1})
+	require.NoError(t, err)
+
+	var got []mailquv1.Status
+	for {
+		email, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		got = append(got, email.GetStatus())
+	}
+
+	// unchanged statuses are not repeated and the stream ends at the terminal status
+	assert.Equal(t, []mailquv1.Status{
+		mailquv1.Status_STATUS_PENDING,
+		mailquv1.Status_STATUS_PROCESSING,
+		mailquv1.Status_STATUS_CANCELLED,
+	}, got)
+	srv.AssertExpectations(t)
+}
+
+func TestEmailServer_WatchEmailNotFound(t *testing.T) {
+	srv := new(MockEmailService)
+	srv.On("Get", mock.Anything, 1).Return(entities.Email{}, entities.ErrEmailNotFound)
+	client := mailquv1.NewMailQueueClient(newConn(t, srv))
+
+	stream, err := client.WatchEmail(t.Context(), &mailquv1.WatchEmailRequest{Id: 1})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestServer_RequestID(t *testing.T) {
+	srv := new(MockEmailService)
+	srv.On("Get", mock.MatchedBy(func(ctx context.Context) bool {
+		return requestid.FromContext(ctx) == "req-1"
+	}), 1).Return(entities.Email{ID: 1, Status: entities.Sent}, nil)
+	client := mailquv1.NewMailQueueClient(newConn(t, srv))
+
+	var header metadata.MD
+	ctx := metadata.AppendToOutgoingContext(t.Context(), requestIDKey, "req-1")
+	_, err := client.GetEmail(ctx, &mailquv1.GetEmailRequest{Id: 1}, grpc.Header(&header))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"req-1"}, header.Get(requestIDKey))
+}
+
+func TestServer_Auth(t *testing.T) {
+	srv := new(MockEmailService)
+	srv.On("Get", mock.Anything, 1).Return(entities.Email{ID: 1, Status: entities.Sent}, nil)
+	conn := newTokenConn(t, srv, "secret")
+	client := mailquv1.NewMailQueueClient(conn)
+
+	tests := []struct {
+		name     string
+		md       []string
+		wantCode codes.Code
+	}{
+		{name: "no token", wantCode: codes.Unauthenticated},
+		{name: "wrong token", md: []string{"authorization", "Bearer other"}, wantCode: codes.Unauthenticated},
+		{name: "not a bearer token", md: []string{"authorization", "secret"}, wantCode: codes.Unauthenticated},
+		{name: "valid token", md: []string{"authorization", "Bearer secret"}, wantCode: codes.OK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := metadata.AppendToOutgoingContext(t.Context(), tt.md...)
+
+			_, err := client.GetEmail(ctx, &mailquv1.GetEmailRequest{Id: 1})
+			assert.Equal(t, tt.wantCode, status.Code(err), "GetEmail")
+
+			_, err = client.SendEmail(ctx, &mailquv1.SendEmailRequest{})
+			if tt.wantCode == codes.Unauthenticated {
+				assert.Equal(t, codes.Unauthenticated, status.Code(err), "SendEmail")
+			}
+
+			stream, err := client.WatchEmail(ctx, &mailquv1.WatchEmailRequest{Id: 1})
+			require.NoError(t, err)
+			_, err = stream.Recv()
+			assert.Equal(t, tt.wantCode, status.Code(err), "WatchEmail")
+		})
+	}
+
+	// probes check the health without the token
+	resp, err := healthpb.NewHealthClient(conn).Check(t.Context(), &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.GetStatus())
+}
+
+func TestServer_Health(t *testing.T) {
+	conn := newConn(t, new(MockEmailService))
+	client := healthpb.NewHealthClient(conn)
+
+	for _, service := range []string{"", mailquv1.MailQueue_ServiceDesc.ServiceName} {
+		resp, err := client.Check(t.Context(), &healthpb.HealthCheckRequest{Service: service})
+		require.NoError(t, err)
+		assert.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.GetStatus())
+	}
+}
+
+func TestServer_Reflection(t *testing.T) {
+	conn := newConn(t, new(MockEmailService))
+	stream, err := reflectionpb.NewServerReflectionClient(conn).ServerReflectionInfo(t.Context())
+	require.NoError(t, err)
+
+	err = stream.Send(&reflectionpb.ServerReflectionRequest{
+		MessageRequest: &reflectionpb.ServerReflectionRequest_ListServices{},
+	})
+	require.NoError(t, err)
+	resp, err := stream.Recv()
+	require.NoError(t, err)
+
+	var services []string
+	for _, s := range resp.GetListServicesResponse().GetService() {
+		services = append(services, s.GetName())
+	}
+	assert.Contains(t, services, mailquv1.MailQueue_ServiceDesc.ServiceName)
+	assert.Contains(t, services, healthpb.Health_ServiceDesc.ServiceName)
+}
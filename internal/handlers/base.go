@@ -1,41 +1,170 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
 	"log"
+	"mime"
 	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
 
 	"github.com/go-playground/validator/v10"
+
+	"github.com/grishkovelli/betera-mailqusrv/config"
+	"github.com/grishkovelli/betera-mailqusrv/internal/address"
+	"github.com/grishkovelli/betera-mailqusrv/internal/bounce"
+	"github.com/grishkovelli/betera-mailqusrv/internal/entities"
+	"github.com/grishkovelli/betera-mailqusrv/internal/signing"
+)
+
+// statusClientClosedRequest is the non-standard status logged for requests cancelled by the client.
+const statusClientClosedRequest = 499
+
+// Request body errors.
+var (
+	errInvalidJSON          = errors.New("invalid json")
+	errUnsupportedMediaType = errors.New("content type must be application/json")
+)
+
+// IdempotencyKeyHeader is the request header that makes repeated sends return the email queued first.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// maxIdempotencyKeyLen leaves room in the 255 characters of the idempotency_key column
+// for the index suffix of batch items.
+const maxIdempotencyKeyLen = 200
+
+var errInvalidIdempotencyKey = fmt.Errorf(
+	"%s must be at most %d characters long", IdempotencyKeyHeader, maxIdempotencyKeyLen,
 )
 
 func validateStruct(s any) error {
 	v := validator.New(validator.WithRequiredStructEnabled())
+	// report fields by their JSON names
+	v.RegisterTagNameFunc(func(f reflect.StructField) string {
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+
 	return v.Struct(s)
 }
 
-func validateParams(r *http.Request, s any) error {
-	if err := json.NewDecoder(r.Body).Decode(&s); err != nil {
-		return errors.New("invalid params")
+// validateParams decodes the JSON request body into s and validates it. Bodies of other content types
+// are rejected with errUnsupportedMediaType, oversized ones with *http.MaxBytesError.
+func validateParams(w http.ResponseWriter, r *http.Request, cfg config.Server, s any) error {
+	if mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err != nil ||
+		mediaType != "application/json" {
+		return errUnsupportedMediaType
+	}
+
+	dec := json.NewDecoder(limitBody(w, r, cfg))
+	if cfg.StrictJSON {
+		dec.DisallowUnknownFields()
+	}
+
+	if err := dec.Decode(&s); err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			return maxErr
+		}
+		return fmt.Errorf("%w: %w", errInvalidJSON, err)
 	}
 
 	return validateStruct(s)
 }
 
+// limitBody limits the request body to the configured maximum size.
+func limitBody(w http.ResponseWriter, r *http.Request, cfg config.Server) io.Reader {
+	if cfg.MaxBodySize > 0 {
+		r.Body = http.MaxBytesReader(w, r.Body, cfg.MaxBodySize)
+	}
+
+	return r.Body
+}
+
+// pathID parses the id path value of the request.
+func pathID(r *http.Request) (int, error) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		return 0, fmt.Errorf("invalid id: %s", r.PathValue("id"))
+	}
+
+	return id, nil
+}
+
+// idempotencyKey returns the optional Idempotency-Key header of the request.
+func idempotencyKey(r *http.Request) (string, error) {
+	key := r.Header.Get(IdempotencyKeyHeader)
+	if len(key) > maxIdempotencyKeyLen {
+		return "", errInvalidIdempotencyKey
+	}
+
+	return key, nil
+}
+
+// cursorParam parses the optional cursor query parameter of the request.
+func cursorParam(r *http.Request) (int, error) {
+	c := r.URL.Query().Get("cursor")
+	if c == "" {
+		return 0, nil
+	}
+
+	return strconv.Atoi(c)
+}
+
 func renderJSON(w http.ResponseWriter, code int, payload any) {
 	resp, err := json.Marshal(payload)
 	if err != nil {
 		code = http.StatusUnprocessableEntity
-		resp, _ = json.Marshal(map[string]string{"error": err.Error()})
+		resp, _ = json.Marshal(newErrorResponse(code, err))
 	}
 
-	w.Header().Set("Content-Type", "serverlication/json")
+	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(code)
 	if _, err = w.Write(resp); err != nil {
 		log.Printf("failed to write response: %v", err)
 	}
 }
 
-func renderError(w http.ResponseWriter, code int, err error) {
-	renderJSON(w, code, map[string]string{"error": err.Error()})
+// errorStatus maps service errors to HTTP status codes.
+func errorStatus(err error) int {
+	switch {
+	case errors.Is(err, entities.ErrEmailNotFound),
+		errors.Is(err, entities.ErrSuppressionNotFound),
+		errors.Is(err, entities.ErrIdentityNotFound),
+		errors.Is(err, signing.ErrInvalidToken):
+		return http.StatusNotFound
+	case errors.Is(err, entities.ErrInvalidTransition),
+		errors.Is(err, entities.ErrIdentityExists),
+		errors.Is(err, entities.ErrIdempotencyConflict):
+		return http.StatusConflict
+	case errors.Is(err, entities.ErrEmptyFilter),
+		errors.Is(err, entities.ErrUnknownStatus),
+		errors.Is(err, address.ErrUndeliverable),
+		errors.Is(err, errInvalidJSON),
+		errors.Is(err, errInvalidIdempotencyKey),
+		errors.As(err, new(validator.ValidationErrors)):
+		return http.StatusBadRequest
+	case errors.As(err, new(*http.MaxBytesError)):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, errUnsupportedMediaType):
+		return http.StatusUnsupportedMediaType
+	case errors.Is(err, entities.ErrRecipientSuppressed),
+		errors.Is(err, entities.ErrUnverifiedFrom),
+		errors.Is(err, bounce.ErrNotDSN):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout
+	case errors.Is(err, context.Canceled):
+		return statusClientClosedRequest
+	default:
+		return http.StatusInternalServerError
+	}
 }
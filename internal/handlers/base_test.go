@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grishkovelli/betera-mailqusrv/config"
+	"github.com/grishkovelli/betera-mailqusrv/internal/entities"
+)
+
+func TestValidateParams_Body(t *testing.T) {
+	const body = `{"to_address": "test@example.com", "subject": "Test Subject", "body": "Test Body"}`
+
+	tests := []struct {
+		name           string
+		cfg            config.Server
+		contentType    string
+		body           string
+		expectedStatus int
+		expectedCode   string
+		expectedFields []fieldError
+	}{
+		{
+			name:           "json",
+			contentType:    "application/json",
+			body:           body,
+			expectedStatus: http.StatusAccepted,
+		},
+		{
+			name:           "json with charset",
+			contentType:    "application/json; charset=utf-8",
+			body:           body,
+			expectedStatus: http.StatusAccepted,
+		},
+		{
+			name:           "missing content type",
+			body:           body,
+			expectedStatus: http.StatusUnsupportedMediaType,
+			expectedCode:   "unsupported_media_type",
+		},
+		{
+			name:           "form content type",
+			contentType:    "application/x-www-form-urlencoded",
+			body:           body,
+			expectedStatus: http.StatusUnsupportedMediaType,
+			expectedCode:   "unsupported_media_type",
+		},
+		{
+			name:           "body within limit",
+			cfg:            config.Server{MaxBodySize: int64(len(body))},
+			contentType:    "application/json",
+			body:           body,
+			expectedStatus: http.StatusAccepted,
+		},
+		{
+			name:           "oversized body",
+			cfg:            config.Server{MaxBodySize: 16},
+			contentType:    "application/json",
+			body:           body,
+			expectedStatus: http.StatusRequestEntityTooLarge,
+			expectedCode:   "request_too_large",
+		},
+		{
+			name:           "unknown field",
+			contentType:    "application/json",
+			body:           strings.TrimSuffix(body, "}") + `, "priority": 1}`,
+			expectedStatus: http.StatusAccepted,
+		},
+		{
+			name:           "unknown field in strict mode",
+			cfg:            config.Server{StrictJSON: true},
+			contentType:    "application/json",
+			body:           strings.TrimSuffix(body, "}") + `, "priority": 1}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   codeInvalidJSON,
+			expectedFields: []fieldError{{Field: "priority", Rule: "unknown", Message: "is not allowed"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockEmailService)
+			handler := NewEmailHandler(tt.cfg, mockService)
+
+			req := httptest.NewRequest(http.MethodPost, "/send-email", strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			w := httptest.NewRecorder()
+
+			if tt.expectedStatus == http.StatusAccepted {
+				mockService.On("Create", mock.Anything, mock.Anything).Return(entities.Email{ID: 1}, nil)
+			}
+
+			handler.Send(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedCode != "" {
+				assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+				var got errorResponse
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&got))
+				assert.Equal(t, tt.expectedCode, got.Code)
+				assert.Equal(t, tt.expectedFields, got.Fields)
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
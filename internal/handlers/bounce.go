@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"context"
+	"io"
+	"net/http"
+
+	"github.com/grishkovelli/betera-mailqusrv/config"
+	"github.com/grishkovelli/betera-mailqusrv/internal/entities"
+)
+
+// bounceService defines the interface for bounce processing.
+type bounceService interface {
+	Process(ctx context.Context, raw io.Reader) (entities.BounceResult, error)
+}
+
+// BounceHandler handles HTTP requests with inbound delivery status notifications.
+type BounceHandler struct {
+	cfg           config.Server
+	bounceService bounceService
+}
+
+// NewBounceHandler creates a new instance of BounceHandler.
+func NewBounceHandler(cfg config.Server, srv bounceService) *BounceHandler {
+	return &BounceHandler{cfg, srv}
+}
+
+// Ingest handles the HTTP request with a raw RFC 3464 delivery status notification in the body.
+func (h *BounceHandler) Ingest(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	result, err := h.bounceService.Process(ctx, limitBody(w, r, h.cfg))
+	if err != nil {
+		renderError(w, errorStatus(err), err)
+		return
+	}
+
+	renderJSON(w, http.StatusOK, result)
+}
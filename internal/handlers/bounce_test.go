@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grishkovelli/betera-mailqusrv/config"
+	"github.com/grishkovelli/betera-mailqusrv/internal/bounce"
+	"github.com/grishkovelli/betera-mailqusrv/internal/entities"
+)
+
+type MockBounceService struct {
+	mock.Mock
+}
+
+var _ bounceService = (*MockBounceService)(nil)
+
+func (m *MockBounceService) Process(ctx context.Context, raw io.Reader) (entities.BounceResult, error) {
+	args := m.Called(ctx, raw)
+	return args.Get(0).(entities.BounceResult), args.Error(1)
+}
+
+func TestBounceHandler_Ingest(t *testing.T) {
+	tests := []struct {
+		name           string
+		mockResult     entities.BounceResult
+		mockError      error
+		expectedStatus int
+	}{
+		{
+			name: "hard bounce",
+			mockResult: entities.BounceResult{
+				EmailID:    42,
+				Bounced:    true,
+				Suppressed: []string{"user@example.com"},
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "not a delivery status notification",
+			mockError:      bounce.ErrNotDSN,
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:           "unknown email",
+			mockError:      entities.ErrEmailNotFound,
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "oversized message",
+			mockError:      fmt.Errorf("read message: %w", &http.MaxBytesError{Limit: 1024}),
+			expectedStatus: http.StatusRequestEntityTooLarge,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockBounceService)
+			handler := NewBounceHandler(config.Server{}, mockService)
+
+			req := httptest.NewRequest(http.MethodPost, "/bounces", strings.NewReader("raw message"))
+			w := httptest.NewRecorder()
+
+			mockService.On("Process", mock.Anything, mock.Anything).Return(tt.mockResult, tt.mockError)
+
+			handler.Ingest(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				var response entities.BounceResult
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+				assert.Equal(t, tt.mockResult, response)
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
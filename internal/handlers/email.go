@@ -2,9 +2,7 @@ package handlers
 
 import (
 	"context"
-	"fmt"
 	"net/http"
-	"slices"
 	"strconv"
 
 	"github.com/grishkovelli/betera-mailqusrv/config"
@@ -13,8 +11,30 @@ import (
 
 // emailService defines the interface for email-related operations.
 type emailService interface {
-	Create(ctx context.Context, p entities.CreateEmail) error
-	GetByStatus(ctx context.Context, status string, limit, cursor int) ([]entities.Email, error)
+	Create(ctx context.Context, p entities.CreateEmail) (entities.Email, error)
+	Get(ctx context.Context, id int) (entities.Email, error)
+	GetByStatus(ctx context.Context, status entities.Status, limit, cursor int) ([]entities.Email, error)
+	GetByFilter(ctx context.Context, f entities.EmailFilter, limit, cursor int) ([]entities.Email, error)
+	Cancel(ctx context.Context, id int) (entities.Email, error)
+	Retry(ctx context.Context, id int) (entities.Email, error)
+	BulkCancel(ctx context.Context, f entities.EmailFilter) (int64, error)
+	BulkRetry(ctx context.Context, f entities.EmailFilter) (int64, error)
+}
+
+// bulkResult is the response body of bulk operations.
+type bulkResult struct {
+	Updated int64 `json:"updated"` // Number of affected emails
+}
+
+// batchResult is the outcome of queueing one email of a batch, either the email or the error.
+type batchResult struct {
+	Email *entities.Email `json:"email,omitempty"` // Queued email
+	Error *errorResponse  `json:"error,omitempty"` // Reason the email was not queued
+}
+
+// batchResponse is the response body of batch sends, results are in the order of the request.
+type batchResponse struct {
+	Results []batchResult `json:"results"`
 }
 
 // EmailHandler handles HTTP requests related to email operations.
@@ -28,55 +48,183 @@ func NewEmailHandler(cfg config.Server, srv emailService) *EmailHandler {
 	return &EmailHandler{cfg, srv}
 }
 
-// Send handles the HTTP request to create and queue a new email.
+// Send handles the HTTP request to create and queue a new email. Requests repeated with the same
+// Idempotency-Key header return the email queued by the first one.
 func (h *EmailHandler) Send(w http.ResponseWriter, r *http.Request) {
 	params := entities.CreateEmail{}
 
-	if err := validateParams(r, &params); err != nil {
+	if err := validateParams(w, r, h.cfg, &params); err != nil {
+		renderError(w, errorStatus(err), err)
+		return
+	}
+
+	key, err := idempotencyKey(r)
+	if err != nil {
+		renderError(w, errorStatus(err), err)
+		return
+	}
+	params.IdempotencyKey = key
+
+	ctx := r.Context()
+	email, err := h.emailService.Create(ctx, params)
+	if err != nil {
+		renderError(w, errorStatus(err), err)
+		return
+	}
+
+	renderJSON(w, http.StatusAccepted, email)
+}
+
+// SendBatch handles the HTTP request to queue up to entities.MaxBatchSize emails. The whole batch
+// is validated up front, emails that cannot be queued are reported per item and do not affect the others.
+// Items of requests with an Idempotency-Key header use the key suffixed with their index.
+func (h *EmailHandler) SendBatch(w http.ResponseWriter, r *http.Request) {
+	params := entities.CreateEmails{}
+
+	if err := validateParams(w, r, h.cfg, &params); err != nil {
+		renderError(w, errorStatus(err), err)
+		return
+	}
+
+	key, err := idempotencyKey(r)
+	if err != nil {
+		renderError(w, errorStatus(err), err)
+		return
+	}
+
+	ctx := r.Context()
+	results := make([]batchResult, len(params.Emails))
+	for i, p := range params.Emails {
+		if key != "" {
+			p.IdempotencyKey = key + ":" + strconv.Itoa(i)
+		}
+
+		email, err := h.emailService.Create(ctx, p)
+		if err != nil {
+			resp := newErrorResponse(errorStatus(err), err)
+			results[i].Error = &resp
+			continue
+		}
+		results[i].Email = &email
+	}
+
+	renderJSON(w, http.StatusAccepted, batchResponse{Results: results})
+}
+
+// Get handles the HTTP request to retrieve a single email.
+func (h *EmailHandler) Get(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
 		renderError(w, http.StatusBadRequest, err)
 		return
 	}
 
-	ctx := context.Background()
-	if err := h.emailService.Create(ctx, params); err != nil {
-		renderError(w, http.StatusInternalServerError, err)
+	ctx := r.Context()
+	email, err := h.emailService.Get(ctx, id)
+	if err != nil {
+		renderError(w, errorStatus(err), err)
 		return
 	}
 
-	w.WriteHeader(http.StatusAccepted)
+	renderJSON(w, http.StatusOK, email)
 }
 
-// List handles the HTTP request to retrieve emails by their status.
+// List handles the HTTP request to retrieve emails by their status. With the to_address
+// query parameter all emails to the recipient are returned, optionally filtered by status.
 func (h *EmailHandler) List(w http.ResponseWriter, r *http.Request) {
-	var cursor int
-	status := r.URL.Query().Get("status")
+	cursor, err := cursorParam(r)
+	if err != nil {
+		renderError(w, http.StatusBadRequest, err)
+		return
+	}
 
-	if c := r.URL.Query().Get("cursor"); c != "" {
-		v, err := strconv.Atoi(c)
-		if err != nil {
+	query := r.URL.Query()
+	to := query.Get("to_address")
+
+	var status entities.Status
+	if to == "" || query.Get("status") != "" {
+		if status, err = entities.ParseStatus(query.Get("status")); err != nil {
 			renderError(w, http.StatusBadRequest, err)
 			return
 		}
+	}
 
-		cursor = v
+	ctx := r.Context()
+	var emails []entities.Email
+	if to != "" {
+		emails, err = h.emailService.GetByFilter(ctx, entities.EmailFilter{To: to, Status: status}, h.cfg.PageSize, cursor)
+	} else {
+		emails, err = h.emailService.GetByStatus(ctx, status, h.cfg.PageSize, cursor)
 	}
+	if err != nil {
+		renderError(w, errorStatus(err), err)
+		return
+	}
+
+	renderJSON(w, http.StatusOK, emails)
+}
+
+// Cancel handles the HTTP request to cancel a pending or failed email.
+func (h *EmailHandler) Cancel(w http.ResponseWriter, r *http.Request) {
+	h.changeStatus(w, r, h.emailService.Cancel)
+}
 
-	if !validateEmailStatus(status) {
-		renderError(w, http.StatusBadRequest, fmt.Errorf("invalid status: %s", status))
+// Retry handles the HTTP request to requeue a failed or dead email.
+func (h *EmailHandler) Retry(w http.ResponseWriter, r *http.Request) {
+	h.changeStatus(w, r, h.emailService.Retry)
+}
+
+// BulkCancel handles the HTTP request to cancel all emails matching a filter.
+func (h *EmailHandler) BulkCancel(w http.ResponseWriter, r *http.Request) {
+	h.bulkChangeStatus(w, r, h.emailService.BulkCancel)
+}
+
+// BulkRetry handles the HTTP request to requeue all emails matching a filter.
+func (h *EmailHandler) BulkRetry(w http.ResponseWriter, r *http.Request) {
+	h.bulkChangeStatus(w, r, h.emailService.BulkRetry)
+}
+
+// changeStatus applies a status change to the email identified by the id path value.
+func (h *EmailHandler) changeStatus(
+	w http.ResponseWriter,
+	r *http.Request,
+	fn func(ctx context.Context, id int) (entities.Email, error),
+) {
+	id, err := pathID(r)
+	if err != nil {
+		renderError(w, http.StatusBadRequest, err)
 		return
 	}
 
-	ctx := context.Background()
-	emails, err := h.emailService.GetByStatus(ctx, status, h.cfg.PageSize, cursor)
+	ctx := r.Context()
+	email, err := fn(ctx, id)
 	if err != nil {
-		renderError(w, http.StatusInternalServerError, err)
+		renderError(w, errorStatus(err), err)
 		return
 	}
 
-	renderJSON(w, http.StatusOK, emails)
+	renderJSON(w, http.StatusOK, email)
 }
 
-// validateEmailStatus checks if the provided status is valid.
-func validateEmailStatus(status string) bool {
-	return slices.Contains([]string{entities.Pending, entities.Sent, entities.Failed}, status)
+// bulkChangeStatus applies a status change to all emails matching the filter from the request body.
+func (h *EmailHandler) bulkChangeStatus(
+	w http.ResponseWriter,
+	r *http.Request,
+	fn func(ctx context.Context, f entities.EmailFilter) (int64, error),
+) {
+	params := entities.EmailFilter{}
+
+	if err := validateParams(w, r, h.cfg, &params); err != nil {
+		renderError(w, errorStatus(err), err)
+		return
+	}
+
+	ctx := r.Context()
+	n, err := fn(ctx, params)
+	if err != nil {
+		renderError(w, errorStatus(err), err)
+		return
+	}
+
+	renderJSON(w, http.StatusOK, bulkResult{Updated: n})
 }
@@ -15,6 +15,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/grishkovelli/betera-mailqusrv/config"
+	"github.com/grishkovelli/betera-mailqusrv/internal/address"
 	"github.com/grishkovelli/betera-mailqusrv/internal/entities"
 )
 
@@ -24,20 +25,54 @@ type MockEmailService struct {
 
 var _ emailService = (*MockEmailService)(nil)
 
-func (m *MockEmailService) Create(ctx context.Context, p entities.CreateEmail) error {
+func (m *MockEmailService) Create(ctx context.Context, p entities.CreateEmail) (entities.Email, error) {
 	args := m.Called(ctx, p)
-	return args.Error(0)
+	return args.Get(0).(entities.Email), args.Error(1)
+}
+
+func (m *MockEmailService) Get(ctx context.Context, id int) (entities.Email, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(entities.Email), args.Error(1)
 }
 
 func (m *MockEmailService) GetByStatus(
 	ctx context.Context,
-	status string,
+	status entities.Status,
 	limit, cursor int,
 ) ([]entities.Email, error) {
 	args := m.Called(ctx, status, limit, cursor)
 	return args.Get(0).([]entities.Email), args.Error(1)
 }
 
+func (m *MockEmailService) GetByFilter(
+	ctx context.Context,
+	f entities.EmailFilter,
+	limit, cursor int,
+) ([]entities.Email, error) {
+	args := m.Called(ctx, f, limit, cursor)
+	return args.Get(0).([]entities.Email), args.Error(1)
+}
+
+func (m *MockEmailService) Cancel(ctx context.Context, id int) (entities.Email, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(entities.Email), args.Error(1)
+}
+
+func (m *MockEmailService) Retry(ctx context.Context, id int) (entities.Email, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(entities.Email), args.Error(1)
+}
+
+func (m *MockEmailService) BulkCancel(ctx context.Context, f entities.EmailFilter) (int64, error) {
+	args := m.Called(ctx, f)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockEmailService) BulkRetry(ctx context.Context, f entities.EmailFilter) (int64, error) {
+	args := m.Called(ctx, f)
+	return args.Get(0).(int64), args.Error(1)
+}
+
 func TestEmailHandler_Send(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -95,6 +130,71 @@ func TestEmailHandler_Send(t *testing.T) {
 			mockError:      errors.New("service error"),
 			expectedStatus: http.StatusInternalServerError,
 		},
+		{
+			name: "explicit provider",
+			requestBody: entities.CreateEmail{
+				To:       "test@example.com",
+				Subject:  "Test Subject",
+				Body:     "Test Body",
+				Provider: "sendgrid",
+			},
+			mockError:      nil,
+			expectedStatus: http.StatusAccepted,
+		},
+		{
+			name: "unknown provider",
+			requestBody: entities.CreateEmail{
+				To:       "test@example.com",
+				Subject:  "Test Subject",
+				Body:     "Test Body",
+				Provider: "pigeon",
+			},
+			mockError:      nil,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "suppressed recipient",
+			requestBody: entities.CreateEmail{
+				To:      "bounced@example.com",
+				Subject: "Test Subject",
+				Body:    "Test Body",
+			},
+			mockError:      entities.ErrRecipientSuppressed,
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name: "verified from address",
+			requestBody: entities.CreateEmail{
+				To:      "test@example.com",
+				Subject: "Test Subject",
+				Body:    "Test Body",
+				From:    "news@mailqu.local",
+			},
+			mockError:      nil,
+			expectedStatus: http.StatusAccepted,
+		},
+		{
+			name: "unverified from address",
+			requestBody: entities.CreateEmail{
+				To:      "test@example.com",
+				Subject: "Test Subject",
+				Body:    "Test Body",
+				From:    "news@unverified.example",
+			},
+			mockError:      entities.ErrUnverifiedFrom,
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name: "invalid from address",
+			requestBody: entities.CreateEmail{
+				To:      "test@example.com",
+				Subject: "Test Subject",
+				Body:    "Test Body",
+				From:    "news",
+			},
+			mockError:      nil,
+			expectedStatus: http.StatusBadRequest,
+		},
 	}
 
 	for _, tt := range tests {
@@ -104,12 +204,13 @@ func TestEmailHandler_Send(t *testing.T) {
 
 			body, _ := json.Marshal(tt.requestBody)
 			req := httptest.NewRequest(http.MethodPost, "/send-email", bytes.NewBuffer(body))
+			req.Header.Set("Content-Type", "application/json")
 			w := httptest.NewRecorder()
 
 			if tt.mockError == nil && tt.expectedStatus == http.StatusAccepted {
-				mockService.On("Create", mock.Anything, tt.requestBody).Return(nil)
+				mockService.On("Create", mock.Anything, tt.requestBody).Return(entities.Email{ID: 1}, nil)
 			} else if tt.mockError != nil {
-				mockService.On("Create", mock.Anything, tt.requestBody).Return(tt.mockError)
+				mockService.On("Create", mock.Anything, tt.requestBody).Return(entities.Email{}, tt.mockError)
 			}
 
 			handler.Send(w, req)
@@ -120,10 +221,121 @@ func TestEmailHandler_Send(t *testing.T) {
 	}
 }
 
+func TestEmailHandler_SendUndeliverable(t *testing.T) {
+	mockService := new(MockEmailService)
+	handler := NewEmailHandler(config.Server{}, mockService)
+
+	params := entities.CreateEmail{To: "user@mailinator.com", Subject: "Test Subject", Body: "Test Body"}
+	mockService.On("Create", mock.Anything, params).Return(entities.Email{}, &address.Error{
+		Address: "user@mailinator.com",
+		Check:   address.CheckDisposable,
+		Reason:  "mailinator.com is a disposable email domain",
+	})
+
+	body, _ := json.Marshal(params)
+	req := httptest.NewRequest(http.MethodPost, "/send-email", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	handler.Send(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	var got errorResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&got))
+	assert.Equal(t, "undeliverable_address", got.Code)
+	assert.Equal(t, []fieldError{{
+		Field:   "to_address",
+		Rule:    address.CheckDisposable,
+		Message: "mailinator.com is a disposable email domain",
+	}}, got.Fields)
+	mockService.AssertExpectations(t)
+}
+
+func TestEmailHandler_SendBatch(t *testing.T) {
+	mockService := new(MockEmailService)
+	handler := NewEmailHandler(config.Server{}, mockService)
+
+	first := entities.CreateEmail{To: "first@example.com", Subject: "Test Subject", Body: "Test Body"}
+	second := entities.CreateEmail{To: "bounced@example.com", Subject: "Test Subject", Body: "Test Body"}
+	mockService.On("Create", mock.Anything, first).Return(entities.Email{ID: 7, To: first.To}, nil)
+	mockService.On("Create", mock.Anything, second).Return(entities.Email{}, entities.ErrRecipientSuppressed)
+
+	body, _ := json.Marshal(entities.CreateEmails{Emails: []entities.CreateEmail{first, second}})
+	req := httptest.NewRequest(http.MethodPost, "/send-email/batch", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	handler.SendBatch(w, req)
+
+	assert.Equal(t, http.StatusAccepted, w.Code)
+
+	var got batchResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&got))
+	require.Len(t, got.Results, 2)
+	require.NotNil(t, got.Results[0].Email)
+	assert.Equal(t, 7, got.Results[0].Email.ID)
+	assert.Nil(t, got.Results[0].Error)
+	assert.Nil(t, got.Results[1].Email)
+	require.NotNil(t, got.Results[1].Error)
+	assert.Equal(t, "unprocessable", got.Results[1].Error.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestEmailHandler_SendBatchTooLarge(t *testing.T) {
+	mockService := new(MockEmailService)
+	handler := NewEmailHandler(config.Server{}, mockService)
+
+	emails := make([]entities.CreateEmail, entities.MaxBatchSize+1)
+	for i := range emails {
+		emails[i] = entities.CreateEmail{To: "test@example.com", Subject: "Test Subject", Body: "Test Body"}
+	}
+	body, _ := json.Marshal(entities.CreateEmails{Emails: emails})
+	req := httptest.NewRequest(http.MethodPost, "/send-email/batch", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	handler.SendBatch(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestEmailHandler_RequestContext(t *testing.T) {
+	tests := []struct {
+		name           string
+		err            error
+		expectedStatus int
+	}{
+		{name: "client disconnected", err: context.Canceled, expectedStatus: 499},
+		{name: "request timed out", err: context.DeadlineExceeded, expectedStatus: http.StatusGatewayTimeout},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockEmailService)
+			handler := NewEmailHandler(config.Server{PageSize: 50}, mockService)
+
+			ctx, cancel := context.WithCancel(t.Context())
+			defer cancel()
+			req := httptest.NewRequest(http.MethodGet, "/emails?status=pending", nil).WithContext(ctx)
+			w := httptest.NewRecorder()
+
+			// the service must receive the request context to abort queries of cancelled requests
+			mockService.On("GetByStatus", ctx, entities.Pending, 50, 0).Return([]entities.Email(nil), tt.err)
+
+			handler.List(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
 func TestEmailHandler_List(t *testing.T) {
 	tests := []struct {
 		name           string
-		status         string
+		status         entities.Status
 		cursor         string
 		mockEmails     []entities.Email
 		mockError      error
@@ -182,7 +394,7 @@ func TestEmailHandler_List(t *testing.T) {
 			mockService := new(MockEmailService)
 			handler := NewEmailHandler(config.Server{PageSize: 10}, mockService)
 
-			url := "/emails?status=" + tt.status
+			url := "/emails?status=" + string(tt.status)
 			if tt.cursor != "" {
 				url += "&cursor=" + tt.cursor
 			}
@@ -212,3 +424,301 @@ func TestEmailHandler_List(t *testing.T) {
 		})
 	}
 }
+
+func TestEmailHandler_ListByRecipient(t *testing.T) {
+	tests := []struct {
+		name           string
+		query          string
+		filter         entities.EmailFilter
+		expectedStatus int
+	}{
+		{
+			name:           "all statuses",
+			query:          "to_address=test@example.com",
+			filter:         entities.EmailFilter{To: "test@example.com"},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "with status",
+			query:          "to_address=test@example.com&status=failed&cursor=3",
+			filter:         entities.EmailFilter{To: "test@example.com", Status: entities.Failed},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "invalid status",
+			query:          "to_address=test@example.com&status=invalid",
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockEmailService)
+			handler := NewEmailHandler(config.Server{PageSize: 10}, mockService)
+			emails := []entities.Email{{ID: 4, To: "test@example.com", Status: entities.Failed}}
+			if tt.expectedStatus == http.StatusOK {
+				mockService.On("GetByFilter", mock.Anything, tt.filter, 10, mock.Anything).Return(emails, nil)
+			}
+
+			w := httptest.NewRecorder()
+			handler.List(w, httptest.NewRequest(http.MethodGet, "/emails?"+tt.query, nil))
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestEmailHandler_Cancel(t *testing.T) {
+	tests := []struct {
+		name           string
+		id             string
+		mockEmail      entities.Email
+		mockError      error
+		expectedStatus int
+	}{
+		{
+			name:           "successful cancel",
+			id:             "1",
+			mockEmail:      entities.Email{ID: 1, To: "test@example.com", Status: entities.Cancelled},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "invalid id",
+			id:             "invalid",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "email not found",
+			id:             "2",
+			mockError:      entities.ErrEmailNotFound,
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "sent email cannot be cancelled",
+			id:             "3",
+			mockError:      entities.ErrInvalidTransition,
+			expectedStatus: http.StatusConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockEmailService)
+			handler := NewEmailHandler(config.Server{}, mockService)
+
+			req := httptest.NewRequest(http.MethodPost, "/emails/"+tt.id+"/cancel", nil)
+			req.SetPathValue("id", tt.id)
+			w := httptest.NewRecorder()
+
+			if id, err := strconv.Atoi(tt.id); err == nil {
+				mockService.On("Cancel", mock.Anything, id).Return(tt.mockEmail, tt.mockError)
+			}
+
+			handler.Cancel(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				var response entities.Email
+				err := json.NewDecoder(w.Body).Decode(&response)
+				require.NoError(t, err)
+				assert.Equal(t, tt.mockEmail, response)
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestEmailHandler_Retry(t *testing.T) {
+	tests := []struct {
+		name           string
+		mockError      error
+		expectedStatus int
+	}{
+		{
+			name:           "successful retry",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "sent email cannot be retried",
+			mockError:      entities.ErrInvalidTransition,
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:           "processing email cannot be retried",
+			mockError:      &entities.TransitionError{ID: 1, From: entities.Processing, To: entities.Pending},
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:           "service error",
+			mockError:      errors.New("service error"),
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockEmailService)
+			handler := NewEmailHandler(config.Server{}, mockService)
+
+			req := httptest.NewRequest(http.MethodPost, "/emails/1/retry", nil)
+			req.SetPathValue("id", "1")
+			w := httptest.NewRecorder()
+
+			mockService.On("Retry", mock.Anything, 1).
+				Return(entities.Email{ID: 1, Status: entities.Pending}, tt.mockError)
+
+			handler.Retry(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestEmailHandler_BulkCancel(t *testing.T) {
+	tests := []struct {
+		name           string
+		requestBody    string
+		filter         entities.EmailFilter
+		mockUpdated    int64
+		mockError      error
+		expectedStatus int
+	}{
+		{
+			name:           "cancel by status",
+			requestBody:    `{"status":"pending"}`,
+			filter:         entities.EmailFilter{Status: entities.Pending},
+			mockUpdated:    3,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "cancel by ids",
+			requestBody:    `{"ids":[1,2]}`,
+			filter:         entities.EmailFilter{IDs: []int{1, 2}},
+			mockUpdated:    2,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "empty filter",
+			requestBody:    `{}`,
+			filter:         entities.EmailFilter{},
+			mockError:      entities.ErrEmptyFilter,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "sent emails cannot be cancelled",
+			requestBody:    `{"status":"sent"}`,
+			filter:         entities.EmailFilter{Status: entities.Sent},
+			mockError:      &entities.TransitionError{From: entities.Sent, To: entities.Cancelled},
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:           "invalid status",
+			requestBody:    `{"status":"unknown"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid address",
+			requestBody:    `{"to_address":"invalid-email"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockEmailService)
+			handler := NewEmailHandler(config.Server{}, mockService)
+
+			req := httptest.NewRequest(http.MethodPost, "/emails/cancel", bytes.NewBufferString(tt.requestBody))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			if tt.mockUpdated > 0 || tt.mockError != nil {
+				mockService.On("BulkCancel", mock.Anything, tt.filter).Return(tt.mockUpdated, tt.mockError)
+			}
+
+			handler.BulkCancel(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				var response bulkResult
+				err := json.NewDecoder(w.Body).Decode(&response)
+				require.NoError(t, err)
+				assert.Equal(t, tt.mockUpdated, response.Updated)
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestEmailHandler_BulkRetry(t *testing.T) {
+	tests := []struct {
+		name           string
+		requestBody    string
+		filter         entities.EmailFilter
+		mockUpdated    int64
+		mockError      error
+		expectedStatus int
+	}{
+		{
+			name:           "retry by status",
+			requestBody:    `{"status":"failed"}`,
+			filter:         entities.EmailFilter{Status: entities.Failed},
+			mockUpdated:    4,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "retry by recipient",
+			requestBody:    `{"to_address":"test@example.com"}`,
+			filter:         entities.EmailFilter{To: "test@example.com"},
+			mockUpdated:    1,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "empty filter",
+			requestBody:    `{}`,
+			filter:         entities.EmailFilter{},
+			mockError:      entities.ErrEmptyFilter,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "sent emails cannot be retried",
+			requestBody:    `{"status":"sent"}`,
+			filter:         entities.EmailFilter{Status: entities.Sent},
+			mockError:      &entities.TransitionError{From: entities.Sent, To: entities.Pending},
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:           "invalid status",
+			requestBody:    `{"status":"unknown"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockEmailService)
+			handler := NewEmailHandler(config.Server{}, mockService)
+
+			req := httptest.NewRequest(http.MethodPost, "/emails/retry", bytes.NewBufferString(tt.requestBody))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			if tt.mockUpdated > 0 || tt.mockError != nil {
+				mockService.On("BulkRetry", mock.Anything, tt.filter).Return(tt.mockUpdated, tt.mockError)
+			}
+
+			handler.BulkRetry(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				var response bulkResult
+				err := json.NewDecoder(w.Body).Decode(&response)
+				require.NoError(t, err)
+				assert.Equal(t, tt.mockUpdated, response.Updated)
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+
+	"github.com/grishkovelli/betera-mailqusrv/internal/address"
+)
+
+// Error codes of responses that are not derived from the HTTP status.
+const (
+	codeInvalidJSON      = "invalid_json"
+	codeValidationFailed = "validation_failed"
+	codeUndeliverable    = "undeliverable_address"
+)
+
+// errorResponse is the body of all error responses.
+type errorResponse struct {
+	Code    string       `json:"code"`             // Stable machine readable error code
+	Message string       `json:"message"`          // Human readable description
+	Fields  []fieldError `json:"fields,omitempty"` // Invalid request fields
+}
+
+// fieldError describes a request field that failed validation.
+type fieldError struct {
+	Field   string `json:"field"`           // JSON path of the field, e.g. to_address or ids[0]
+	Rule    string `json:"rule"`            // Failed rule, e.g. required, max or type
+	Param   string `json:"param,omitempty"` // Constraint of the rule, e.g. 64 for max=64
+	Message string `json:"message"`         // Human readable description
+}
+
+// renderError renders err in the errorResponse format. Validation errors, decode errors
+// and rejected recipient addresses are reported per field.
+func renderError(w http.ResponseWriter, code int, err error) {
+	renderJSON(w, code, newErrorResponse(code, err))
+}
+
+// RenderError renders err in the errorResponse format for middleware that fails a request
+// before or instead of its handler.
+func RenderError(w http.ResponseWriter, code int, err error) {
+	renderError(w, code, err)
+}
+
+func newErrorResponse(code int, err error) errorResponse {
+	resp := errorResponse{Code: statusCode(code), Message: err.Error()}
+
+	var (
+		validationErrs validator.ValidationErrors
+		typeErr        *json.UnmarshalTypeError
+		addrErr        *address.Error
+	)
+	switch {
+	case errors.As(err, &validationErrs):
+		resp.Code = codeValidationFailed
+		resp.Message = "request validation failed"
+		for _, fe := range validationErrs {
+			resp.Fields = append(resp.Fields, newFieldError(fe))
+		}
+	case errors.As(err, &typeErr):
+		resp.Code = codeInvalidJSON
+		resp.Fields = []fieldError{{
+			Field:   typeErr.Field,
+			Rule:    "type",
+			Param:   typeErr.Type.String(),
+			Message: fmt.Sprintf("must be of type %s, got %s", typeErr.Type, typeErr.Value),
+		}}
+	case errors.Is(err, errInvalidJSON):
+		resp.Code = codeInvalidJSON
+		// encoding/json reports unknown fields of strict decoding without a dedicated error type
+		if _, field, ok := strings.Cut(err.Error(), `json: unknown field "`); ok {
+			field = strings.TrimSuffix(field, `"`)
+			resp.Fields = []fieldError{{Field: field, Rule: "unknown", Message: "is not allowed"}}
+		}
+	case errors.As(err, &addrErr):
+		// the recipient is the only address checked for deliverability
+		resp.Code = codeUndeliverable
+		resp.Fields = []fieldError{{Field: "to_address", Rule: addrErr.Check, Message: addrErr.Reason}}
+	}
+
+	return resp
+}
+
+// newFieldError converts a validator error, the field path is reported without the struct name.
+func newFieldError(fe validator.FieldError) fieldError {
+	field := fe.Field()
+	if _, path, ok := strings.Cut(fe.Namespace(), "."); ok {
+		field = path
+	}
+
+	return fieldError{Field: field, Rule: fe.Tag(), Param: fe.Param(), Message: ruleMessage(fe)}
+}
+
+// ruleMessage describes a failed validation rule.
+func ruleMessage(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "email":
+		return "must be a valid email address"
+	case "email|fqdn":
+		return "must be a valid email address or domain"
+	case "oneof":
+		return "must be one of: " + strings.ReplaceAll(fe.Param(), " ", ", ")
+	case "min":
+		return limitMessage("at least", fe)
+	case "max":
+		return limitMessage("at most", fe)
+	case "gt":
+		return "must be greater than " + fe.Param()
+	default:
+		return "must satisfy the " + fe.Tag() + " rule"
+	}
+}
+
+// limitMessage describes a failed min or max rule. Limits of strings count characters,
+// limits of slices and maps count items.
+func limitMessage(bound string, fe validator.FieldError) string {
+	switch fe.Kind() { //nolint:exhaustive // other kinds are limited by their value
+	case reflect.String:
+		return "must be " + bound + " " + fe.Param() + " characters long"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return "must have " + bound + " " + fe.Param() + " items"
+	default:
+		return "must be " + bound + " " + fe.Param()
+	}
+}
+
+// statusCode returns the generic error code of an HTTP status.
+func statusCode(status int) string {
+	switch status {
+	case http.StatusBadRequest:
+		return "bad_request"
+	case http.StatusUnauthorized:
+		return "unauthorized"
+	case http.StatusForbidden:
+		return "forbidden"
+	case http.StatusNotFound:
+		return "not_found"
+	case http.StatusConflict:
+		return "conflict"
+	case http.StatusRequestEntityTooLarge:
+		return "request_too_large"
+	case http.StatusUnsupportedMediaType:
+		return "unsupported_media_type"
+	case http.StatusUnprocessableEntity:
+		return "unprocessable"
+	case http.StatusGatewayTimeout:
+		return "timeout"
+	case statusClientClosedRequest:
+		return "client_closed_request"
+	default:
+		return "internal"
+	}
+}
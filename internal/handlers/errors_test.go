@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grishkovelli/betera-mailqusrv/config"
+	"github.com/grishkovelli/betera-mailqusrv/internal/entities"
+)
+
+func TestRenderError_Params(t *testing.T) {
+	batch := make([]entities.CreateEmail, entities.MaxBatchSize+1)
+	for i := range batch {
+		batch[i] = entities.CreateEmail{To: "test@example.com", Subject: "Test Subject", Body: "Test Body"}
+	}
+	oversized, err := json.Marshal(entities.CreateEmails{Emails: batch})
+	require.NoError(t, err)
+	longCategory := `{"to_address": "test@example.com", "subject": "Hi", "body": "Hi", "category": "` +
+		strings.Repeat("a", 65) + `"}`
+
+	tests := []struct {
+		name       string
+		body       string
+		params     any
+		wantCode   string
+		wantFields []fieldError
+	}{
+		{
+			name:     "malformed json",
+			body:     `{"to_address":`,
+			params:   &entities.CreateEmail{},
+			wantCode: codeInvalidJSON,
+		},
+		{
+			name:     "wrong type",
+			body:     `{"to_address": 42}`,
+			params:   &entities.CreateEmail{},
+			wantCode: codeInvalidJSON,
+			wantFields: []fieldError{{
+				Field:   "to_address",
+				Rule:    "type",
+				Param:   "string",
+				Message: "must be of type string, got number",
+			}},
+		},
+		{
+			name:     "invalid fields",
+			body:     `{"to_address": "invalid", "body": "Hi", "provider": "pigeon"}`,
+			params:   &entities.CreateEmail{},
+			wantCode: codeValidationFailed,
+			wantFields: []fieldError{
+				{Field: "to_address", Rule: "email", Message: "must be a valid email address"},
+				{Field: "subject", Rule: "required", Message: "is required"},
+				{
+					Field:   "provider",
+					Rule:    "oneof",
+					Param:   "smtp sendgrid mailgun ses",
+					Message: "must be one of: smtp, sendgrid, mailgun, ses",
+				},
+			},
+		},
+		{
+			name:     "too long",
+			body:     longCategory,
+			params:   &entities.CreateEmail{},
+			wantCode: codeValidationFailed,
+			wantFields: []fieldError{
+				{Field: "category", Rule: "max", Param: "64", Message: "must be at most 64 characters long"},
+			},
+		},
+		{
+			name:     "oversized batch",
+			body:     string(oversized),
+			params:   &entities.CreateEmails{},
+			wantCode: codeValidationFailed,
+			wantFields: []fieldError{
+				{Field: "emails", Rule: "max", Param: "100", Message: "must have at most 100 items"},
+			},
+		},
+		{
+			name:     "nested field",
+			body:     `{"ids": [1, 0]}`,
+			params:   &entities.EmailFilter{},
+			wantCode: codeValidationFailed,
+			wantFields: []fieldError{
+				{Field: "ids[1]", Rule: "gt", Param: "0", Message: "must be greater than 0"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			err := validateParams(w, req, config.Server{}, tt.params)
+			require.Error(t, err)
+			renderError(w, http.StatusBadRequest, err)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+
+			var got errorResponse
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&got))
+			assert.Equal(t, tt.wantCode, got.Code)
+			assert.NotEmpty(t, got.Message)
+			assert.Equal(t, tt.wantFields, got.Fields)
+		})
+	}
+}
+
+func TestRenderError_Status(t *testing.T) {
+	tests := []struct {
+		err      error
+		wantCode string
+	}{
+		{err: entities.ErrEmailNotFound, wantCode: "not_found"},
+		{err: entities.ErrIdentityExists, wantCode: "conflict"},
+		{err: entities.ErrIdempotencyConflict, wantCode: "conflict"},
+		{err: entities.ErrEmptyFilter, wantCode: "bad_request"},
+		{err: entities.ErrRecipientSuppressed, wantCode: "unprocessable"},
+		{err: fmt.Errorf("query: %w", context.DeadlineExceeded), wantCode: "timeout"},
+		{err: context.Canceled, wantCode: "client_closed_request"},
+		{err: errors.New("db error"), wantCode: "internal"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.wantCode, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			renderError(w, errorStatus(tt.err), tt.err)
+
+			var got errorResponse
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&got))
+			assert.Equal(t, errorResponse{Code: tt.wantCode, Message: tt.err.Error()}, got)
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"net/http"
+
+	"github.com/grishkovelli/betera-mailqusrv/internal/entities"
+	"github.com/grishkovelli/betera-mailqusrv/internal/tracking"
+)
+
+// eventService defines the interface for engagement tracking.
+type eventService interface {
+	Open(ctx context.Context, token, userAgent string) error
+	Click(ctx context.Context, token, userAgent string) (string, error)
+	Stats(ctx context.Context) (entities.Stats, error)
+}
+
+// EventHandler handles tracking links embedded into emails and engagement statistics.
+type EventHandler struct {
+	eventService eventService
+}
+
+// NewEventHandler creates a new instance of EventHandler.
+func NewEventHandler(srv eventService) *EventHandler {
+	return &EventHandler{srv}
+}
+
+// Open handles the HTTP request for the tracking pixel. The pixel is served even if the open
+// could not be recorded, only forged tokens and tokens of unknown emails are rejected.
+func (h *EventHandler) Open(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if err := h.eventService.Open(ctx, r.PathValue("token"), r.UserAgent()); err != nil {
+		if status := errorStatus(err); status != http.StatusInternalServerError {
+			renderError(w, status, err)
+			return
+		}
+		log.Printf("failed to record open: %v", err)
+	}
+
+	w.Header().Set("Content-Type", "image/gif")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write([]byte(tracking.Pixel)); err != nil {
+		log.Printf("failed to write response: %v", err)
+	}
+}
+
+// Click handles the HTTP request for a tracked link and redirects to the original URL.
+func (h *EventHandler) Click(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	target, err := h.eventService.Click(ctx, r.PathValue("token"), r.UserAgent())
+	if target == "" {
+		renderError(w, errorStatus(err), err)
+		return
+	}
+	if err != nil {
+		log.Printf("failed to record click: %v", err)
+	}
+
+	http.Redirect(w, r, target, http.StatusFound)
+}
+
+// Stats handles the HTTP request to retrieve email statistics.
+func (h *EventHandler) Stats(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	stats, err := h.eventService.Stats(ctx)
+	if err != nil {
+		renderError(w, errorStatus(err), err)
+		return
+	}
+
+	renderJSON(w, http.StatusOK, stats)
+}
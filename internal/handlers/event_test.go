@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grishkovelli/betera-mailqusrv/internal/entities"
+	"github.com/grishkovelli/betera-mailqusrv/internal/signing"
+	"github.com/grishkovelli/betera-mailqusrv/internal/tracking"
+)
+
+type MockEventService struct {
+	mock.Mock
+}
+
+var _ eventService = (*MockEventService)(nil)
+
+func (m *MockEventService) Open(ctx context.Context, token, userAgent string) error {
+	args := m.Called(ctx, token, userAgent)
+	return args.Error(0)
+}
+
+func (m *MockEventService) Click(ctx context.Context, token, userAgent string) (string, error) {
+	args := m.Called(ctx, token, userAgent)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockEventService) Stats(ctx context.Context) (entities.Stats, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(entities.Stats), args.Error(1)
+}
+
+func TestEventHandler_Open(t *testing.T) {
+	tests := []struct {
+		name           string
+		mockError      error
+		expectedStatus int
+		expectedType   string
+	}{
+		{
+			name:           "recorded",
+			expectedStatus: http.StatusOK,
+			expectedType:   "image/gif",
+		},
+		{
+			name:           "record error",
+			mockError:      errors.New("db error"),
+			expectedStatus: http.StatusOK,
+			expectedType:   "image/gif",
+		},
+		{
+			name:           "invalid token",
+			mockError:      signing.ErrInvalidToken,
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockEventService)
+			handler := NewEventHandler(mockService)
+
+			req := httptest.NewRequest(http.MethodGet, "/t/o/abc", nil)
+			req.SetPathValue("token", "abc")
+			req.Header.Set("User-Agent", "Mail/1.0")
+			w := httptest.NewRecorder()
+
+			mockService.On("Open", mock.Anything, "abc", "Mail/1.0").Return(tt.mockError)
+
+			handler.Open(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedType != "" {
+				assert.Equal(t, tt.expectedType, w.Header().Get("Content-Type"))
+				assert.Equal(t, tracking.Pixel, w.Body.String())
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestEventHandler_Click(t *testing.T) {
+	tests := []struct {
+		name           string
+		target         string
+		mockError      error
+		expectedStatus int
+	}{
+		{
+			name:           "recorded",
+			target:         "https://example.com/a",
+			expectedStatus: http.StatusFound,
+		},
+		{
+			name:           "record error",
+			target:         "https://example.com/a",
+			mockError:      errors.New("db error"),
+			expectedStatus: http.StatusFound,
+		},
+		{
+			name:           "invalid token",
+			mockError:      signing.ErrInvalidToken,
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockEventService)
+			handler := NewEventHandler(mockService)
+
+			req := httptest.NewRequest(http.MethodGet, "/t/c/abc", nil)
+			req.SetPathValue("token", "abc")
+			w := httptest.NewRecorder()
+
+			mockService.On("Click", mock.Anything, "abc", "").Return(tt.target, tt.mockError)
+
+			handler.Click(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.target != "" {
+				assert.Equal(t, tt.target, w.Header().Get("Location"))
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestEventHandler_Stats(t *testing.T) {
+	mockService := new(MockEventService)
+	handler := NewEventHandler(mockService)
+
+	stats := entities.Stats{
+		Statuses:     map[entities.Status]int64{entities.Sent: 10, entities.Failed: 1},
+		Opens:        7,
+		UniqueOpens:  5,
+		Clicks:       3,
+		UniqueClicks: 2,
+	}
+	mockService.On("Stats", mock.Anything).Return(stats, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/stats", nil)
+	w := httptest.NewRecorder()
+
+	handler.Stats(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response entities.Stats
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+	assert.Equal(t, stats, response)
+	mockService.AssertExpectations(t)
+}
@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/grishkovelli/betera-mailqusrv/config"
+	"github.com/grishkovelli/betera-mailqusrv/internal/entities"
+)
+
+// identityService defines the interface for sender identity operations.
+type identityService interface {
+	Create(ctx context.Context, p entities.CreateIdentity) (entities.Identity, error)
+	Get(ctx context.Context, id int) (entities.Identity, error)
+	List(ctx context.Context, limit, cursor int) ([]entities.Identity, error)
+	Verify(ctx context.Context, id int) (entities.Identity, error)
+	Delete(ctx context.Context, id int) error
+}
+
+// IdentityHandler handles HTTP requests related to sender identities.
+type IdentityHandler struct {
+	cfg             config.Server
+	identityService identityService
+}
+
+// NewIdentityHandler creates a new instance of IdentityHandler.
+func NewIdentityHandler(cfg config.Server, srv identityService) *IdentityHandler {
+	return &IdentityHandler{cfg, srv}
+}
+
+// Create handles the HTTP request to register a from address or domain.
+func (h *IdentityHandler) Create(w http.ResponseWriter, r *http.Request) {
+	params := entities.CreateIdentity{}
+
+	if err := validateParams(w, r, h.cfg, &params); err != nil {
+		renderError(w, errorStatus(err), err)
+		return
+	}
+
+	ctx := r.Context()
+	identity, err := h.identityService.Create(ctx, params)
+	if err != nil {
+		renderError(w, errorStatus(err), err)
+		return
+	}
+
+	renderJSON(w, http.StatusCreated, identity)
+}
+
+// Get handles the HTTP request to retrieve a single identity.
+func (h *IdentityHandler) Get(w http.ResponseWriter, r *http.Request) {
+	h.byID(w, r, h.identityService.Get)
+}
+
+// List handles the HTTP request to retrieve a page of identities.
+func (h *IdentityHandler) List(w http.ResponseWriter, r *http.Request) {
+	cursor, err := cursorParam(r)
+	if err != nil {
+		renderError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	ctx := r.Context()
+	list, err := h.identityService.List(ctx, h.cfg.PageSize, cursor)
+	if err != nil {
+		renderError(w, errorStatus(err), err)
+		return
+	}
+
+	renderJSON(w, http.StatusOK, list)
+}
+
+// Verify handles the HTTP request to mark an identity as verified.
+func (h *IdentityHandler) Verify(w http.ResponseWriter, r *http.Request) {
+	h.byID(w, r, h.identityService.Verify)
+}
+
+// Delete handles the HTTP request to remove an identity.
+func (h *IdentityHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		renderError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	ctx := r.Context()
+	if err = h.identityService.Delete(ctx, id); err != nil {
+		renderError(w, errorStatus(err), err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// byID applies fn to the identity referenced by the path and renders the result.
+func (h *IdentityHandler) byID(
+	w http.ResponseWriter,
+	r *http.Request,
+	fn func(ctx context.Context, id int) (entities.Identity, error),
+) {
+	id, err := pathID(r)
+	if err != nil {
+		renderError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	ctx := r.Context()
+	identity, err := fn(ctx, id)
+	if err != nil {
+		renderError(w, errorStatus(err), err)
+		return
+	}
+
+	renderJSON(w, http.StatusOK, identity)
+}
@@ -0,0 +1,195 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grishkovelli/betera-mailqusrv/config"
+	"github.com/grishkovelli/betera-mailqusrv/internal/entities"
+)
+
+type MockIdentityService struct {
+	mock.Mock
+}
+
+var _ identityService = (*MockIdentityService)(nil)
+
+func (m *MockIdentityService) Create(ctx context.Context, p entities.CreateIdentity) (entities.Identity, error) {
+	args := m.Called(ctx, p)
+	return args.Get(0).(entities.Identity), args.Error(1)
+}
+
+func (m *MockIdentityService) Get(ctx context.Context, id int) (entities.Identity, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(entities.Identity), args.Error(1)
+}
+
+func (m *MockIdentityService) List(ctx context.Context, limit, cursor int) ([]entities.Identity, error) {
+	args := m.Called(ctx, limit, cursor)
+	return args.Get(0).([]entities.Identity), args.Error(1)
+}
+
+func (m *MockIdentityService) Verify(ctx context.Context, id int) (entities.Identity, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(entities.Identity), args.Error(1)
+}
+
+func (m *MockIdentityService) Delete(ctx context.Context, id int) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func TestIdentityHandler_Create(t *testing.T) {
+	tests := []struct {
+		name           string
+		requestBody    string
+		params         entities.CreateIdentity
+		mockError      error
+		expectedStatus int
+	}{
+		{
+			name:        "from address",
+			requestBody: `{"address":"news@example.com","display_name":"News","reply_to":"support@example.com"}`,
+			params: entities.CreateIdentity{
+				Address:     "news@example.com",
+				DisplayName: "News",
+				ReplyTo:     "support@example.com",
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "domain",
+			requestBody:    `{"address":"example.com"}`,
+			params:         entities.CreateIdentity{Address: "example.com"},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "invalid address",
+			requestBody:    `{"address":"not an address"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid reply-to",
+			requestBody:    `{"address":"example.com","reply_to":"support"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "already exists",
+			requestBody:    `{"address":"example.com"}`,
+			params:         entities.CreateIdentity{Address: "example.com"},
+			mockError:      entities.ErrIdentityExists,
+			expectedStatus: http.StatusConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockIdentityService)
+			handler := NewIdentityHandler(config.Server{}, mockService)
+
+			req := httptest.NewRequest(http.MethodPost, "/identities", bytes.NewBufferString(tt.requestBody))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			if tt.params.Address != "" {
+				mockService.On("Create", mock.Anything, tt.params).
+					Return(entities.Identity{ID: 1, Address: tt.params.Address}, tt.mockError)
+			}
+
+			handler.Create(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestIdentityHandler_List(t *testing.T) {
+	mockService := new(MockIdentityService)
+	handler := NewIdentityHandler(config.Server{PageSize: 10}, mockService)
+
+	list := []entities.Identity{{ID: 3, Address: "example.com", Verified: true}}
+	mockService.On("List", mock.Anything, 10, 2).Return(list, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/identities?cursor=2", nil)
+	w := httptest.NewRecorder()
+
+	handler.List(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response []entities.Identity
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+	assert.Equal(t, list, response)
+	mockService.AssertExpectations(t)
+}
+
+func TestIdentityHandler_Verify(t *testing.T) {
+	tests := []struct {
+		name           string
+		id             string
+		mockError      error
+		expectedStatus int
+	}{
+		{
+			name:           "successful verification",
+			id:             "1",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "not found",
+			id:             "2",
+			mockError:      entities.ErrIdentityNotFound,
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "invalid id",
+			id:             "invalid",
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockIdentityService)
+			handler := NewIdentityHandler(config.Server{}, mockService)
+
+			req := httptest.NewRequest(http.MethodPost, "/identities/"+tt.id+"/verify", nil)
+			req.SetPathValue("id", tt.id)
+			w := httptest.NewRecorder()
+
+			if tt.expectedStatus != http.StatusBadRequest {
+				mockService.On("Verify", mock.Anything, mock.AnythingOfType("int")).
+					Return(entities.Identity{ID: 1, Address: "example.com", Verified: true}, tt.mockError)
+			}
+
+			handler.Verify(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestIdentityHandler_Delete(t *testing.T) {
+	mockService := new(MockIdentityService)
+	handler := NewIdentityHandler(config.Server{}, mockService)
+
+	mockService.On("Delete", mock.Anything, 4).Return(nil)
+
+	req := httptest.NewRequest(http.MethodDelete, "/identities/4", nil)
+	req.SetPathValue("id", "4")
+	w := httptest.NewRecorder()
+
+	handler.Delete(w, req)
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	mockService.AssertExpectations(t)
+}
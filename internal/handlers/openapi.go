@@ -0,0 +1,21 @@
+package handlers
+
+import (
+	_ "embed"
+	"log"
+	"net/http"
+)
+
+// openAPISpec is the OpenAPI 3 document of the v1 API.
+//
+//go:embed openapi.json
+var openAPISpec []byte
+
+// OpenAPI serves the OpenAPI 3 document of the v1 API.
+func OpenAPI(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(openAPISpec); err != nil {
+		log.Printf("failed to write response: %v", err)
+	}
+}
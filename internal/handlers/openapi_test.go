@@ -0,0 +1,256 @@
+package handlers
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	legacyrouter "github.com/getkin/kin-openapi/routers/legacy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grishkovelli/betera-mailqusrv/config"
+	"github.com/grishkovelli/betera-mailqusrv/internal/entities"
+)
+
+func newOpenAPIRouter(t *testing.T) routers.Router {
+	t.Helper()
+
+	loader := openapi3.NewLoader()
+	doc, err := loader.LoadFromData(openAPISpec)
+	require.NoError(t, err)
+	require.NoError(t, doc.Validate(loader.Context))
+
+	router, err := legacyrouter.NewRouter(doc)
+	require.NoError(t, err)
+
+	return router
+}
+
+func TestOpenAPI(t *testing.T) {
+	newOpenAPIRouter(t)
+
+	w := httptest.NewRecorder()
+	OpenAPI(w, httptest.NewRequest(http.MethodGet, "/v1/openapi.json", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	assert.JSONEq(t, string(openAPISpec), w.Body.String())
+}
+
+// TestOpenAPI_Responses checks that responses of the email handlers match the OpenAPI document.
+func TestOpenAPI_Responses(t *testing.T) {
+	email := entities.Email{
+		ID:        1,
+		To:        "test@example.com",
+		Subject:   "Test Subject",
+		Body:      "Test Body",
+		Status:    entities.Cancelled,
+		MessageID: "<1@mailqu.local>",
+		From:      "noreply@mailqu.local",
+		Category:  "news",
+		RequestID: "req-1",
+	}
+	sendBody := `{"to_address":"test@example.com","subject":"Test Subject","body":"Test Body"}`
+
+	tests := []struct {
+		name        string
+		method      string
+		target      string
+		body        string
+		contentType string
+		idemKey     string
+		setup       func(m *MockEmailService)
+		wantStatus  int
+	}{
+		{
+			name:   "send",
+			method: http.MethodPost, target: "/v1/send-email", body: sendBody,
+			setup:      func(m *MockEmailService) { m.On("Create", mock.Anything, mock.Anything).Return(email, nil) },
+			wantStatus: http.StatusAccepted,
+		},
+		{
+			name:   "send validation error",
+			method: http.MethodPost, target: "/v1/send-email", body: `{"to_address":"test","subject":"","body":1}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:   "send unsupported media type",
+			method: http.MethodPost, target: "/v1/send-email", body: sendBody, contentType: "text/plain",
+			wantStatus: http.StatusUnsupportedMediaType,
+		},
+		{
+			name:   "send suppressed recipient",
+			method: http.MethodPost, target: "/v1/send-email", body: sendBody,
+			setup: func(m *MockEmailService) {
+				m.On("Create", mock.Anything, mock.Anything).Return(entities.Email{}, entities.ErrRecipientSuppressed)
+			},
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:   "send with idempotency key",
+			method: http.MethodPost, target: "/v1/send-email", body: sendBody, idemKey: "order-1",
+			setup: func(m *MockEmailService) {
+				m.On("Create", mock.Anything, mock.MatchedBy(func(p entities.CreateEmail) bool {
+					return p.IdempotencyKey == "order-1"
+				})).Return(email, nil)
+			},
+			wantStatus: http.StatusAccepted,
+		},
+		{
+			name:   "send idempotency key too long",
+			method: http.MethodPost, target: "/v1/send-email", body: sendBody, idemKey: strings.Repeat("k", 201),
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:   "send batch",
+			method: http.MethodPost, target: "/v1/send-email/batch", idemKey: "order-2",
+			body: `{"emails":[` + sendBody + `,{"to_address":"bounced@example.com","subject":"s","body":"b"}]}`,
+			setup: func(m *MockEmailService) {
+				m.On("Create", mock.Anything, mock.MatchedBy(func(p entities.CreateEmail) bool {
+					return p.IdempotencyKey == "order-2:0"
+				})).Return(email, nil)
+				m.On("Create", mock.Anything, mock.MatchedBy(func(p entities.CreateEmail) bool {
+					return p.IdempotencyKey == "order-2:1"
+				})).Return(entities.Email{}, entities.ErrRecipientSuppressed)
+			},
+			wantStatus: http.StatusAccepted,
+		},
+		{
+			name:   "send batch validation error",
+			method: http.MethodPost, target: "/v1/send-email/batch", body: `{"emails":[{"to_address":"test"}]}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:   "get",
+			method: http.MethodGet, target: "/v1/emails/1",
+			setup:      func(m *MockEmailService) { m.On("Get", mock.Anything, 1).Return(email, nil) },
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:   "get not found",
+			method: http.MethodGet, target: "/v1/emails/2",
+			setup: func(m *MockEmailService) {
+				m.On("Get", mock.Anything, 2).Return(entities.Email{}, entities.ErrEmailNotFound)
+			},
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:   "list",
+			method: http.MethodGet, target: "/v1/emails?status=cancelled&cursor=0",
+			setup: func(m *MockEmailService) {
+				m.On("GetByStatus", mock.Anything, entities.Cancelled, 50, 0).Return([]entities.Email{email}, nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:   "list unknown status",
+			method: http.MethodGet, target: "/v1/emails?status=lost",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:   "cancel",
+			method: http.MethodPost, target: "/v1/emails/1/cancel",
+			setup:      func(m *MockEmailService) { m.On("Cancel", mock.Anything, 1).Return(email, nil) },
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:   "cancel not found",
+			method: http.MethodPost, target: "/v1/emails/2/cancel",
+			setup: func(m *MockEmailService) {
+				m.On("Cancel", mock.Anything, 2).Return(entities.Email{}, entities.ErrEmailNotFound)
+			},
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:   "retry invalid transition",
+			method: http.MethodPost, target: "/v1/emails/1/retry",
+			setup: func(m *MockEmailService) {
+				err := &entities.TransitionError{ID: 1, From: entities.Sent, To: entities.Pending}
+				m.On("Retry", mock.Anything, 1).Return(entities.Email{}, err)
+			},
+			wantStatus: http.StatusConflict,
+		},
+		{
+			name:   "bulk cancel",
+			method: http.MethodPost, target: "/v1/emails/cancel", body: `{"status":"pending"}`,
+			setup: func(m *MockEmailService) {
+				m.On("BulkCancel", mock.Anything, mock.Anything).Return(int64(3), nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:   "bulk retry empty filter",
+			method: http.MethodPost, target: "/v1/emails/retry", body: `{}`,
+			setup: func(m *MockEmailService) {
+				m.On("BulkRetry", mock.Anything, mock.Anything).Return(int64(0), entities.ErrEmptyFilter)
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:   "bulk retry too large",
+			method: http.MethodPost, target: "/v1/emails/retry", body: `{"ids":[` + strings.Repeat("1,", 600) + `1]}`,
+			wantStatus: http.StatusRequestEntityTooLarge,
+		},
+	}
+
+	router := newOpenAPIRouter(t)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockEmailService)
+			if tt.setup != nil {
+				tt.setup(mockService)
+			}
+			handler := NewEmailHandler(config.Server{PageSize: 50, MaxBodySize: 1024}, mockService)
+
+			mux := http.NewServeMux()
+			mux.HandleFunc("POST /v1/send-email", handler.Send)
+			mux.HandleFunc("POST /v1/send-email/batch", handler.SendBatch)
+			mux.HandleFunc("GET /v1/emails", handler.List)
+			mux.HandleFunc("GET /v1/emails/{id}", handler.Get)
+			mux.HandleFunc("POST /v1/emails/{id}/cancel", handler.Cancel)
+			mux.HandleFunc("POST /v1/emails/{id}/retry", handler.Retry)
+			mux.HandleFunc("POST /v1/emails/cancel", handler.BulkCancel)
+			mux.HandleFunc("POST /v1/emails/retry", handler.BulkRetry)
+
+			req := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			contentType := tt.contentType
+			if contentType == "" {
+				contentType = "application/json"
+			}
+			req.Header.Set("Content-Type", contentType)
+			if tt.idemKey != "" {
+				req.Header.Set(IdempotencyKeyHeader, tt.idemKey)
+			}
+			w := httptest.NewRecorder()
+
+			mux.ServeHTTP(w, req)
+			require.Equal(t, tt.wantStatus, w.Code, w.Body.String())
+
+			route, pathParams, err := router.FindRoute(req)
+			require.NoError(t, err)
+
+			err = openapi3filter.ValidateResponse(t.Context(), &openapi3filter.ResponseValidationInput{
+				RequestValidationInput: &openapi3filter.RequestValidationInput{
+					Request:    req,
+					PathParams: pathParams,
+					Route:      route,
+				},
+				Status:  w.Code,
+				Header:  w.Header(),
+				Body:    io.NopCloser(bytes.NewReader(w.Body.Bytes())),
+				Options: &openapi3filter.Options{IncludeResponseStatus: true},
+			})
+			require.NoError(t, err)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,200 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/grishkovelli/betera-mailqusrv/config"
+	"github.com/grishkovelli/betera-mailqusrv/internal/entities"
+)
+
+// LastEventIDHeader is the request header of reconnecting SSE clients carrying the ID of the last received event.
+const LastEventIDHeader = "Last-Event-ID"
+
+// statusChangeEvent is the SSE event type of status changes.
+const statusChangeEvent = "status"
+
+// recentChanges is the number of sent change IDs a stream remembers to skip duplicates.
+const recentChanges = 1024
+
+// statusChangeService defines the interface of the status change feed.
+type statusChangeService interface {
+	Subscribe(f entities.StatusChangeFilter) (<-chan entities.StatusChange, func())
+	Replay(
+		ctx context.Context,
+		afterID int64,
+		f entities.StatusChangeFilter,
+		fn func(entities.StatusChange) error,
+	) error
+}
+
+// StatusChangeHandler streams email status changes as Server-Sent Events.
+type StatusChangeHandler struct {
+	cfg                 config.SSE
+	statusChangeService statusChangeService
+}
+
+// NewStatusChangeHandler creates a new instance of StatusChangeHandler.
+func NewStatusChangeHandler(cfg config.SSE, srv statusChangeService) *StatusChangeHandler {
+	return &StatusChangeHandler{cfg, srv}
+}
+
+// Stream handles the HTTP request for the SSE stream of status changes, optionally filtered by the status
+// and id query parameters. Clients reconnecting with the Last-Event-ID header (or the last_event_id query
+// parameter) first get the changes they missed, along with some they may have received already. The stream
+// ends when the client falls behind, it is expected to reconnect and catch up.
+func (h *StatusChangeHandler) Stream(w http.ResponseWriter, r *http.Request) {
+	f, lastID, err := streamParams(r)
+	if err != nil {
+		renderError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	// the stream outlives the server's write timeout
+	rc := http.NewResponseController(w)
+	if err = rc.SetWriteDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		log.Printf("failed to clear write deadline: %v", err)
+	}
+
+	// subscribe before the replay, so that no change falls between the two
+	changes, unsubscribe := h.statusChangeService.Subscribe(f)
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	// changes commit out of ID order, so the ones replayed or announced twice are skipped by ID
+	// instead of dropping every change with an ID lower than the last sent one
+	sent := newRecentIDs(recentChanges)
+	send := func(c entities.StatusChange) error {
+		if !sent.add(c.ID) {
+			return nil
+		}
+		if err := writeEvent(w, c); err != nil {
+			return err
+		}
+
+		return rc.Flush()
+	}
+
+	ctx := r.Context()
+	if lastID > 0 {
+		if err = h.statusChangeService.Replay(ctx, lastID, f, send); err != nil {
+			log.Printf("failed to replay status changes: %v", err)
+			return
+		}
+	}
+	if err = rc.Flush(); err != nil {
+		return
+	}
+
+	keepalive := time.NewTicker(time.Duration(max(h.cfg.KeepaliveInterval, 1)) * time.Second)
+	defer keepalive.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-keepalive.C:
+			if _, err = fmt.Fprint(w, ": keepalive\n\n"); err != nil {
+				return
+			}
+			if err = rc.Flush(); err != nil {
+				return
+			}
+		case c, ok := <-changes:
+			if !ok {
+				return
+			}
+			if err = send(c); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// recentIDs is a set of the last added IDs.
+type recentIDs struct {
+	seen map[int64]struct{}
+	ring []int64
+	next int
+}
+
+func newRecentIDs(size int) *recentIDs {
+	return &recentIDs{seen: make(map[int64]struct{}, size), ring: make([]int64, 0, size)}
+}
+
+// add adds the ID, evicting the oldest one when the set is full. It reports false if the ID was already in the set.
+func (r *recentIDs) add(id int64) bool {
+	if _, ok := r.seen[id]; ok {
+		return false
+	}
+
+	if len(r.ring) < cap(r.ring) {
+		r.ring = append(r.ring, id)
+	} else {
+		delete(r.seen, r.ring[r.next])
+		r.ring[r.next] = id
+		r.next = (r.next + 1) % len(r.ring)
+	}
+	r.seen[id] = struct{}{}
+
+	return true
+}
+
+// streamParams parses the filter and the ID of the last event received by the client.
+func streamParams(r *http.Request) (entities.StatusChangeFilter, int64, error) {
+	var f entities.StatusChangeFilter
+	query := r.URL.Query()
+
+	if s := query.Get("status"); s != "" {
+		status, err := entities.ParseStatus(s)
+		if err != nil {
+			return f, 0, err
+		}
+		f.Status = status
+	}
+
+	if id := query.Get("id"); id != "" {
+		n, err := strconv.Atoi(id)
+		if err != nil || n <= 0 {
+			return f, 0, fmt.Errorf("invalid id: %s", id)
+		}
+		f.EmailID = n
+	}
+
+	last := r.Header.Get(LastEventIDHeader)
+	if last == "" {
+		last = query.Get("last_event_id")
+	}
+	if last == "" {
+		return f, 0, nil
+	}
+
+	lastID, err := strconv.ParseInt(last, 10, 64)
+	if err != nil || lastID < 0 {
+		return f, 0, fmt.Errorf("invalid last event id: %s", last)
+	}
+
+	return f, lastID, nil
+}
+
+// writeEvent writes a status change as an SSE event, its ID is the ID of the change.
+func writeEvent(w http.ResponseWriter, c entities.StatusChange) error {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", c.ID, statusChangeEvent, data)
+
+	return err
+}
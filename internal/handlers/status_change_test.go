@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/grishkovelli/betera-mailqusrv/config"
+	"github.com/grishkovelli/betera-mailqusrv/internal/entities"
+)
+
+type MockStatusChangeService struct {
+	mock.Mock
+}
+
+var _ statusChangeService = (*MockStatusChangeService)(nil)
+
+func (m *MockStatusChangeService) Subscribe(f entities.StatusChangeFilter) (<-chan entities.StatusChange, func()) {
+	args := m.Called(f)
+	return args.Get(0).(chan entities.StatusChange), func() {}
+}
+
+func (m *MockStatusChangeService) Replay(
+	ctx context.Context,
+	afterID int64,
+	f entities.StatusChangeFilter,
+	fn func(entities.StatusChange) error,
+) error {
+	args := m.Called(ctx, afterID, f, fn)
+	for _, c := range args.Get(0).([]entities.StatusChange) {
+		if err := fn(c); err != nil {
+			return err
+		}
+	}
+
+	return args.Error(1)
+}
+
+// closedChanges returns a closed channel holding the given changes, which ends the stream once they are sent.
+func closedChanges(changes ...entities.StatusChange) chan entities.StatusChange {
+	ch := make(chan entities.StatusChange, len(changes))
+	for _, c := range changes {
+		ch <- c
+	}
+	close(ch)
+
+	return ch
+}
+
+func TestStatusChangeHandler_Stream(t *testing.T) {
+	tests := []struct {
+		name           string
+		url            string
+		lastEventID    string
+		filter         entities.StatusChangeFilter
+		replay         []entities.StatusChange
+		live           []entities.StatusChange
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name: "live changes",
+			url:  "/emails/events",
+			live: []entities.StatusChange{
+				{ID: 1, EmailID: 7, Status: entities.Pending},
+				{ID: 2, EmailID: 7, From: entities.Pending, Status: entities.Processing},
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: "id: 1\nevent: status\n" +
+				`data: {"id":1,"email_id":7,"from_status":"","status":"pending","created_at":"0001-01-01T00:00:00Z"}` +
+				"\n\nid: 2\nevent: status\n" +
+				`data: {"id":2,"email_id":7,"from_status":"pending","status":"processing",` +
+				`"created_at":"0001-01-01T00:00:00Z"}` + "\n\n",
+		},
+		{
+			name:        "replay after last event id skips duplicates",
+			url:         "/emails/events?status=sent&id=7",
+			lastEventID: "3",
+			filter:      entities.StatusChangeFilter{EmailID: 7, Status: entities.Sent},
+			replay:      []entities.StatusChange{{ID: 5, EmailID: 7, Status: entities.Sent}},
+			live: []entities.StatusChange{
+				{ID: 5, EmailID: 7, Status: entities.Sent},
+				{ID: 9, EmailID: 7, Status: entities.Sent},
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: "id: 5\nevent: status\n" +
+				`data: {"id":5,"email_id":7,"from_status":"","status":"sent","created_at":"0001-01-01T00:00:00Z"}` +
+				"\n\nid: 9\nevent: status\n" +
+				`data: {"id":9,"email_id":7,"from_status":"","status":"sent","created_at":"0001-01-01T00:00:00Z"}` +
+				"\n\n",
+		},
+		{
+			name:   "changes committed out of id order",
+			url:    "/emails/events?id=7",
+			filter: entities.StatusChangeFilter{EmailID: 7},
+			live: []entities.StatusChange{
+				{ID: 9, EmailID: 7, Status: entities.Sent},
+				{ID: 8, EmailID: 7, Status: entities.Cancelled},
+				{ID: 9, EmailID: 7, Status: entities.Sent},
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: "id: 9\nevent: status\n" +
+				`data: {"id":9,"email_id":7,"from_status":"","status":"sent","created_at":"0001-01-01T00:00:00Z"}` +
+				"\n\nid: 8\nevent: status\n" +
+				`data: {"id":8,"email_id":7,"from_status":"","status":"cancelled",` +
+				`"created_at":"0001-01-01T00:00:00Z"}` + "\n\n",
+		},
+		{
+			name:           "unknown status",
+			url:            "/emails/events?status=unknown",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid id",
+			url:            "/emails/events?id=abc",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid last event id",
+			url:            "/emails/events?last_event_id=abc",
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := new(MockStatusChangeService)
+			if tt.expectedStatus == http.StatusOK {
+				srv.On("Subscribe", tt.filter).Return(closedChanges(tt.live...))
+			}
+			if tt.replay != nil {
+				srv.On("Replay", mock.Anything, int64(3), tt.filter, mock.Anything).Return(tt.replay, nil)
+			}
+			h := NewStatusChangeHandler(config.SSE{KeepaliveInterval: 15}, srv)
+
+			req := httptest.NewRequest(http.MethodGet, tt.url, nil)
+			if tt.lastEventID != "" {
+				req.Header.Set(LastEventIDHeader, tt.lastEventID)
+			}
+			w := httptest.NewRecorder()
+
+			h.Stream(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				assert.Equal(t, "text/event-stream", w.Header().Get("Content-Type"))
+				assert.Equal(t, tt.expectedBody, w.Body.String())
+			}
+			srv.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRecentIDs(t *testing.T) {
+	ids := newRecentIDs(2)
+
+	assert.True(t, ids.add(1))
+	assert.True(t, ids.add(2))
+	assert.False(t, ids.add(1))
+	assert.True(t, ids.add(3)) // evicts 1
+	assert.True(t, ids.add(1)) // evicts 2
+	assert.False(t, ids.add(3))
+	assert.True(t, ids.add(2))
+}
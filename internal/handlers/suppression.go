@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/grishkovelli/betera-mailqusrv/config"
+	"github.com/grishkovelli/betera-mailqusrv/internal/entities"
+)
+
+// suppressionService defines the interface for suppression list operations.
+type suppressionService interface {
+	Create(ctx context.Context, p entities.CreateSuppression) (entities.Suppression, error)
+	Get(ctx context.Context, id int) (entities.Suppression, error)
+	List(ctx context.Context, limit, cursor int) ([]entities.Suppression, error)
+	Delete(ctx context.Context, id int) error
+}
+
+// SuppressionHandler handles HTTP requests related to the suppression list.
+type SuppressionHandler struct {
+	cfg                config.Server
+	suppressionService suppressionService
+}
+
+// NewSuppressionHandler creates a new instance of SuppressionHandler.
+func NewSuppressionHandler(cfg config.Server, srv suppressionService) *SuppressionHandler {
+	return &SuppressionHandler{cfg, srv}
+}
+
+// Create handles the HTTP request to suppress an address.
+func (h *SuppressionHandler) Create(w http.ResponseWriter, r *http.Request) {
+	params := entities.CreateSuppression{}
+
+	if err := validateParams(w, r, h.cfg, &params); err != nil {
+		renderError(w, errorStatus(err), err)
+		return
+	}
+
+	ctx := r.Context()
+	s, err := h.suppressionService.Create(ctx, params)
+	if err != nil {
+		renderError(w, errorStatus(err), err)
+		return
+	}
+
+	renderJSON(w, http.StatusCreated, s)
+}
+
+// Get handles the HTTP request to retrieve a single suppression.
+func (h *SuppressionHandler) Get(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		renderError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	ctx := r.Context()
+	s, err := h.suppressionService.Get(ctx, id)
+	if err != nil {
+		renderError(w, errorStatus(err), err)
+		return
+	}
+
+	renderJSON(w, http.StatusOK, s)
+}
+
+// List handles the HTTP request to retrieve a page of suppressions.
+func (h *SuppressionHandler) List(w http.ResponseWriter, r *http.Request) {
+	cursor, err := cursorParam(r)
+	if err != nil {
+		renderError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	ctx := r.Context()
+	list, err := h.suppressionService.List(ctx, h.cfg.PageSize, cursor)
+	if err != nil {
+		renderError(w, errorStatus(err), err)
+		return
+	}
+
+	renderJSON(w, http.StatusOK, list)
+}
+
+// Delete handles the HTTP request to remove an address from the suppression list.
+func (h *SuppressionHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		renderError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	ctx := r.Context()
+	if err = h.suppressionService.Delete(ctx, id); err != nil {
+		renderError(w, errorStatus(err), err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
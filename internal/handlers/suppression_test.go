@@ -0,0 +1,185 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grishkovelli/betera-mailqusrv/config"
+	"github.com/grishkovelli/betera-mailqusrv/internal/entities"
+)
+
+type MockSuppressionService struct {
+	mock.Mock
+}
+
+var _ suppressionService = (*MockSuppressionService)(nil)
+
+func (m *MockSuppressionService) Create(
+	ctx context.Context,
+	p entities.CreateSuppression,
+) (entities.Suppression, error) {
+	args := m.Called(ctx, p)
+	return args.Get(0).(entities.Suppression), args.Error(1)
+}
+
+func (m *MockSuppressionService) Get(ctx context.Context, id int) (entities.Suppression, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(entities.Suppression), args.Error(1)
+}
+
+func (m *MockSuppressionService) List(ctx context.Context, limit, cursor int) ([]entities.Suppression, error) {
+	args := m.Called(ctx, limit, cursor)
+	return args.Get(0).([]entities.Suppression), args.Error(1)
+}
+
+func (m *MockSuppressionService) Delete(ctx context.Context, id int) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func TestSuppressionHandler_Create(t *testing.T) {
+	tests := []struct {
+		name           string
+		requestBody    string
+		params         entities.CreateSuppression
+		mockError      error
+		expectedStatus int
+	}{
+		{
+			name:           "successful suppression",
+			requestBody:    `{"address":"test@example.com","reason":"bounce"}`,
+			params:         entities.CreateSuppression{Address: "test@example.com", Reason: entities.Bounce},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "invalid address",
+			requestBody:    `{"address":"invalid-email","reason":"bounce"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid reason",
+			requestBody:    `{"address":"test@example.com","reason":"unknown"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "service error",
+			requestBody:    `{"address":"test@example.com","reason":"manual"}`,
+			params:         entities.CreateSuppression{Address: "test@example.com", Reason: entities.Manual},
+			mockError:      errors.New("service error"),
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockSuppressionService)
+			handler := NewSuppressionHandler(config.Server{}, mockService)
+
+			req := httptest.NewRequest(http.MethodPost, "/suppressions", bytes.NewBufferString(tt.requestBody))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			if tt.params.Address != "" {
+				mockService.On("Create", mock.Anything, tt.params).
+					Return(entities.Suppression{ID: 1, Address: tt.params.Address, Reason: tt.params.Reason}, tt.mockError)
+			}
+
+			handler.Create(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestSuppressionHandler_List(t *testing.T) {
+	mockService := new(MockSuppressionService)
+	handler := NewSuppressionHandler(config.Server{PageSize: 10}, mockService)
+
+	list := []entities.Suppression{
+		{ID: 6, Address: "test6@example.com", Reason: entities.Unsubscribe},
+	}
+	mockService.On("List", mock.Anything, 10, 5).Return(list, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/suppressions?cursor=5", nil)
+	w := httptest.NewRecorder()
+
+	handler.List(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response []entities.Suppression
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+	assert.Equal(t, list, response)
+	mockService.AssertExpectations(t)
+}
+
+func TestSuppressionHandler_Get(t *testing.T) {
+	mockService := new(MockSuppressionService)
+	handler := NewSuppressionHandler(config.Server{}, mockService)
+
+	mockService.On("Get", mock.Anything, 7).Return(entities.Suppression{}, entities.ErrSuppressionNotFound)
+
+	req := httptest.NewRequest(http.MethodGet, "/suppressions/7", nil)
+	req.SetPathValue("id", "7")
+	w := httptest.NewRecorder()
+
+	handler.Get(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestSuppressionHandler_Delete(t *testing.T) {
+	tests := []struct {
+		name           string
+		id             string
+		mockError      error
+		expectedStatus int
+	}{
+		{
+			name:           "successful delete",
+			id:             "1",
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name:           "not found",
+			id:             "2",
+			mockError:      entities.ErrSuppressionNotFound,
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "invalid id",
+			id:             "invalid",
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockSuppressionService)
+			handler := NewSuppressionHandler(config.Server{}, mockService)
+
+			req := httptest.NewRequest(http.MethodDelete, "/suppressions/"+tt.id, nil)
+			req.SetPathValue("id", tt.id)
+			w := httptest.NewRecorder()
+
+			if tt.expectedStatus != http.StatusBadRequest {
+				mockService.On("Delete", mock.Anything, mock.AnythingOfType("int")).Return(tt.mockError)
+			}
+
+			handler.Delete(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
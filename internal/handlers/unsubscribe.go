@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/grishkovelli/betera-mailqusrv/internal/entities"
+)
+
+// unsubscribeService defines the interface for one-click unsubscribes.
+type unsubscribeService interface {
+	Unsubscribe(ctx context.Context, token string) (entities.Suppression, error)
+}
+
+// UnsubscribeHandler handles RFC 8058 one-click unsubscribe requests sent by mailbox providers.
+type UnsubscribeHandler struct {
+	unsubscribeService unsubscribeService
+}
+
+// NewUnsubscribeHandler creates a new instance of UnsubscribeHandler.
+func NewUnsubscribeHandler(srv unsubscribeService) *UnsubscribeHandler {
+	return &UnsubscribeHandler{srv}
+}
+
+// Unsubscribe handles the HTTP request to unsubscribe the recipient referenced by the token.
+// The List-Unsubscribe=One-Click form body sent by mailbox providers carries no data and is ignored.
+func (h *UnsubscribeHandler) Unsubscribe(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	suppression, err := h.unsubscribeService.Unsubscribe(ctx, r.PathValue("token"))
+	if err != nil {
+		renderError(w, errorStatus(err), err)
+		return
+	}
+
+	renderJSON(w, http.StatusOK, suppression)
+}
@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grishkovelli/betera-mailqusrv/internal/entities"
+	"github.com/grishkovelli/betera-mailqusrv/internal/signing"
+)
+
+type MockUnsubscribeService struct {
+	mock.Mock
+}
+
+var _ unsubscribeService = (*MockUnsubscribeService)(nil)
+
+func (m *MockUnsubscribeService) Unsubscribe(ctx context.Context, token string) (entities.Suppression, error) {
+	args := m.Called(ctx, token)
+	return args.Get(0).(entities.Suppression), args.Error(1)
+}
+
+func TestUnsubscribeHandler_Unsubscribe(t *testing.T) {
+	suppression := entities.Suppression{
+		ID:       1,
+		Address:  "test@example.com",
+		Reason:   entities.Unsubscribe,
+		Category: "news",
+	}
+
+	tests := []struct {
+		name           string
+		mockResult     entities.Suppression
+		mockError      error
+		expectedStatus int
+	}{
+		{
+			name:           "unsubscribed",
+			mockResult:     suppression,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "invalid token",
+			mockError:      signing.ErrInvalidToken,
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "unknown email",
+			mockError:      entities.ErrEmailNotFound,
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "service error",
+			mockError:      errors.New("db error"),
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockUnsubscribeService)
+			handler := NewUnsubscribeHandler(mockService)
+
+			body := strings.NewReader("List-Unsubscribe=One-Click")
+			req := httptest.NewRequest(http.MethodPost, "/unsubscribe/abc", body)
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			req.SetPathValue("token", "abc")
+			w := httptest.NewRecorder()
+
+			mockService.On("Unsubscribe", mock.Anything, "abc").Return(tt.mockResult, tt.mockError)
+
+			handler.Unsubscribe(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				var got entities.Suppression
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&got))
+				assert.Equal(t, tt.mockResult, got)
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,22 @@
+package metrics
+
+import (
+	"expvar"
+	"sync"
+)
+
+// mu guards the registration of expvar variables, which panics on duplicate names.
+var mu sync.Mutex //nolint:gochecknoglobals // expvar registry is process wide
+
+// Map returns the published expvar map with the given name, creating it on first use.
+// The values are served as JSON by expvar.Handler.
+func Map(name string) *expvar.Map {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if m, ok := expvar.Get(name).(*expvar.Map); ok {
+		return m
+	}
+
+	return expvar.NewMap(name)
+}
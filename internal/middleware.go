@@ -1,39 +1,281 @@
 package server
 
 import (
+	"context"
+	"errors"
+	"expvar"
 	"log/slog"
 	"net/http"
+	"net/url"
+	"runtime/debug"
+	"slices"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/grishkovelli/betera-mailqusrv/config"
+	"github.com/grishkovelli/betera-mailqusrv/internal/handlers"
+	"github.com/grishkovelli/betera-mailqusrv/internal/requestid"
+	"github.com/grishkovelli/betera-mailqusrv/internal/signing"
+	"github.com/grishkovelli/betera-mailqusrv/internal/tracing"
+)
+
+var errInternal = errors.New("internal server error")
+
+// Auth errors.
+var (
+	errUnauthorized = errors.New("admin credentials required")
+	errInvalidToken = errors.New("valid bearer token required")
+	errCrossOrigin  = errors.New("cross-origin request")
 )
 
+// redacted replaces the values of sensitive query parameters in access logs.
+const redacted = "REDACTED"
+
 type loggingWriter struct {
 	http.ResponseWriter
 	status int
+	bytes  int
 }
 
 func (w *loggingWriter) WriteHeader(code int) {
-	w.status = code
+	if w.status == 0 {
+		w.status = code
+	}
 	w.ResponseWriter.WriteHeader(code)
 }
 
 func (w *loggingWriter) Write(b []byte) (int, error) {
-	return w.ResponseWriter.Write(b)
+	// like http.ResponseWriter, the first write without WriteHeader sends 200
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.bytes += n
+
+	return n, err
 }
 
-func loggingAccess(logger *slog.Logger) func(http.Handler) http.Handler {
+// statusCode returns the sent status, handlers that write nothing get an implicit 200.
+func (w *loggingWriter) statusCode() int {
+	if w.status == 0 {
+		return http.StatusOK
+	}
+
+	return w.status
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController.
+func (w *loggingWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// loggingAccess logs every request with its latency, response size and user agent. Values of the
+// query parameters in redact are replaced, 4xx responses are logged as warnings and 5xx as errors.
+func loggingAccess(logger *slog.Logger, redact []string) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
 			lw := &loggingWriter{ResponseWriter: w}
 			next.ServeHTTP(lw, r)
 
-			reqLogger := logger.With(
+			status := lw.statusCode()
+			logger.LogAttrs(r.Context(), accessLevel(status), "request",
 				slog.Group("http",
 					slog.String("method", r.Method),
 					slog.String("path", r.URL.Path),
+					slog.String("query", redactQuery(r.URL.RawQuery, redact)),
 					slog.String("remote_addr", r.RemoteAddr),
-					slog.Int("status", lw.status),
+					slog.String("user_agent", r.UserAgent()),
+					slog.Int("status", status),
+					slog.Int("bytes", lw.bytes),
+					slog.Duration("duration", time.Since(start)),
 				))
+		})
+	}
+}
+
+// accessLevel returns the log level of a response status.
+func accessLevel(status int) slog.Level {
+	switch {
+	case status >= http.StatusInternalServerError:
+		return slog.LevelError
+	case status >= http.StatusBadRequest:
+		return slog.LevelWarn
+	default:
+		return slog.LevelInfo
+	}
+}
+
+// redactQuery replaces the values of the given parameters (case-insensitive) in a raw query string.
+func redactQuery(rawQuery string, params []string) string {
+	if rawQuery == "" {
+		return ""
+	}
+
+	// malformed pairs are dropped rather than logged verbatim
+	values, _ := url.ParseQuery(rawQuery)
+	for key, vals := range values {
+		if !slices.ContainsFunc(params, func(p string) bool { return strings.EqualFold(p, key) }) {
+			continue
+		}
+		for i := range vals {
+			vals[i] = redacted
+		}
+	}
+
+	return values.Encode()
+}
+
+// withRecover turns handler panics into 500 responses. The panic is logged with its stack and
+// counted in the http key of panics, responses that were already started are left as they are.
+func withRecover(logger *slog.Logger, panics *expvar.Map) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			lw := &loggingWriter{ResponseWriter: w}
+			defer func() {
+				rec := recover()
+				if rec == nil {
+					return
+				}
+				// net/http aborts the response of this sentinel without logging
+				if rec == http.ErrAbortHandler { //nolint:errorlint // recovered values are compared as is
+					panic(rec)
+				}
+
+				panics.Add("http", 1)
+				logger.ErrorContext(r.Context(), "handler panic",
+					"method", r.Method,
+					"path", r.URL.Path,
+					"panic", rec,
+					"stack", string(debug.Stack()),
+				)
+				if lw.status == 0 {
+					handlers.RenderError(lw, http.StatusInternalServerError, errInternal)
+				}
+			}()
+
+			next.ServeHTTP(lw, r)
+		})
+	}
+}
+
+// withTimeout cancels the request context after d, so that queries of slow requests are aborted.
+// A zero duration disables the timeout.
+func withTimeout(d time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if d <= 0 {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx, cancel := context.WithTimeout(r.Context(), d)
+			defer cancel()
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// withRequestID propagates the X-Request-ID header of the request, or a generated ID if it is missing
+// or malformed, through the request context and echoes it in the response.
+func withRequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(requestid.Header)
+		if !requestid.Valid(id) {
+			id = requestid.New()
+		}
+
+		w.Header().Set(requestid.Header, id)
+		next.ServeHTTP(w, r.WithContext(requestid.NewContext(r.Context(), id)))
+	})
+}
+
+// withAdminAuth requires the admin credentials through HTTP basic auth. Browsers attach the credentials
+// to requests of other sites as well, so state changing requests have to come from the same origin.
+func withAdminAuth(cfg config.Admin) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user, pass, _ := r.BasicAuth()
+			// both comparisons run, so that timing does not tell which one failed
+			userOK := signing.Equal(user, cfg.Username)
+			passOK := signing.Equal(pass, cfg.Password)
+			if !userOK || !passOK || cfg.Password == "" {
+				w.Header().Set("WWW-Authenticate", `Basic realm="mailqusrv admin", charset="UTF-8"`)
+				handlers.RenderError(w, http.StatusUnauthorized, errUnauthorized)
+				return
+			}
+
+			if r.Method != http.MethodGet && r.Method != http.MethodHead && crossOrigin(r) {
+				handlers.RenderError(w, http.StatusForbidden, errCrossOrigin)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// withBearerToken requires the token in the Authorization header, an empty token rejects all requests.
+func withBearerToken(token string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok || !signing.Equal(got, token) || token == "" {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="mailqusrv"`)
+				handlers.RenderError(w, http.StatusUnauthorized, errInvalidToken)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// crossOrigin reports whether the request was made by a page of another origin, using the Sec-Fetch-Site
+// header of current browsers and the Origin header of older ones. Requests of non-browser clients have neither.
+func crossOrigin(r *http.Request) bool {
+	if site := r.Header.Get("Sec-Fetch-Site"); site != "" {
+		return site != "same-origin" && site != "none"
+	}
+
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return false
+	}
+	u, err := url.Parse(origin)
+
+	return err != nil || u.Host != r.Host
+}
+
+// withSpan traces requests of a route, continuing the trace of the caller if the request carries one.
+func withSpan(route string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+			ctx, span := tracing.Start(ctx, route,
+				trace.WithSpanKind(trace.SpanKindServer),
+				trace.WithAttributes(
+					semconv.HTTPRequestMethodKey.String(r.Method),
+					semconv.HTTPRoute(route),
+					semconv.URLPath(r.URL.Path),
+				),
+			)
+			defer span.End()
+
+			lw := &loggingWriter{ResponseWriter: w}
+			next.ServeHTTP(lw, r.WithContext(ctx))
 
-			reqLogger.Info("request")
+			status := lw.statusCode()
+			span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+			if status >= http.StatusInternalServerError {
+				span.SetStatus(codes.Error, http.StatusText(status))
+			}
 		})
 	}
 }
@@ -0,0 +1,375 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"expvar"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/grishkovelli/betera-mailqusrv/config"
+	"github.com/grishkovelli/betera-mailqusrv/internal/requestid"
+	"github.com/grishkovelli/betera-mailqusrv/internal/tracing"
+)
+
+func TestWithTimeout(t *testing.T) {
+	tests := []struct {
+		name         string
+		timeout      time.Duration
+		wantDeadline bool
+	}{
+		{name: "timeout", timeout: time.Millisecond, wantDeadline: true},
+		{name: "disabled", timeout: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ctxErr error
+			var hasDeadline bool
+			h := withTimeout(tt.timeout)(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+				_, hasDeadline = r.Context().Deadline()
+				if hasDeadline {
+					<-r.Context().Done()
+				}
+				ctxErr = r.Context().Err()
+			}))
+
+			h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+			if hasDeadline != tt.wantDeadline {
+				t.Errorf("deadline set = %v, want %v", hasDeadline, tt.wantDeadline)
+			}
+			if tt.wantDeadline && !errors.Is(ctxErr, context.DeadlineExceeded) {
+				t.Errorf("context error = %v, want %v", ctxErr, context.DeadlineExceeded)
+			}
+		})
+	}
+}
+
+func TestWithRequestID(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		keep   bool
+	}{
+		{name: "propagated", header: "req-42", keep: true},
+		{name: "missing"},
+		{name: "malformed", header: "req 42\nforged"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			h := withRequestID(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+				got = requestid.FromContext(r.Context())
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.header != "" {
+				req.Header.Set(requestid.Header, tt.header)
+			}
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, req)
+
+			if tt.keep && got != tt.header {
+				t.Errorf("request ID = %q, want %q", got, tt.header)
+			}
+			if !tt.keep && (got == "" || got == tt.header) {
+				t.Errorf("request ID = %q, want a generated one", got)
+			}
+			if echoed := w.Header().Get(requestid.Header); echoed != got {
+				t.Errorf("echoed request ID = %q, want %q", echoed, got)
+			}
+		})
+	}
+}
+
+func TestWithSpan(t *testing.T) {
+	exp := tracetest.NewInMemoryExporter()
+	prevProvider, prevPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(tracing.NewProvider(config.Tracing{SampleRatio: 1}, sdktrace.WithSyncer(exp)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(prevProvider)
+		otel.SetTextMapPropagator(prevPropagator)
+	})
+
+	const parent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	var traceParent string
+	h := withSpan("GET /emails/{id}")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceParent = tracing.TraceParent(r.Context())
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/emails/1", nil)
+	req.Header.Set("Traceparent", parent)
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := exp.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("got %d spans, want 1", len(spans))
+	}
+	span := spans[0]
+	if span.Name != "GET /emails/{id}" {
+		t.Errorf("span name = %q, want the route pattern", span.Name)
+	}
+	if got := span.SpanContext.TraceID().String(); got != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("trace ID = %s, want the propagated one", got)
+	}
+	if traceParent != "00-"+span.SpanContext.TraceID().String()+"-"+span.SpanContext.SpanID().String()+"-01" {
+		t.Errorf("handler trace context = %q, want the route span", traceParent)
+	}
+	if span.Status.Code != codes.Error {
+		t.Errorf("span status = %v, want %v", span.Status.Code, codes.Error)
+	}
+}
+
+func TestLoggingAccess(t *testing.T) {
+	tests := []struct {
+		name      string
+		target    string
+		handler   http.HandlerFunc
+		wantLevel string
+		wantAttrs []string
+	}{
+		{
+			name:      "implicit status",
+			target:    "/emails?status=sent",
+			handler:   func(w http.ResponseWriter, _ *http.Request) { _, _ = w.Write([]byte("hello")) },
+			wantLevel: "INFO",
+			wantAttrs: []string{"http.status=200", "http.bytes=5", `http.query="status=sent"`, "http.user_agent=curl/8.0"},
+		},
+		{
+			name:      "no body",
+			target:    "/emails",
+			handler:   func(http.ResponseWriter, *http.Request) {},
+			wantLevel: "INFO",
+			wantAttrs: []string{"http.status=200", "http.bytes=0"},
+		},
+		{
+			name:      "client error",
+			target:    "/t/c/x?Token=secret&url=https%3A%2F%2Fexample.com",
+			handler:   func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusNotFound) },
+			wantLevel: "WARN",
+			wantAttrs: []string{"http.status=404", `http.query="Token=REDACTED&url=https%3A%2F%2Fexample.com"`},
+		},
+		{
+			name:      "server error",
+			target:    "/emails",
+			handler:   func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusInternalServerError) },
+			wantLevel: "ERROR",
+			wantAttrs: []string{"http.status=500"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			logger := slog.New(slog.NewTextHandler(buf, nil))
+			h := loggingAccess(logger, []string{"token"})(tt.handler)
+
+			req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			req.Header.Set("User-Agent", "curl/8.0")
+			h.ServeHTTP(httptest.NewRecorder(), req)
+
+			line := buf.String()
+			if !strings.Contains(line, "level="+tt.wantLevel+" ") {
+				t.Errorf("log line %q, want level %s", line, tt.wantLevel)
+			}
+			for _, attr := range append(tt.wantAttrs, "http.duration=") {
+				if !strings.Contains(line, attr) {
+					t.Errorf("log line %q has no %s", line, attr)
+				}
+			}
+			if strings.Contains(line, "secret") {
+				t.Errorf("log line %q has a redacted value", line)
+			}
+		})
+	}
+}
+
+func TestWithRecover(t *testing.T) {
+	tests := []struct {
+		name       string
+		handler    http.HandlerFunc
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "panic",
+			handler:    func(http.ResponseWriter, *http.Request) { panic("nil map") },
+			wantStatus: http.StatusInternalServerError,
+			wantBody:   `{"code":"internal","message":"internal server error"}`,
+		},
+		{
+			name: "panic after write",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusAccepted)
+				panic("nil map")
+			},
+			wantStatus: http.StatusAccepted,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			logger := slog.New(requestid.NewLogHandler(slog.NewTextHandler(buf, nil)))
+			panics := new(expvar.Map).Init()
+			h := withRecover(logger, panics)(tt.handler)
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "/emails", nil)
+			h.ServeHTTP(w, r.WithContext(requestid.NewContext(r.Context(), "req-1")))
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if got := strings.TrimSpace(w.Body.String()); got != tt.wantBody {
+				t.Errorf("body = %s, want %s", got, tt.wantBody)
+			}
+			if got := panics.Get("http").String(); got != "1" {
+				t.Errorf("panic count = %s, want 1", got)
+			}
+			for _, attr := range []string{"handler panic", "panic=\"nil map\"", "request_id=req-1", "stack="} {
+				if !strings.Contains(buf.String(), attr) {
+					t.Errorf("log %q has no %s", buf.String(), attr)
+				}
+			}
+		})
+	}
+}
+
+func TestWithRecover_AbortHandler(t *testing.T) {
+	logger := slog.New(slog.DiscardHandler)
+	h := withRecover(logger, new(expvar.Map).Init())(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		panic(http.ErrAbortHandler)
+	}))
+
+	defer func() {
+		if rec := recover(); rec != http.ErrAbortHandler { //nolint:errorlint // recovered values are compared as is
+			t.Errorf("recovered %v, want %v", rec, http.ErrAbortHandler)
+		}
+	}()
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+}
+
+func TestWithAdminAuth(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		user, pass string
+		header     map[string]string
+		wantStatus int
+	}{
+		{name: "authorized", method: http.MethodGet, user: "admin", pass: "secret", wantStatus: http.StatusOK},
+		{name: "no credentials", method: http.MethodGet, wantStatus: http.StatusUnauthorized},
+		{name: "wrong password", method: http.MethodGet, user: "admin", pass: "guess", wantStatus: http.StatusUnauthorized},
+		{name: "wrong user", method: http.MethodGet, user: "root", pass: "secret", wantStatus: http.StatusUnauthorized},
+		{
+			name: "same origin post", method: http.MethodPost, user: "admin", pass: "secret",
+			header:     map[string]string{"Sec-Fetch-Site": "same-origin", "Origin": "http://example.com"},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "cross site post", method: http.MethodPost, user: "admin", pass: "secret",
+			header:     map[string]string{"Sec-Fetch-Site": "cross-site"},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name: "foreign origin post", method: http.MethodPost, user: "admin", pass: "secret",
+			header:     map[string]string{"Origin": "https://evil.example"},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name: "cross site get", method: http.MethodGet, user: "admin", pass: "secret",
+			header:     map[string]string{"Sec-Fetch-Site": "cross-site"},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "post without browser headers", method: http.MethodPost, user: "admin", pass: "secret",
+			wantStatus: http.StatusOK,
+		},
+	}
+
+	h := withAdminAuth(config.Admin{Username: "admin", Password: "secret"})(
+		http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}),
+	)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, "http://example.com/admin/api/emails/1/retry", nil)
+			if tt.user != "" {
+				r.SetBasicAuth(tt.user, tt.pass)
+			}
+			for k, v := range tt.header {
+				r.Header.Set(k, v)
+			}
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if tt.wantStatus == http.StatusUnauthorized && w.Header().Get("WWW-Authenticate") == "" {
+				t.Error("no WWW-Authenticate challenge")
+			}
+		})
+	}
+}
+
+func TestWithAdminAuth_NoPassword(t *testing.T) {
+	h := withAdminAuth(config.Admin{Username: "admin"})(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+
+	r := httptest.NewRequest(http.MethodGet, "/admin/", nil)
+	r.SetBasicAuth("admin", "")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusUnauthorized)
+	}
+}
+
+func TestWithBearerToken(t *testing.T) {
+	tests := []struct {
+		name       string
+		token      string
+		header     string
+		wantStatus int
+	}{
+		{name: "valid token", token: "secret", header: "Bearer secret", wantStatus: http.StatusOK},
+		{name: "no header", token: "secret", wantStatus: http.StatusUnauthorized},
+		{name: "wrong token", token: "secret", header: "Bearer guess", wantStatus: http.StatusUnauthorized},
+		{name: "basic auth", token: "secret", header: "Basic c2VjcmV0", wantStatus: http.StatusUnauthorized},
+		{name: "no token configured", header: "Bearer ", wantStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := withBearerToken(tt.token)(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+
+			r := httptest.NewRequest(http.MethodPost, "/bounces", nil)
+			if tt.header != "" {
+				r.Header.Set("Authorization", tt.header)
+			}
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+		})
+	}
+}
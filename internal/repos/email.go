@@ -2,13 +2,23 @@ package repos
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strings"
 
 	"github.com/grishkovelli/betera-mailqusrv/internal/entities"
+	"github.com/grishkovelli/betera-mailqusrv/internal/tracing"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
+// emailColumns lists the columns scanned into entities.Email.
+const emailColumns = "id, to_address, subject, body, status, attempts, message_id, provider, sent_provider, " +
+	"from_address, from_name, reply_to, html_body, track, category, request_id, traceparent, idempotency_key"
+
 // EmailRepo handles all database operations related to emails.
 type EmailRepo struct {
 	db *pgxpool.Pool
@@ -20,29 +30,66 @@ func NewEmailRepo(db *pgxpool.Pool) *EmailRepo {
 }
 
 // Create inserts a new email record into the database and returns the created email.
-func (r *EmailRepo) Create(ctx context.Context, email entities.CreateEmail) (entities.Email, error) {
-	rows, err := r.db.Query(ctx, `
-		INSERT INTO emails (to_address, subject, body)
-		VALUES ($1, $2, $3)
-		RETURNING id, to_address, subject, body, status
-	`, email.To, email.Subject, email.Body)
+// An email with the same idempotency key that was created concurrently is returned instead.
+func (r *EmailRepo) Create(ctx context.Context, email entities.Email) (_ entities.Email, err error) {
+	ctx, span := startSpan(ctx, "Create")
+	defer func() { tracing.End(span, err) }()
+
+	rows, err := conn(ctx, r.db).Query(ctx, `
+		INSERT INTO emails (
+			to_address, subject, body, status, message_id, provider,
+			from_address, from_name, reply_to, html_body, track, category, request_id, traceparent,
+			idempotency_key
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+		ON CONFLICT (idempotency_key) WHERE idempotency_key <> '' DO NOTHING
+		RETURNING `+emailColumns+`
+	`,
+		email.To,
+		email.Subject,
+		email.Body,
+		string(email.Status),
+		email.MessageID,
+		email.Provider,
+		email.From,
+		email.FromName,
+		email.ReplyTo,
+		email.HTML,
+		email.Track,
+		email.Category,
+		email.RequestID,
+		email.TraceParent,
+		email.IdempotencyKey,
+	)
 	if err != nil {
 		return entities.Email{}, err
 	}
 
-	return pgx.CollectOneRow(rows, pgx.RowToStructByName[entities.Email])
+	created, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[entities.Email])
+	if errors.Is(err, pgx.ErrNoRows) {
+		return r.GetByIdempotencyKey(ctx, email.IdempotencyKey)
+	}
+
+	return created, err
 }
 
 // GetByStatus retrieves emails with the specified status, using cursor-based pagination.
-func (r *EmailRepo) GetByStatus(ctx context.Context, status string, limit, cursor int) ([]entities.Email, error) {
-	rows, err := r.db.Query(ctx, `
-		SELECT id, to_address, subject, body, status
+func (r *EmailRepo) GetByStatus(
+	ctx context.Context,
+	status entities.Status,
+	limit, cursor int,
+) (_ []entities.Email, err error) {
+	ctx, span := startSpan(ctx, "GetByStatus")
+	defer func() { tracing.End(span, err) }()
+
+	rows, err := conn(ctx, r.db).Query(ctx, `
+		SELECT `+emailColumns+`
 		FROM emails
 		WHERE id > $1
 			AND status = $2
 		ORDER BY id
 		LIMIT $3
-	`, cursor, status, limit)
+	`, cursor, string(status), limit)
 	if err != nil {
 		return nil, err
 	}
@@ -50,37 +97,198 @@ func (r *EmailRepo) GetByStatus(ctx context.Context, status string, limit, curso
 	return pgx.CollectRows(rows, pgx.RowToStructByName[entities.Email])
 }
 
-// WithTransaction executes the provided function within a database transaction.
-func (r *EmailRepo) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
-	tx, err := r.db.Begin(ctx)
+// GetByFilter retrieves a page of emails matching the filter, ordered by ID.
+func (r *EmailRepo) GetByFilter(
+	ctx context.Context,
+	f entities.EmailFilter,
+	limit, cursor int,
+) (_ []entities.Email, err error) {
+	ctx, span := startSpan(ctx, "GetByFilter")
+	defer func() { tracing.End(span, err) }()
+
+	where, args := filterConditions(f, cursor, limit)
+
+	rows, err := conn(ctx, r.db).Query(ctx, `
+		SELECT `+emailColumns+`
+		FROM emails
+		WHERE id > $1`+where+`
+		ORDER BY id
+		LIMIT $2
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return pgx.CollectRows(rows, pgx.RowToStructByName[entities.Email])
+}
+
+// GetByID retrieves a single email by its ID.
+func (r *EmailRepo) GetByID(ctx context.Context, id int) (_ entities.Email, err error) {
+	ctx, span := startSpan(ctx, "GetByID")
+	defer func() { tracing.End(span, err) }()
+
+	rows, err := conn(ctx, r.db).Query(ctx, `
+		SELECT `+emailColumns+`
+		FROM emails
+		WHERE id = $1
+	`, id)
+	if err != nil {
+		return entities.Email{}, err
+	}
+
+	email, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[entities.Email])
+	if errors.Is(err, pgx.ErrNoRows) {
+		return entities.Email{}, entities.ErrEmailNotFound
+	}
+
+	return email, err
+}
+
+// GetByMessageID retrieves a single email by its Message-ID header value.
+func (r *EmailRepo) GetByMessageID(ctx context.Context, messageID string) (_ entities.Email, err error) {
+	ctx, span := startSpan(ctx, "GetByMessageID")
+	defer func() { tracing.End(span, err) }()
+
+	rows, err := conn(ctx, r.db).Query(ctx, `
+		SELECT `+emailColumns+`
+		FROM emails
+		WHERE message_id = $1
+	`, messageID)
+	if err != nil {
+		return entities.Email{}, err
+	}
+
+	email, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[entities.Email])
+	if errors.Is(err, pgx.ErrNoRows) {
+		return entities.Email{}, entities.ErrEmailNotFound
+	}
+
+	return email, err
+}
+
+// GetByIdempotencyKey retrieves the email created by a request with the given idempotency key.
+func (r *EmailRepo) GetByIdempotencyKey(ctx context.Context, key string) (_ entities.Email, err error) {
+	ctx, span := startSpan(ctx, "GetByIdempotencyKey")
+	defer func() { tracing.End(span, err) }()
+
+	rows, err := conn(ctx, r.db).Query(ctx, `
+		SELECT `+emailColumns+`
+		FROM emails
+		WHERE idempotency_key = $1
+			AND idempotency_key <> ''
+	`, key)
+	if err != nil {
+		return entities.Email{}, err
+	}
+
+	email, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[entities.Email])
+	if errors.Is(err, pgx.ErrNoRows) {
+		return entities.Email{}, entities.ErrEmailNotFound
+	}
+
+	return email, err
+}
+
+// UpdateStatus moves a single email to the given status and returns the updated record.
+// The update only succeeds if the current status allows the transition, otherwise
+// an *entities.TransitionError is returned.
+// Moving an email back to pending resets its attempt counter.
+func (r *EmailRepo) UpdateStatus(ctx context.Context, id int, status entities.Status) (_ entities.Email, err error) {
+	ctx, span := startSpan(ctx, "UpdateStatus")
+	defer func() { tracing.End(span, err) }()
+
+	rows, err := conn(ctx, r.db).Query(ctx, `
+		UPDATE emails
+		SET status = $1,
+				attempts = CASE WHEN $1 = 'pending' THEN 0 ELSE attempts END,
+				updated_at = NOW()
+		WHERE id = $2
+			AND status::text = ANY($3)
+		RETURNING `+emailColumns+`
+	`, string(status), id, sourceStatuses(status))
+	if err != nil {
+		return entities.Email{}, err
+	}
+
+	email, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[entities.Email])
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return email, err
+	}
+
+	// nothing was updated, either the email does not exist or its status does not allow the transition
+	current, err := r.GetByID(ctx, id)
+	if err != nil {
+		return entities.Email{}, err
+	}
+
+	return entities.Email{}, &entities.TransitionError{ID: id, From: current.Status, To: status}
+}
+
+// UpdateStatusByFilter moves all emails matching the filter to the given status and returns
+// the number of updated rows. Emails whose current status does not allow the transition are skipped.
+// Moving emails back to pending resets their attempt counters.
+func (r *EmailRepo) UpdateStatusByFilter(
+	ctx context.Context,
+	f entities.EmailFilter,
+	status entities.Status,
+) (_ int64, err error) {
+	ctx, span := startSpan(ctx, "UpdateStatusByFilter")
+	defer func() { tracing.End(span, err) }()
+
+	where, args := filterConditions(f, string(status), sourceStatuses(status))
+
+	tag, err := conn(ctx, r.db).Exec(ctx, `
+		UPDATE emails
+		SET status = $1,
+				attempts = CASE WHEN $1 = 'pending' THEN 0 ELSE attempts END,
+				updated_at = NOW()
+		WHERE status::text = ANY($2)`+where, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	return tag.RowsAffected(), nil
+}
+
+// WithTransaction executes the provided function within a database transaction. Queries of all repositories
+// made with the context passed to fn run within the transaction, so row locks are held until it ends.
+// The transaction is committed if fn succeeds and rolled back otherwise.
+func (r *EmailRepo) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	ctx, span := startSpan(ctx, "WithTransaction")
+	defer func() { tracing.End(span, err) }()
+
+	tx, err := begin(ctx, r.db)
 	if err != nil {
 		return err
 	}
 
 	defer func() {
 		if p := recover(); p != nil {
-			err = tx.Rollback(ctx)
+			_ = tx.Rollback(context.WithoutCancel(ctx))
 			panic(p)
-		} else if err != nil {
-			err = tx.Rollback(ctx)
-		} else {
-			err = tx.Commit(ctx)
 		}
+		if err != nil {
+			_ = tx.Rollback(context.WithoutCancel(ctx))
+			return
+		}
+		err = tx.Commit(ctx)
 	}()
 
-	err = fn(ctx)
-	return err
+	return fn(withTx(ctx, tx))
 }
 
 // LockPendingFailed locks and retrieves a batch of pending or failed emails for processing.
-func (r *EmailRepo) LockPendingFailed(ctx context.Context, batchSize int) ([]entities.Email, error) {
-	rows, err := r.db.Query(ctx, `
-		SELECT id, to_address, subject, body, status
+func (r *EmailRepo) LockPendingFailed(ctx context.Context, batchSize int) (_ []entities.Email, err error) {
+	ctx, span := startSpan(ctx, "LockPendingFailed")
+	defer func() { tracing.End(span, err) }()
+
+	rows, err := conn(ctx, r.db).Query(ctx, `
+		SELECT `+emailColumns+`
 		FROM emails
-		WHERE status IN ('pending', 'failed')
-		LIMIT $1
+		WHERE status::text = ANY($1)
+		LIMIT $2
 		FOR UPDATE SKIP LOCKED
-	`, batchSize)
+	`, sourceStatuses(entities.Processing), batchSize)
 	if err != nil {
 		return nil, err
 	}
@@ -88,30 +296,145 @@ func (r *EmailRepo) LockPendingFailed(ctx context.Context, batchSize int) ([]ent
 	return pgx.CollectRows(rows, pgx.RowToStructByName[entities.Email])
 }
 
-// BatchUpdateStatus updates the status of multiple emails by their IDs.
-func (r *EmailRepo) BatchUpdateStatus(ctx context.Context, ids []int, status string) error {
+// BatchUpdateStatus moves multiple emails to the given status by their IDs.
+// Emails whose current status does not allow the transition are left unchanged
+// and reported with an error wrapping entities.ErrInvalidTransition.
+func (r *EmailRepo) BatchUpdateStatus(ctx context.Context, ids []int, status entities.Status) (err error) {
+	ctx, span := startSpan(ctx, "BatchUpdateStatus")
+	defer func() { tracing.End(span, err) }()
+
 	if len(ids) == 0 {
 		return nil
 	}
 
-	_, err := r.db.Exec(ctx, `
+	tag, err := conn(ctx, r.db).Exec(ctx, `
 		UPDATE emails
 		SET status = $1,
 				updated_at = NOW()
 		WHERE id = ANY($2)
-	`, status, ids)
-	return err
+			AND status::text = ANY($3)
+	`, string(status), ids, sourceStatuses(status))
+	if err != nil {
+		return err
+	}
+
+	return checkAffected(tag.RowsAffected(), len(ids), status)
+}
+
+// MarkSent marks processing emails as sent and records the provider that accepted them.
+func (r *EmailRepo) MarkSent(ctx context.Context, ids []int, provider string) (err error) {
+	ctx, span := startSpan(ctx, "MarkSent")
+	defer func() { tracing.End(span, err) }()
+
+	if len(ids) == 0 {
+		return nil
+	}
+
+	tag, err := conn(ctx, r.db).Exec(ctx, `
+		UPDATE emails
+		SET status = $1,
+				sent_provider = $2,
+				updated_at = NOW()
+		WHERE id = ANY($3)
+			AND status::text = ANY($4)
+	`, string(entities.Sent), provider, ids, sourceStatuses(entities.Sent))
+	if err != nil {
+		return err
+	}
+
+	return checkAffected(tag.RowsAffected(), len(ids), entities.Sent)
+}
+
+// MarkProcessing marks pending or failed emails as processing and counts a new delivery attempt for each of them.
+func (r *EmailRepo) MarkProcessing(ctx context.Context, ids []int) (err error) {
+	ctx, span := startSpan(ctx, "MarkProcessing")
+	defer func() { tracing.End(span, err) }()
+
+	if len(ids) == 0 {
+		return nil
+	}
+
+	tag, err := conn(ctx, r.db).Exec(ctx, `
+		UPDATE emails
+		SET status = $1,
+				attempts = attempts + 1,
+				updated_at = NOW()
+		WHERE id = ANY($2)
+			AND status::text = ANY($3)
+	`, string(entities.Processing), ids, sourceStatuses(entities.Processing))
+	if err != nil {
+		return err
+	}
+
+	return checkAffected(tag.RowsAffected(), len(ids), entities.Processing)
 }
 
 // MarkStuckEmailsAsPending resets the status of emails that have been in 'processing' state for too long.
-func (r *EmailRepo) MarkStuckEmailsAsPending(ctx context.Context, seconds int) error {
-	_, err := r.db.Exec(ctx, `
+func (r *EmailRepo) MarkStuckEmailsAsPending(ctx context.Context, seconds int) (err error) {
+	ctx, span := startSpan(ctx, "MarkStuckEmailsAsPending")
+	defer func() { tracing.End(span, err) }()
+
+	_, err = conn(ctx, r.db).Exec(ctx, `
 		UPDATE emails
-		SET status = 'pending',
+		SET status = $1,
 				updated_at = NOW()
-		WHERE status = 'processing'
-		AND updated_at < NOW() - ($1 * INTERVAL '1 second')
-	`, seconds)
+		WHERE status = $2
+		AND updated_at < NOW() - ($3 * INTERVAL '1 second')
+	`, string(entities.Pending), string(entities.Processing), seconds)
 
 	return err
 }
+
+// startSpan starts a client span of an EmailRepo query.
+func startSpan(ctx context.Context, method string) (context.Context, trace.Span) {
+	return tracing.Start(ctx, "EmailRepo."+method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(semconv.DBSystemPostgreSQL),
+	)
+}
+
+// filterConditions builds additional WHERE conditions for the filter. Positional
+// arguments are numbered after the leading args.
+func filterConditions(f entities.EmailFilter, leading ...any) (string, []any) {
+	var sb strings.Builder
+	args := leading
+
+	if len(f.IDs) > 0 {
+		args = append(args, f.IDs)
+		fmt.Fprintf(&sb, " AND id = ANY($%d)", len(args))
+	}
+	if f.Status != "" {
+		args = append(args, string(f.Status))
+		fmt.Fprintf(&sb, " AND status = $%d", len(args))
+	}
+	if f.To != "" {
+		args = append(args, f.To)
+		fmt.Fprintf(&sb, " AND to_address = $%d", len(args))
+	}
+
+	return sb.String(), args
+}
+
+// sourceStatuses returns the statuses allowed to transition to the given status as strings.
+func sourceStatuses(to entities.Status) []string {
+	from := entities.SourceStatuses(to)
+
+	ss := make([]string, len(from))
+	for i, s := range from {
+		ss[i] = string(s)
+	}
+
+	return ss
+}
+
+// checkAffected reports an error if fewer rows than expected were moved to the given status.
+func checkAffected(affected int64, expected int, status entities.Status) error {
+	if affected < int64(expected) {
+		return fmt.Errorf(
+			"%w: %d of %d emails cannot be moved to %s",
+			entities.ErrInvalidTransition, int64(expected)-affected, expected, status,
+		)
+	}
+
+	return nil
+}
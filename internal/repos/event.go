@@ -0,0 +1,96 @@
+package repos
+
+import (
+	"context"
+	"unicode/utf8"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/grishkovelli/betera-mailqusrv/internal/entities"
+)
+
+// maxUserAgent is the length of the user_agent column.
+const maxUserAgent = 512
+
+// EventRepo handles all database operations related to engagement events.
+type EventRepo struct {
+	db *pgxpool.Pool
+}
+
+// NewEventRepo creates a new instance of EventRepo.
+func NewEventRepo(db *pgxpool.Pool) *EventRepo {
+	return &EventRepo{db: db}
+}
+
+// Create records an event. Events of unknown emails are reported as entities.ErrEmailNotFound.
+func (r *EventRepo) Create(ctx context.Context, e entities.Event) error {
+	userAgent := truncateUserAgent(e.UserAgent)
+
+	tag, err := conn(ctx, r.db).Exec(ctx, `
+		INSERT INTO email_events (email_id, type, url, user_agent)
+		SELECT id, $2, $3, $4
+		FROM emails
+		WHERE id = $1
+	`, e.EmailID, string(e.Type), e.URL, userAgent)
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return entities.ErrEmailNotFound
+	}
+
+	return nil
+}
+
+// truncateUserAgent cuts the user agent to maxUserAgent bytes, stepping back to a rune boundary
+// so that a multi-byte character is not split into invalid UTF-8.
+func truncateUserAgent(s string) string {
+	if len(s) <= maxUserAgent {
+		return s
+	}
+
+	n := maxUserAgent
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+
+	return s[:n]
+}
+
+// Stats aggregates email statuses and engagement events.
+func (r *EventRepo) Stats(ctx context.Context) (entities.Stats, error) {
+	stats := entities.Stats{Statuses: map[entities.Status]int64{}}
+
+	rows, err := conn(ctx, r.db).Query(ctx, `SELECT status, COUNT(*) FROM emails GROUP BY status`)
+	if err != nil {
+		return entities.Stats{}, err
+	}
+
+	var (
+		status string
+		count  int64
+	)
+	_, err = pgx.ForEachRow(rows, []any{&status, &count}, func() error {
+		stats.Statuses[entities.Status(status)] = count
+		return nil
+	})
+	if err != nil {
+		return entities.Stats{}, err
+	}
+
+	err = conn(ctx, r.db).QueryRow(ctx, `
+		SELECT
+			COUNT(*) FILTER (WHERE type = 'open'),
+			COUNT(DISTINCT email_id) FILTER (WHERE type = 'open'),
+			COUNT(*) FILTER (WHERE type = 'click'),
+			COUNT(DISTINCT email_id) FILTER (WHERE type = 'click')
+		FROM email_events
+	`).Scan(&stats.Opens, &stats.UniqueOpens, &stats.Clicks, &stats.UniqueClicks)
+	if err != nil {
+		return entities.Stats{}, err
+	}
+
+	return stats, nil
+}
@@ -0,0 +1,36 @@
+package repos
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestTruncateUserAgent(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want int
+	}{
+		{"short", "Mozilla/5.0", len("Mozilla/5.0")},
+		{"ascii at the limit", strings.Repeat("a", maxUserAgent), maxUserAgent},
+		{"ascii over the limit", strings.Repeat("a", maxUserAgent+1), maxUserAgent},
+		{"rune across the limit", strings.Repeat("a", maxUserAgent-1) + "й", maxUserAgent - 1},
+		{"multi-byte runes", strings.Repeat("世", maxUserAgent), maxUserAgent - maxUserAgent%3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := truncateUserAgent(tt.in)
+			if len(got) != tt.want {
+				t.Errorf("len(truncateUserAgent()) = %d, want %d", len(got), tt.want)
+			}
+			if !utf8.ValidString(got) {
+				t.Errorf("truncateUserAgent() = %q, want valid UTF-8", got)
+			}
+			if !strings.HasPrefix(tt.in, got) {
+				t.Errorf("truncateUserAgent() = %q, want a prefix of the input", got)
+			}
+		})
+	}
+}
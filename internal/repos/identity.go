@@ -0,0 +1,140 @@
+package repos
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/grishkovelli/betera-mailqusrv/internal/entities"
+)
+
+// identityColumns lists the columns scanned into entities.Identity.
+const identityColumns = "id, address, display_name, reply_to, verified, created_at"
+
+// uniqueViolation is the PostgreSQL error code of unique constraint violations.
+const uniqueViolation = "23505"
+
+// IdentityRepo handles all database operations related to sender identities.
+type IdentityRepo struct {
+	db *pgxpool.Pool
+}
+
+// NewIdentityRepo creates a new instance of IdentityRepo.
+func NewIdentityRepo(db *pgxpool.Pool) *IdentityRepo {
+	return &IdentityRepo{db: db}
+}
+
+// Create registers a new unverified identity.
+func (r *IdentityRepo) Create(ctx context.Context, i entities.CreateIdentity) (entities.Identity, error) {
+	rows, err := conn(ctx, r.db).Query(ctx, `
+		INSERT INTO identities (address, display_name, reply_to)
+		VALUES ($1, $2, $3)
+		RETURNING `+identityColumns+`
+	`, strings.ToLower(i.Address), i.DisplayName, i.ReplyTo)
+	if err != nil {
+		return entities.Identity{}, err
+	}
+
+	identity, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[entities.Identity])
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+		return entities.Identity{}, entities.ErrIdentityExists
+	}
+
+	return identity, err
+}
+
+// GetByID retrieves a single identity by its ID.
+func (r *IdentityRepo) GetByID(ctx context.Context, id int) (entities.Identity, error) {
+	rows, err := conn(ctx, r.db).Query(ctx, `
+		SELECT `+identityColumns+`
+		FROM identities
+		WHERE id = $1
+	`, id)
+	if err != nil {
+		return entities.Identity{}, err
+	}
+
+	return collectIdentity(rows)
+}
+
+// List retrieves identities using cursor-based pagination.
+func (r *IdentityRepo) List(ctx context.Context, limit, cursor int) ([]entities.Identity, error) {
+	rows, err := conn(ctx, r.db).Query(ctx, `
+		SELECT `+identityColumns+`
+		FROM identities
+		WHERE id > $1
+		ORDER BY id
+		LIMIT $2
+	`, cursor, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return pgx.CollectRows(rows, pgx.RowToStructByName[entities.Identity])
+}
+
+// Verify marks an identity as verified.
+func (r *IdentityRepo) Verify(ctx context.Context, id int) (entities.Identity, error) {
+	rows, err := conn(ctx, r.db).Query(ctx, `
+		UPDATE identities
+		SET verified = TRUE,
+				updated_at = NOW()
+		WHERE id = $1
+		RETURNING `+identityColumns+`
+	`, id)
+	if err != nil {
+		return entities.Identity{}, err
+	}
+
+	return collectIdentity(rows)
+}
+
+// Delete removes an identity by its ID.
+func (r *IdentityRepo) Delete(ctx context.Context, id int) error {
+	tag, err := conn(ctx, r.db).Exec(ctx, `DELETE FROM identities WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return entities.ErrIdentityNotFound
+	}
+
+	return nil
+}
+
+// FindForAddress retrieves the identity an address may be sent from: the identity of the address
+// itself or of its domain. Verified identities and exact addresses take precedence.
+func (r *IdentityRepo) FindForAddress(ctx context.Context, address string) (entities.Identity, error) {
+	address = strings.ToLower(address)
+	domain := address[strings.LastIndexByte(address, '@')+1:]
+
+	rows, err := conn(ctx, r.db).Query(ctx, `
+		SELECT `+identityColumns+`
+		FROM identities
+		WHERE address = $1 OR address = $2
+		ORDER BY verified DESC, address = $1 DESC
+		LIMIT 1
+	`, address, domain)
+	if err != nil {
+		return entities.Identity{}, err
+	}
+
+	return collectIdentity(rows)
+}
+
+// collectIdentity scans a single identity, translating a missing row into entities.ErrIdentityNotFound.
+func collectIdentity(rows pgx.Rows) (entities.Identity, error) {
+	identity, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[entities.Identity])
+	if errors.Is(err, pgx.ErrNoRows) {
+		return entities.Identity{}, entities.ErrIdentityNotFound
+	}
+
+	return identity, err
+}
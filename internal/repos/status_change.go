@@ -0,0 +1,104 @@
+package repos
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/grishkovelli/betera-mailqusrv/internal/entities"
+)
+
+// StatusChangesChannel is the channel on which the emails trigger announces logged status changes.
+const StatusChangesChannel = "email_status_changes"
+
+// StatusChangeRepo handles all database operations related to the status change log.
+type StatusChangeRepo struct {
+	db *pgxpool.Pool
+}
+
+// NewStatusChangeRepo creates a new instance of StatusChangeRepo.
+func NewStatusChangeRepo(db *pgxpool.Pool) *StatusChangeRepo {
+	return &StatusChangeRepo{db: db}
+}
+
+// List returns up to limit changes matching the filter with an ID greater than afterID, ordered by ID.
+func (r *StatusChangeRepo) List(
+	ctx context.Context,
+	afterID int64,
+	f entities.StatusChangeFilter,
+	limit int,
+) ([]entities.StatusChange, error) {
+	rows, err := conn(ctx, r.db).Query(ctx, `
+		SELECT id, email_id, COALESCE(from_status::text, '') AS from_status, status, created_at
+		FROM email_status_changes
+		WHERE id > $1
+			AND ($2 = 0 OR email_id = $2)
+			AND ($3 = '' OR status::text = $3)
+		ORDER BY id
+		LIMIT $4
+	`, afterID, f.EmailID, string(f.Status), limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return pgx.CollectRows(rows, pgx.RowToStructByName[entities.StatusChange])
+}
+
+// ReplayStart returns the ID to replay changes after for a client that received the change afterID.
+// IDs are taken when a change is logged but changes are announced when their transaction commits,
+// so a change with a lower ID may have been announced after afterID. The replay starts before
+// the changes logged within overlap before afterID, afterID is returned if it is no longer logged.
+func (r *StatusChangeRepo) ReplayStart(ctx context.Context, afterID int64, overlap time.Duration) (int64, error) {
+	var start int64
+	err := conn(ctx, r.db).QueryRow(ctx, `
+		SELECT COALESCE(MIN(id) - 1, $1)
+		FROM email_status_changes
+		WHERE id <= $1
+			AND created_at >= (SELECT created_at FROM email_status_changes WHERE id = $1) - $2 * INTERVAL '1 second'
+	`, afterID, overlap.Seconds()).Scan(&start)
+
+	return start, err
+}
+
+// DeleteBefore removes changes logged before t and returns the number of removed changes.
+func (r *StatusChangeRepo) DeleteBefore(ctx context.Context, t time.Time) (int64, error) {
+	tag, err := conn(ctx, r.db).Exec(ctx, `DELETE FROM email_status_changes WHERE created_at < $1`, t)
+	if err != nil {
+		return 0, err
+	}
+
+	return tag.RowsAffected(), nil
+}
+
+// Listen calls fn with every change announced on StatusChangesChannel until ctx is cancelled
+// or the connection fails. It listens on a dedicated connection taken out of the pool.
+func (r *StatusChangeRepo) Listen(ctx context.Context, fn func(entities.StatusChange)) error {
+	pooled, err := r.db.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	// the session keeps listening, so the connection must not go back to the pool
+	conn := pooled.Hijack()
+	defer conn.Close(context.WithoutCancel(ctx))
+
+	if _, err = conn.Exec(ctx, "LISTEN "+StatusChangesChannel); err != nil {
+		return err
+	}
+
+	for {
+		n, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+
+		var c entities.StatusChange
+		if err = json.Unmarshal([]byte(n.Payload), &c); err != nil {
+			return fmt.Errorf("invalid status change notification: %w", err)
+		}
+		fn(c)
+	}
+}
@@ -0,0 +1,118 @@
+package repos
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/grishkovelli/betera-mailqusrv/internal/entities"
+)
+
+// suppressionColumns lists the columns scanned into entities.Suppression.
+const suppressionColumns = "id, address, reason, expires_at, category, created_at"
+
+// SuppressionRepo handles all database operations related to suppressed addresses.
+type SuppressionRepo struct {
+	db *pgxpool.Pool
+}
+
+// NewSuppressionRepo creates a new instance of SuppressionRepo.
+func NewSuppressionRepo(db *pgxpool.Pool) *SuppressionRepo {
+	return &SuppressionRepo{db: db}
+}
+
+// Upsert suppresses an address for a category, replacing the reason and expiry if it is already suppressed.
+func (r *SuppressionRepo) Upsert(ctx context.Context, s entities.CreateSuppression) (entities.Suppression, error) {
+	rows, err := conn(ctx, r.db).Query(ctx, `
+		INSERT INTO suppressions (address, reason, expires_at, category)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (address, category) DO UPDATE
+		SET reason = EXCLUDED.reason,
+				expires_at = EXCLUDED.expires_at,
+				updated_at = NOW()
+		RETURNING `+suppressionColumns+`
+	`, strings.ToLower(s.Address), string(s.Reason), s.ExpiresAt, s.Category)
+	if err != nil {
+		return entities.Suppression{}, err
+	}
+
+	return pgx.CollectOneRow(rows, pgx.RowToStructByName[entities.Suppression])
+}
+
+// GetByID retrieves a single suppression by its ID.
+func (r *SuppressionRepo) GetByID(ctx context.Context, id int) (entities.Suppression, error) {
+	rows, err := conn(ctx, r.db).Query(ctx, `
+		SELECT `+suppressionColumns+`
+		FROM suppressions
+		WHERE id = $1
+	`, id)
+	if err != nil {
+		return entities.Suppression{}, err
+	}
+
+	s, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[entities.Suppression])
+	if errors.Is(err, pgx.ErrNoRows) {
+		return entities.Suppression{}, entities.ErrSuppressionNotFound
+	}
+
+	return s, err
+}
+
+// List retrieves suppressions using cursor-based pagination.
+func (r *SuppressionRepo) List(ctx context.Context, limit, cursor int) ([]entities.Suppression, error) {
+	rows, err := conn(ctx, r.db).Query(ctx, `
+		SELECT `+suppressionColumns+`
+		FROM suppressions
+		WHERE id > $1
+		ORDER BY id
+		LIMIT $2
+	`, cursor, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return pgx.CollectRows(rows, pgx.RowToStructByName[entities.Suppression])
+}
+
+// Delete removes a suppression by its ID.
+func (r *SuppressionRepo) Delete(ctx context.Context, id int) error {
+	tag, err := conn(ctx, r.db).Exec(ctx, `DELETE FROM suppressions WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return entities.ErrSuppressionNotFound
+	}
+
+	return nil
+}
+
+// FilterSuppressed returns the addresses from the given list that are currently suppressed
+// for the category, either globally or for that category only.
+func (r *SuppressionRepo) FilterSuppressed(ctx context.Context, category string, addresses []string) ([]string, error) {
+	if len(addresses) == 0 {
+		return nil, nil
+	}
+
+	lower := make([]string, len(addresses))
+	for i, a := range addresses {
+		lower[i] = strings.ToLower(a)
+	}
+
+	rows, err := conn(ctx, r.db).Query(ctx, `
+		SELECT DISTINCT address
+		FROM suppressions
+		WHERE address = ANY($1)
+			AND category IN ('', $2)
+			AND (expires_at IS NULL OR expires_at > NOW())
+	`, lower, category)
+	if err != nil {
+		return nil, err
+	}
+
+	return pgx.CollectRows(rows, pgx.RowTo[string])
+}
@@ -0,0 +1,26 @@
+package repos
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type fakeTx struct {
+	pgx.Tx
+}
+
+func TestConn(t *testing.T) {
+	pool := &pgxpool.Pool{}
+
+	if got := conn(context.Background(), pool); got != pool {
+		t.Errorf("conn() outside of a transaction = %v, want the pool", got)
+	}
+
+	tx := &fakeTx{}
+	if got := conn(withTx(context.Background(), tx), pool); got != tx {
+		t.Errorf("conn() within a transaction = %v, want the transaction", got)
+	}
+}
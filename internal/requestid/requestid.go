@@ -0,0 +1,95 @@
+// Package requestid carries the ID of the API request that created an email through contexts,
+// so that access, service and worker log lines of one email can be correlated.
+package requestid
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+)
+
+// Header is the HTTP header the request ID is read from and echoed in.
+const Header = "X-Request-ID"
+
+// LogKey is the log attribute of the request ID.
+const LogKey = "request_id"
+
+// maxLen is the maximum length of request IDs accepted from clients.
+const maxLen = 128
+
+// idSize is the number of random bytes of generated request IDs.
+const idSize = 16
+
+type ctxKey struct{}
+
+// New generates a random request ID.
+func New() string {
+	b := make([]byte, idSize)
+	_, _ = rand.Read(b) // never returns an error
+
+	return hex.EncodeToString(b)
+}
+
+// Valid reports whether a client supplied ID may be used as is. IDs are limited to a safe
+// set of characters so they can be logged and stored without escaping.
+func Valid(id string) bool {
+	if id == "" || len(id) > maxLen {
+		return false
+	}
+
+	for _, c := range id {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '-', c == '_', c == '.', c == ':':
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+// NewContext returns a copy of ctx carrying the request ID. An empty ID leaves ctx unchanged.
+func NewContext(ctx context.Context, id string) context.Context {
+	if id == "" {
+		return ctx
+	}
+
+	return context.WithValue(ctx, ctxKey{}, id)
+}
+
+// FromContext returns the request ID of ctx, or an empty string if there is none.
+func FromContext(ctx context.Context) string {
+	id, _ := ctx.Value(ctxKey{}).(string)
+	return id
+}
+
+// LogHandler adds the request ID of the context to records logged with one.
+type LogHandler struct {
+	slog.Handler
+}
+
+// NewLogHandler wraps h so that records get the request_id attribute.
+func NewLogHandler(h slog.Handler) *LogHandler {
+	return &LogHandler{Handler: h}
+}
+
+// Handle adds the request ID of ctx to the record and passes it on.
+func (h *LogHandler) Handle(ctx context.Context, r slog.Record) error {
+	if id := FromContext(ctx); id != "" {
+		r.AddAttrs(slog.String(LogKey, id))
+	}
+
+	return h.Handler.Handle(ctx, r)
+}
+
+// WithAttrs returns a handler that keeps adding request IDs.
+func (h *LogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &LogHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+// WithGroup returns a handler that keeps adding request IDs.
+func (h *LogHandler) WithGroup(name string) slog.Handler {
+	return &LogHandler{Handler: h.Handler.WithGroup(name)}
+}
@@ -0,0 +1,40 @@
+package requestid
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValid(t *testing.T) {
+	assert.True(t, Valid("4bf92f3577b34da6-a3ce929d0e0e4736"))
+	assert.True(t, Valid("req_1.2:3"))
+	assert.True(t, Valid(New()))
+	assert.False(t, Valid(""))
+	assert.False(t, Valid("id with spaces"))
+	assert.False(t, Valid("id\nforged=1"))
+	assert.False(t, Valid(strings.Repeat("a", maxLen+1)))
+}
+
+func TestContext(t *testing.T) {
+	ctx := NewContext(t.Context(), "abc")
+	assert.Equal(t, "abc", FromContext(ctx))
+	assert.Empty(t, FromContext(t.Context()))
+	assert.Equal(t, t.Context(), NewContext(t.Context(), ""))
+}
+
+func TestLogHandler(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := slog.New(NewLogHandler(slog.NewTextHandler(buf, nil))).With("component", "test")
+
+	logger.InfoContext(NewContext(t.Context(), "abc"), "with id")
+	logger.InfoContext(t.Context(), "without id")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Len(t, lines, 2)
+	assert.Contains(t, lines[0], "component=test request_id=abc")
+	assert.NotContains(t, lines[1], "request_id")
+}
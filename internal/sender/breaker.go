@@ -0,0 +1,104 @@
+package sender
+
+import (
+	"sync"
+	"time"
+)
+
+// Circuit breaker states.
+const (
+	circuitClosed   = "closed"    // Deliveries go through
+	circuitOpen     = "open"      // Deliveries are skipped until the open timeout passes
+	circuitHalfOpen = "half-open" // A single trial delivery decides whether to close or reopen
+)
+
+// breaker is a consecutive failures circuit breaker of a single backend.
+type breaker struct {
+	mu        sync.Mutex
+	state     string
+	failures  int
+	openedAt  time.Time
+	trial     bool
+	threshold int
+	timeout   time.Duration
+	now       func() time.Time
+	onChange  func(from, to string)
+}
+
+func newBreaker(threshold int, timeout time.Duration, onChange func(from, to string)) *breaker {
+	return &breaker{
+		state:     circuitClosed,
+		threshold: threshold,
+		timeout:   timeout,
+		now:       time.Now,
+		onChange:  onChange,
+	}
+}
+
+// allow reports whether a delivery may be attempted. An open circuit turns half-open
+// after the timeout and lets exactly one trial delivery through.
+func (b *breaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case circuitOpen:
+		if b.now().Sub(b.openedAt) < b.timeout {
+			return false
+		}
+		b.setState(circuitHalfOpen)
+		b.trial = true
+		return true
+	case circuitHalfOpen:
+		if b.trial {
+			return false
+		}
+		b.trial = true
+		return true
+	default:
+		return true
+	}
+}
+
+// success records a successful delivery and closes the circuit.
+func (b *breaker) success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures = 0
+	b.trial = false
+	b.setState(circuitClosed)
+}
+
+// failure records a transient delivery failure and opens the circuit once the threshold is reached.
+func (b *breaker) failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	b.trial = false
+	if b.state == circuitHalfOpen || (b.threshold > 0 && b.failures >= b.threshold) {
+		b.openedAt = b.now()
+		b.setState(circuitOpen)
+	}
+}
+
+// currentState returns the current state of the circuit.
+func (b *breaker) currentState() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.state
+}
+
+func (b *breaker) setState(state string) {
+	if b.state == state {
+		return
+	}
+
+	from := b.state
+	b.state = state
+	if b.onChange != nil {
+		b.onChange(from, state)
+	}
+}
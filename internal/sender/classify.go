@@ -0,0 +1,84 @@
+package sender
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// AnyProvider matches errors of every provider in a classification rule.
+const AnyProvider = "*"
+
+// Rule overrides whether delivery errors with a matching code are permanent.
+type Rule struct {
+	Provider  string // Provider that reported the error, AnyProvider matches all of them
+	Code      string // Prefix of the error code, e.g. "5" for all SMTP 5xx replies
+	Permanent bool   // Whether matching errors must not be retried
+}
+
+// matches reports whether the rule applies to the given delivery error.
+func (r Rule) matches(se *Error) bool {
+	return (r.Provider == AnyProvider || r.Provider == se.Provider) && strings.HasPrefix(se.Code, r.Code)
+}
+
+// ParseRules parses permanent and transient rules in the provider:code-prefix format.
+// Transient rules come first, so they can carve exceptions out of broader permanent rules.
+func ParseRules(permanent, transient []string) ([]Rule, error) {
+	rules := make([]Rule, 0, len(permanent)+len(transient))
+	for _, spec := range transient {
+		rule, err := parseRule(spec, false)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	for _, spec := range permanent {
+		rule, err := parseRule(spec, true)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+
+	return rules, nil
+}
+
+func parseRule(spec string, permanent bool) (Rule, error) {
+	provider, code, ok := strings.Cut(strings.TrimSpace(spec), ":")
+	if !ok || provider == "" || code == "" {
+		return Rule{}, fmt.Errorf("invalid classification rule %q", spec)
+	}
+
+	return Rule{Provider: provider, Code: code, Permanent: permanent}, nil
+}
+
+// Classifier decides whether a failed delivery may be retried.
+type Classifier struct {
+	rules []Rule
+}
+
+// NewClassifier creates a classifier that applies the first matching rule
+// and falls back to the provider's own classification.
+func NewClassifier(rules []Rule) *Classifier {
+	return &Classifier{rules: rules}
+}
+
+// Permanent reports whether err is a permanent delivery failure. Cancellations, timeouts,
+// network errors and errors without classification are transient.
+func (c *Classifier) Permanent(err error) bool {
+	var se *Error
+	if !errors.As(err, &se) || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	if c != nil && se.Code != "" {
+		for _, rule := range c.rules {
+			if rule.matches(se) {
+				return rule.Permanent
+			}
+		}
+	}
+
+	return se.Permanent
+}
@@ -0,0 +1,116 @@
+package sender
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClassifier_Permanent(t *testing.T) {
+	rules, err := ParseRules([]string{"*:invalid_address", "sendgrid:403"}, []string{"smtp:552"})
+	require.NoError(t, err)
+	c := NewClassifier(rules)
+
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{
+			name: "smtp 5xx reply",
+			err:  &Error{Provider: SMTP, Code: "550", Permanent: true, Err: errors.New("no such user")},
+			want: true,
+		},
+		{
+			name: "smtp 4xx reply",
+			err:  &Error{Provider: SMTP, Code: "451", Err: errors.New("try again later")},
+		},
+		{
+			name: "transient rule overrides provider",
+			err:  &Error{Provider: SMTP, Code: "552", Permanent: true, Err: errors.New("mailbox full")},
+		},
+		{
+			name: "permanent rule overrides provider",
+			err:  &Error{Provider: SendGrid, Code: "403", Err: errors.New("forbidden")},
+			want: true,
+		},
+		{
+			name: "rule of another provider",
+			err:  &Error{Provider: Mailgun, Code: "403", Err: errors.New("forbidden")},
+		},
+		{
+			name: "any provider rule",
+			err:  &Error{Code: CodeInvalidAddress, Err: ErrInvalidAddress},
+			want: true,
+		},
+		{
+			name: "wrapped error",
+			err:  fmt.Errorf("deliver: %w", &Error{Provider: SES, Code: "400", Permanent: true, Err: errors.New("bad request")}),
+			want: true,
+		},
+		{
+			name: "timeout",
+			err:  &Error{Provider: SendGrid, Permanent: true, Err: context.DeadlineExceeded},
+		},
+		{
+			name: "connection refused",
+			err:  &Error{Provider: SMTP, Err: &net.OpError{Op: "dial", Err: syscall.ECONNREFUSED}},
+		},
+		{
+			name: "unclassified error",
+			err:  errors.New("boom"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, c.Permanent(tt.err))
+		})
+	}
+}
+
+func TestClassifier_Nil(t *testing.T) {
+	var c *Classifier
+
+	assert.True(t, c.Permanent(&Error{Provider: SMTP, Code: "550", Permanent: true}))
+	assert.False(t, c.Permanent(&Error{Provider: SMTP, Code: "451"}))
+}
+
+func TestParseRules(t *testing.T) {
+	tests := []struct {
+		permanent []string
+		transient []string
+		want      []Rule
+		wantErr   bool
+	}{
+		{want: []Rule{}},
+		{
+			permanent: []string{"smtp:5", "*:invalid_address"},
+			transient: []string{" smtp:552 "},
+			want: []Rule{
+				{Provider: "smtp", Code: "552"},
+				{Provider: "smtp", Code: "5", Permanent: true},
+				{Provider: "*", Code: "invalid_address", Permanent: true},
+			},
+		},
+		{permanent: []string{"smtp"}, wantErr: true},
+		{permanent: []string{":550"}, wantErr: true},
+		{transient: []string{"smtp:"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseRules(tt.permanent, tt.transient)
+		if tt.wantErr {
+			assert.Error(t, err)
+			continue
+		}
+		require.NoError(t, err)
+		assert.Equal(t, tt.want, got)
+	}
+}
@@ -0,0 +1,19 @@
+package sender
+
+import (
+	"context"
+)
+
+// FakeSender is a local sender that accepts messages without sending them. It is only registered
+// when SENDER_ROUTES lists it, accepted messages are discarded.
+type FakeSender struct{}
+
+// NewFakeSender creates a fake sender that accepts every message.
+func NewFakeSender() *FakeSender {
+	return &FakeSender{}
+}
+
+// Send discards the message.
+func (s *FakeSender) Send(_ context.Context, _ Message) error {
+	return nil
+}
@@ -0,0 +1,47 @@
+package sender
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// maxErrorBody limits how much of an error response is kept in the error message.
+const maxErrorBody = 512
+
+// doRequest sends an API request and converts non-2xx responses into an *Error.
+// permanent decides from the response whether the failure is permanent.
+func doRequest(client *http.Client, req *http.Request, provider string, permanent func(*http.Response) bool) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return &Error{Provider: provider, Err: err}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusOK && resp.StatusCode < http.StatusMultipleChoices {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+
+	return &Error{
+		Provider:  provider,
+		Code:      strconv.Itoa(resp.StatusCode),
+		Permanent: permanent(resp),
+		Err:       fmt.Errorf("unexpected status %d: %s", resp.StatusCode, strings.TrimSpace(string(body))),
+	}
+}
+
+// permanentStatus treats malformed and oversized requests as permanent failures.
+// Auth errors, rate limits and server errors are transient, since they do not depend on the message.
+func permanentStatus(resp *http.Response) bool {
+	switch resp.StatusCode {
+	case http.StatusBadRequest, http.StatusRequestEntityTooLarge, http.StatusUnprocessableEntity:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,53 @@
+package sender
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"strings"
+
+	"github.com/grishkovelli/betera-mailqusrv/config"
+)
+
+// MailgunSender delivers messages through the Mailgun MIME messages API.
+type MailgunSender struct {
+	cfg    config.Mailgun
+	client *http.Client
+}
+
+// NewMailgunSender creates a Mailgun sender.
+func NewMailgunSender(cfg config.Mailgun, client *http.Client) *MailgunSender {
+	return &MailgunSender{cfg: cfg, client: client}
+}
+
+// Send uploads the rendered message, so headers and signatures reach the recipient unchanged.
+func (s *MailgunSender) Send(ctx context.Context, msg Message) error {
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+
+	if err := w.WriteField("to", msg.To); err != nil {
+		return &Error{Provider: Mailgun, Permanent: true, Err: err}
+	}
+	part, err := w.CreateFormFile("message", "message.mime")
+	if err != nil {
+		return &Error{Provider: Mailgun, Permanent: true, Err: err}
+	}
+	if _, err = part.Write(msg.Bytes()); err != nil {
+		return &Error{Provider: Mailgun, Permanent: true, Err: err}
+	}
+	if err = w.Close(); err != nil {
+		return &Error{Provider: Mailgun, Permanent: true, Err: err}
+	}
+
+	url := fmt.Sprintf("%s/v3/%s/messages.mime", strings.TrimRight(s.cfg.URL, "/"), s.cfg.Domain)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, &body)
+	if err != nil {
+		return &Error{Provider: Mailgun, Permanent: true, Err: err}
+	}
+	req.SetBasicAuth("api", s.cfg.APIKey)
+	req.Header.Set("Content-Type", w.FormDataContentType())
+
+	return doRequest(s.client, req, Mailgun, permanentStatus)
+}
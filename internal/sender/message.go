@@ -0,0 +1,106 @@
+package sender
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/textproto"
+	"time"
+)
+
+// Header is a single message header. Headers are kept in a slice to preserve their order.
+type Header struct {
+	Key   string
+	Value string
+}
+
+// Message is an outgoing email ready for delivery.
+type Message struct {
+	Provider   string    // Provider to deliver through, empty means the default one
+	MessageID  string    // Message-ID header value
+	From       string    // From address
+	ReturnPath string    // Envelope sender, receives bounces
+	To         string    // Recipient address
+	Subject    string    // Subject
+	Body       string    // Plain text body
+	HTML       string    // HTML body, sent as an alternative to the plain text body when set
+	Date       time.Time // Date header value
+	Headers    []Header  // Additional headers
+	Signature  string    // DKIM-Signature header value, rendered before all other headers
+}
+
+// Bytes renders the message in RFC 5322 format with a quoted-printable encoded body.
+// Messages with an HTML body are rendered as multipart/alternative.
+func (m Message) Bytes() []byte {
+	var buf bytes.Buffer
+
+	for _, h := range m.headerList() {
+		fmt.Fprintf(&buf, "%s: %s\r\n", h.Key, h.Value)
+	}
+	buf.WriteString("\r\n")
+
+	if m.HTML == "" {
+		writeQuotedPrintable(&buf, m.Body)
+		return buf.Bytes()
+	}
+
+	mw := multipart.NewWriter(&buf)
+	_ = mw.SetBoundary(m.boundary())
+	for _, part := range []struct{ contentType, body string }{
+		{"text/plain; charset=utf-8", m.Body},
+		{"text/html; charset=utf-8", m.HTML},
+	} {
+		w, _ := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		writeQuotedPrintable(w, part.body)
+	}
+	_ = mw.Close()
+
+	return buf.Bytes()
+}
+
+// boundary derives the multipart boundary from the message, so rendering is repeatable.
+func (m Message) boundary() string {
+	sum := sha256.Sum256([]byte(m.MessageID + "\x00" + m.Body + "\x00" + m.HTML))
+	return "mailqu-" + hex.EncodeToString(sum[:12])
+}
+
+func writeQuotedPrintable(w io.Writer, body string) {
+	qp := quotedprintable.NewWriter(w)
+	_, _ = qp.Write([]byte(body))
+	_ = qp.Close()
+}
+
+// headerList returns all headers of the rendered message.
+func (m Message) headerList() []Header {
+	headers := []Header{
+		{"From", m.From},
+		{"To", m.To},
+		{"Subject", mime.QEncoding.Encode("utf-8", m.Subject)},
+		{"Date", m.Date.Format(time.RFC1123Z)},
+		{"Message-ID", m.MessageID},
+		{"MIME-Version", "1.0"},
+	}
+	if m.HTML == "" {
+		headers = append(headers,
+			Header{"Content-Type", "text/plain; charset=utf-8"},
+			Header{"Content-Transfer-Encoding", "quoted-printable"},
+		)
+	} else {
+		contentType := fmt.Sprintf("multipart/alternative; boundary=%q", m.boundary())
+		headers = append(headers, Header{"Content-Type", contentType})
+	}
+
+	if m.Signature != "" {
+		headers = append([]Header{{"DKIM-Signature", m.Signature}}, headers...)
+	}
+
+	return append(headers, m.Headers...)
+}
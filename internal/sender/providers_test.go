@@ -0,0 +1,251 @@
+package sender
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grishkovelli/betera-mailqusrv/config"
+)
+
+func testMessage() Message {
+	return Message{
+		MessageID:  "<abc@mailqu.local>",
+		From:       "Mailqu <noreply@mailqu.local>",
+		ReturnPath: "bounces+1@mailqu.local",
+		To:         "user@example.com",
+		Subject:    "Hello",
+		Body:       "Hello, world!",
+		Date:       time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC),
+	}
+}
+
+// sendGridAPI mimics the SendGrid mail send endpoint. Recipients on the "invalid" domain are rejected.
+func sendGridAPI(t *testing.T, status int) *httptest.Server {
+	t.Helper()
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v3/mail/send" || r.Header.Get("Authorization") != "Bearer key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		var req sendGridRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil ||
+			strings.HasSuffix(req.Personalizations[0].To[0].Email, "@invalid") {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"errors":[{"message":"Does not contain a valid address.","field":"to"}]}`))
+			return
+		}
+
+		w.WriteHeader(status)
+	}))
+}
+
+func TestSendGridSender_Send(t *testing.T) {
+	tests := []struct {
+		name          string
+		status        int
+		apiKey        string
+		to            string
+		wantErr       bool
+		wantPermanent bool
+		wantCode      string
+	}{
+		{name: "accepted", status: http.StatusAccepted, apiKey: "key", to: "user@example.com"},
+		{name: "invalid address", status: http.StatusAccepted, apiKey: "key", to: "user@invalid",
+			wantErr: true, wantPermanent: true, wantCode: "400"},
+		{name: "unauthorized", status: http.StatusAccepted, apiKey: "wrong", to: "user@example.com",
+			wantErr: true, wantCode: "401"},
+		{name: "rate limited", status: http.StatusTooManyRequests, apiKey: "key", to: "user@example.com",
+			wantErr: true, wantCode: "429"},
+		{name: "server error", status: http.StatusServiceUnavailable, apiKey: "key", to: "user@example.com",
+			wantErr: true, wantCode: "503"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := sendGridAPI(t, tt.status)
+			defer srv.Close()
+
+			s := NewSendGridSender(config.SendGrid{URL: srv.URL, APIKey: tt.apiKey}, srv.Client())
+			msg := testMessage()
+			msg.To = tt.to
+
+			err := s.Send(t.Context(), msg)
+			assertSendError(t, err, tt.wantErr, tt.wantPermanent, tt.wantCode)
+		})
+	}
+}
+
+// mailgunAPI mimics the Mailgun MIME messages endpoint and records the uploaded message.
+func mailgunAPI(t *testing.T, status int, raw *string) *httptest.Server {
+	t.Helper()
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, ok := r.BasicAuth()
+		if r.URL.Path != "/v3/mg.example.com/messages.mime" || !ok || user != "api" || pass != "key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		_, params, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		require.NoError(t, err)
+		form, err := multipart.NewReader(r.Body, params["boundary"]).ReadForm(1 << 20)
+		require.NoError(t, err)
+
+		if form.Value["to"][0] == "" || len(form.File["message"]) == 0 {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"message":"'to' parameter is missing"}`))
+			return
+		}
+
+		f, err := form.File["message"][0].Open()
+		require.NoError(t, err)
+		b, _ := io.ReadAll(f)
+		*raw = string(b)
+
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(`{"id":"<20240101@mg.example.com>","message":"Queued. Thank you."}`))
+	}))
+}
+
+func TestMailgunSender_Send(t *testing.T) {
+	tests := []struct {
+		name          string
+		status        int
+		to            string
+		wantErr       bool
+		wantPermanent bool
+		wantCode      string
+	}{
+		{name: "queued", status: http.StatusOK, to: "user@example.com"},
+		{name: "missing recipient", status: http.StatusOK, to: "",
+			wantErr: true, wantPermanent: true, wantCode: "400"},
+		{name: "server error", status: http.StatusInternalServerError, to: "user@example.com",
+			wantErr: true, wantCode: "500"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var raw string
+			srv := mailgunAPI(t, tt.status, &raw)
+			defer srv.Close()
+
+			s := NewMailgunSender(config.Mailgun{URL: srv.URL, Domain: "mg.example.com", APIKey: "key"}, srv.Client())
+			msg := testMessage()
+			msg.To = tt.to
+
+			err := s.Send(t.Context(), msg)
+			assertSendError(t, err, tt.wantErr, tt.wantPermanent, tt.wantCode)
+			if !tt.wantErr {
+				assert.Contains(t, raw, "Message-ID: <abc@mailqu.local>\r\n")
+			}
+		})
+	}
+}
+
+// sesAPI mimics the SES v2 SendEmail endpoint, responding with the given error type if set.
+func sesAPI(t *testing.T, status int, errType string) *httptest.Server {
+	t.Helper()
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v2/email/outbound-emails" ||
+			!strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKID/") {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		var req sesRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Contains(t, string(req.Content.Raw.Data), "Subject: Hello\r\n")
+
+		if errType != "" {
+			w.Header().Set("X-Amzn-Errortype", errType+":http://internal.amazon.com/coral/com.amazonaws.sesv2/")
+			w.WriteHeader(status)
+			_, _ = w.Write([]byte(`{"message":"` + errType + `"}`))
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"MessageId":"0100018c"}`))
+	}))
+}
+
+func TestSESSender_Send(t *testing.T) {
+	tests := []struct {
+		name          string
+		status        int
+		errType       string
+		wantErr       bool
+		wantPermanent bool
+	}{
+		{name: "sent", status: http.StatusOK},
+		{name: "message rejected", status: http.StatusBadRequest, errType: "MessageRejected",
+			wantErr: true, wantPermanent: true},
+		{name: "throttled", status: http.StatusTooManyRequests, errType: "TooManyRequestsException",
+			wantErr: true},
+		{name: "sending paused", status: http.StatusBadRequest, errType: "SendingPausedException",
+			wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := sesAPI(t, tt.status, tt.errType)
+			defer srv.Close()
+
+			s := NewSESSender(config.SES{URL: srv.URL, Region: "eu-west-1", AccessKeyID: "AKID"}, srv.Client())
+
+			err := s.Send(t.Context(), testMessage())
+			assertSendError(t, err, tt.wantErr, tt.wantPermanent, "")
+		})
+	}
+}
+
+func TestSignV4(t *testing.T) {
+	// get-vanilla from the AWS Signature Version 4 test suite
+	req, err := http.NewRequest(http.MethodGet, "https://example.amazonaws.com/", nil)
+	require.NoError(t, err)
+
+	signV4(req, nil, "us-east-1", "service", "AKIDEXAMPLE",
+		"wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY", time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC))
+
+	assert.Equal(t,
+		"AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, "+
+			"SignedHeaders=host;x-amz-date, "+
+			"Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31",
+		req.Header.Get("Authorization"))
+}
+
+func TestIsPermanent(t *testing.T) {
+	assert.False(t, IsPermanent(errors.New("plain error")))
+	assert.False(t, IsPermanent(&Error{Provider: Fake}))
+	assert.True(t, IsPermanent(&Error{Provider: Fake, Permanent: true}))
+	assert.False(t, IsPermanent(context.DeadlineExceeded))
+}
+
+func assertSendError(t *testing.T, err error, wantErr, wantPermanent bool, wantCode string) {
+	t.Helper()
+
+	if !wantErr {
+		require.NoError(t, err)
+		return
+	}
+
+	var se *Error
+	require.ErrorAs(t, err, &se)
+	assert.Equal(t, wantPermanent, se.Permanent)
+	if wantCode != "" {
+		assert.Equal(t, wantCode, se.Code)
+	}
+}
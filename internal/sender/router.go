@@ -0,0 +1,227 @@
+package sender
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"net/mail"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grishkovelli/betera-mailqusrv/internal/metrics"
+)
+
+// Router errors.
+var (
+	ErrCircuitOpen = errors.New("circuit open")
+	ErrNoBackends  = errors.New("no backends available")
+)
+
+// Route places a configured provider into the failover order. Backends with a lower priority
+// are tried first, backends with the same priority share traffic according to their weights.
+type Route struct {
+	Provider string
+	Priority int
+	Weight   int
+}
+
+// BreakerConfig configures the per-backend circuit breakers.
+type BreakerConfig struct {
+	Threshold int           // Consecutive transient failures that open the circuit, 0 disables the breaker
+	Timeout   time.Duration // How long an open circuit skips the backend before a trial delivery
+}
+
+// Router delivers messages through weighted and prioritized backends and fails over
+// to the next backend on transient errors.
+type Router struct {
+	senders    map[string]Sender
+	breakers   map[string]*breaker
+	groups     [][]Route
+	classifier *Classifier
+	logger     *slog.Logger
+	states     *expvar.Map
+	counts     *expvar.Map
+}
+
+// NewRouter creates a router over the given senders. Every route must reference a sender.
+// The classifier decides which failures are permanent, nil keeps the providers' own classification.
+func NewRouter(
+	senders map[string]Sender,
+	routes []Route,
+	classifier *Classifier,
+	cfg BreakerConfig,
+	logger *slog.Logger,
+) (*Router, error) {
+	r := &Router{
+		senders:    senders,
+		breakers:   make(map[string]*breaker, len(senders)),
+		classifier: classifier,
+		logger:     logger,
+		states:     metrics.Map("sender_circuit_state"),
+		counts:     metrics.Map("sender_deliveries"),
+	}
+
+	for name := range senders {
+		r.breakers[name] = newBreaker(cfg.Threshold, cfg.Timeout, r.stateChanged(name))
+		r.states.Set(name, stringVar(circuitClosed))
+	}
+
+	routes = slices.Clone(routes)
+	slices.SortStableFunc(routes, func(a, b Route) int { return a.Priority - b.Priority })
+	for i, route := range routes {
+		if _, ok := senders[route.Provider]; !ok {
+			return nil, fmt.Errorf("%w: route to %s", ErrUnknownProvider, route.Provider)
+		}
+		if i == 0 || routes[i-1].Priority != route.Priority {
+			r.groups = append(r.groups, nil)
+		}
+		r.groups[len(r.groups)-1] = append(r.groups[len(r.groups)-1], route)
+	}
+
+	return r, nil
+}
+
+// ParseRoutes parses routes in the name[:priority[:weight]] format. Priority defaults to 0, weight to 1.
+func ParseRoutes(specs []string) ([]Route, error) {
+	routes := make([]Route, 0, len(specs))
+	for _, spec := range specs {
+		parts := strings.Split(strings.TrimSpace(spec), ":")
+		if parts[0] == "" || len(parts) > 3 {
+			return nil, fmt.Errorf("invalid route %q", spec)
+		}
+
+		route := Route{Provider: parts[0], Weight: 1}
+		var err error
+		if len(parts) > 1 {
+			if route.Priority, err = strconv.Atoi(parts[1]); err != nil {
+				return nil, fmt.Errorf("invalid route priority %q: %w", spec, err)
+			}
+		}
+		if len(parts) > 2 {
+			if route.Weight, err = strconv.Atoi(parts[2]); err != nil || route.Weight <= 0 {
+				return nil, fmt.Errorf("invalid route weight %q", spec)
+			}
+		}
+
+		routes = append(routes, route)
+	}
+
+	return routes, nil
+}
+
+// Deliver sends the message and reports which provider accepted or rejected it.
+// Messages with an explicit provider are only sent through that provider.
+func (r *Router) Deliver(ctx context.Context, msg Message) Result {
+	if _, err := mail.ParseAddress(msg.To); err != nil {
+		return r.result("", &Error{
+			Code:      CodeInvalidAddress,
+			Permanent: true,
+			Err:       fmt.Errorf("%w: %w", ErrInvalidAddress, err),
+		})
+	}
+
+	if msg.Provider != "" {
+		if _, ok := r.senders[msg.Provider]; !ok {
+			return r.result(msg.Provider, &Error{Provider: msg.Provider, Permanent: true, Err: ErrUnknownProvider})
+		}
+		return r.result(msg.Provider, r.try(ctx, msg.Provider, msg))
+	}
+
+	err := error(&Error{Err: ErrNoBackends})
+	for _, group := range r.groups {
+		for _, route := range weightedOrder(group) {
+			err = r.try(ctx, route.Provider, msg)
+			if err == nil {
+				return Result{Provider: route.Provider}
+			}
+			if r.classifier.Permanent(err) || ctx.Err() != nil {
+				return r.result(route.Provider, err)
+			}
+
+			r.logger.WarnContext(ctx, "failing over", "provider", route.Provider, "error", err)
+		}
+	}
+
+	return r.result("", err)
+}
+
+// Send delivers the message, see Deliver.
+func (r *Router) Send(ctx context.Context, msg Message) error {
+	return r.Deliver(ctx, msg).Err
+}
+
+// result classifies the outcome of a delivery through the provider.
+func (r *Router) result(provider string, err error) Result {
+	return Result{Provider: provider, Permanent: err != nil && r.classifier.Permanent(err), Err: err}
+}
+
+// try delivers the message through a single backend guarded by its circuit breaker.
+func (r *Router) try(ctx context.Context, provider string, msg Message) error {
+	b := r.breakers[provider]
+	if !b.allow() {
+		r.counts.Add(provider+".skipped", 1)
+		return &Error{Provider: provider, Err: ErrCircuitOpen}
+	}
+
+	err := r.senders[provider].Send(ctx, msg)
+	switch {
+	case err == nil:
+		b.success()
+		r.counts.Add(provider+".sent", 1)
+	case r.classifier.Permanent(err):
+		// the backend is healthy, it rejected the message itself
+		b.success()
+		r.counts.Add(provider+".rejected", 1)
+	default:
+		b.failure()
+		r.counts.Add(provider+".failed", 1)
+	}
+
+	return err
+}
+
+// stateChanged logs and publishes circuit state changes of a backend.
+func (r *Router) stateChanged(provider string) func(from, to string) {
+	return func(from, to string) {
+		r.states.Set(provider, stringVar(to))
+		r.logger.Warn("circuit state change", "provider", provider, "from", from, "to", to)
+	}
+}
+
+// weightedOrder returns the routes in a random order where heavier routes tend to come first.
+func weightedOrder(routes []Route) []Route {
+	if len(routes) < 2 { //nolint:mnd // nothing to shuffle
+		return routes
+	}
+
+	rest := slices.Clone(routes)
+	ordered := make([]Route, 0, len(routes))
+	for len(rest) > 0 {
+		total := 0
+		for _, route := range rest {
+			total += route.Weight
+		}
+
+		n := rand.IntN(total) //nolint:gosec // traffic distribution does not need a secure source
+		i := 0
+		for ; n >= rest[i].Weight; i++ {
+			n -= rest[i].Weight
+		}
+
+		ordered = append(ordered, rest[i])
+		rest = slices.Delete(rest, i, i+1)
+	}
+
+	return ordered
+}
+
+func stringVar(s string) *expvar.String {
+	v := new(expvar.String)
+	v.Set(s)
+	return v
+}
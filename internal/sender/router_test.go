@@ -0,0 +1,298 @@
+package sender
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grishkovelli/betera-mailqusrv/config"
+)
+
+func transientFailure(msg Message) error {
+	return &Error{Provider: "test", Code: "421", Err: errors.New("service not available")}
+}
+
+func permanentFailure(msg Message) error {
+	return &Error{Provider: "test", Code: "550", Permanent: true, Err: errors.New("mailbox unavailable")}
+}
+
+// recordingSender keeps the messages it accepts and rejects those for which fail returns an error.
+type recordingSender struct {
+	mu   sync.Mutex
+	fail func(msg Message) error
+	sent []Message
+}
+
+func newRecordingSender(fail func(msg Message) error) *recordingSender {
+	return &recordingSender{fail: fail}
+}
+
+func (s *recordingSender) Send(_ context.Context, msg Message) error {
+	if s.fail != nil {
+		if err := s.fail(msg); err != nil {
+			return err
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sent = append(s.sent, msg)
+
+	return nil
+}
+
+// Sent returns all messages accepted so far.
+func (s *recordingSender) Sent() []Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]Message(nil), s.sent...)
+}
+
+func newTestRouter(t *testing.T, senders map[string]Sender, routes []Route, cfg BreakerConfig) *Router {
+	t.Helper()
+
+	r, err := NewRouter(senders, routes, nil, cfg, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	require.NoError(t, err)
+	return r
+}
+
+func TestRouter_Deliver(t *testing.T) {
+	tests := []struct {
+		name         string
+		primary      func(Message) error
+		provider     string
+		wantProvider string
+		wantErr      bool
+		wantSent     [2]int
+	}{
+		{
+			name:         "primary accepts",
+			wantProvider: "primary",
+			wantSent:     [2]int{1, 0},
+		},
+		{
+			name:         "fails over on transient error",
+			primary:      transientFailure,
+			wantProvider: "backup",
+			wantSent:     [2]int{0, 1},
+		},
+		{
+			name:         "no failover on permanent error",
+			primary:      permanentFailure,
+			wantProvider: "primary",
+			wantErr:      true,
+		},
+		{
+			name:         "explicit provider",
+			provider:     "backup",
+			wantProvider: "backup",
+			wantSent:     [2]int{0, 1},
+		},
+		{
+			name:     "explicit provider is not failed over",
+			primary:  transientFailure,
+			provider: "primary",
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			primary := newRecordingSender(tt.primary)
+			backup := newRecordingSender(nil)
+			r := newTestRouter(t,
+				map[string]Sender{"primary": primary, "backup": backup},
+				[]Route{{Provider: "backup", Priority: 2, Weight: 1}, {Provider: "primary", Priority: 1, Weight: 1}},
+				BreakerConfig{},
+			)
+
+			msg := testMessage()
+			msg.Provider = tt.provider
+			res := r.Deliver(t.Context(), msg)
+
+			assert.Equal(t, tt.wantErr, res.Err != nil)
+			if !tt.wantErr || res.Permanent {
+				assert.Equal(t, tt.wantProvider, res.Provider)
+			}
+			assert.Len(t, primary.Sent(), tt.wantSent[0])
+			assert.Len(t, backup.Sent(), tt.wantSent[1])
+		})
+	}
+}
+
+func TestRouter_UnknownProvider(t *testing.T) {
+	r := newTestRouter(t, map[string]Sender{Fake: NewFakeSender()}, nil, BreakerConfig{})
+
+	msg := testMessage()
+	msg.Provider = SES
+	res := r.Deliver(t.Context(), msg)
+	require.ErrorIs(t, res.Err, ErrUnknownProvider)
+	assert.True(t, res.Permanent)
+
+	msg.Provider = ""
+	res = r.Deliver(t.Context(), msg)
+	require.ErrorIs(t, res.Err, ErrNoBackends)
+	assert.False(t, res.Permanent)
+
+	_, err := NewRouter(map[string]Sender{}, []Route{{Provider: SMTP}}, nil, BreakerConfig{}, slog.Default())
+	require.ErrorIs(t, err, ErrUnknownProvider)
+}
+
+func TestNewFromConfig(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	_, err := NewFromConfig(config.Sender{}, logger)
+	require.ErrorIs(t, err, ErrNoBackends)
+
+	r, err := NewFromConfig(config.Sender{Routes: []string{Fake}}, logger)
+	require.NoError(t, err)
+	res := r.Deliver(t.Context(), testMessage())
+	require.NoError(t, res.Err)
+	assert.Equal(t, Fake, res.Provider)
+
+	// the fake sender is not registered unless a route lists it
+	r, err = NewFromConfig(config.Sender{Routes: []string{SMTP}, SMTP: config.SMTP{Addr: "localhost:25"}}, logger)
+	require.NoError(t, err)
+	msg := testMessage()
+	msg.Provider = Fake
+	res = r.Deliver(t.Context(), msg)
+	require.ErrorIs(t, res.Err, ErrUnknownProvider)
+	assert.True(t, res.Permanent)
+}
+
+func TestRouter_Classification(t *testing.T) {
+	primary := newRecordingSender(func(Message) error {
+		return &Error{Provider: "primary", Code: "552", Permanent: true, Err: errors.New("mailbox full")}
+	})
+	backup := newRecordingSender(nil)
+	routes := []Route{{Provider: "primary", Priority: 1, Weight: 1}, {Provider: "backup", Priority: 2, Weight: 1}}
+
+	r, err := NewRouter(
+		map[string]Sender{"primary": primary, "backup": backup},
+		routes,
+		NewClassifier([]Rule{{Provider: "primary", Code: "552"}}),
+		BreakerConfig{},
+		slog.New(slog.NewTextHandler(io.Discard, nil)),
+	)
+	require.NoError(t, err)
+
+	// the rule overrides the provider's classification, so the message fails over
+	res := r.Deliver(t.Context(), testMessage())
+	require.NoError(t, res.Err)
+	assert.Equal(t, "backup", res.Provider)
+
+	msg := testMessage()
+	msg.To = "not an address"
+	res = r.Deliver(t.Context(), msg)
+	require.ErrorIs(t, res.Err, ErrInvalidAddress)
+	assert.True(t, res.Permanent)
+	assert.Len(t, backup.Sent(), 1)
+}
+
+func TestRouter_CircuitBreaker(t *testing.T) {
+	fail := true
+	primary := newRecordingSender(func(msg Message) error {
+		if fail {
+			return transientFailure(msg)
+		}
+		return nil
+	})
+	backup := newRecordingSender(nil)
+
+	r := newTestRouter(t,
+		map[string]Sender{"primary": primary, "backup": backup},
+		[]Route{{Provider: "primary", Priority: 1, Weight: 1}, {Provider: "backup", Priority: 2, Weight: 1}},
+		BreakerConfig{Threshold: 2, Timeout: time.Minute},
+	)
+	now := time.Now()
+	r.breakers["primary"].now = func() time.Time { return now }
+
+	for range 3 {
+		res := r.Deliver(t.Context(), testMessage())
+		require.NoError(t, res.Err)
+		assert.Equal(t, "backup", res.Provider)
+	}
+	assert.Equal(t, circuitOpen, r.breakers["primary"].currentState())
+
+	// the backend recovers, but the circuit stays open until the timeout passes
+	fail = false
+	assert.Equal(t, "backup", r.Deliver(t.Context(), testMessage()).Provider)
+
+	now = now.Add(time.Minute)
+	res := r.Deliver(t.Context(), testMessage())
+	require.NoError(t, res.Err)
+	assert.Equal(t, "primary", res.Provider)
+	assert.Equal(t, circuitClosed, r.breakers["primary"].currentState())
+}
+
+func TestBreaker_HalfOpenFailure(t *testing.T) {
+	var changes []string
+	b := newBreaker(1, time.Second, func(from, to string) { changes = append(changes, from+"->"+to) })
+	now := time.Now()
+	b.now = func() time.Time { return now }
+
+	b.failure()
+	assert.False(t, b.allow())
+
+	now = now.Add(time.Second)
+	assert.True(t, b.allow())
+	assert.False(t, b.allow(), "only one trial delivery is allowed")
+
+	b.failure()
+	assert.Equal(t, circuitOpen, b.currentState())
+	assert.Equal(t, []string{"closed->open", "open->half-open", "half-open->open"}, changes)
+}
+
+func TestWeightedOrder(t *testing.T) {
+	routes := []Route{{Provider: "heavy", Weight: 3}, {Provider: "light", Weight: 1}}
+
+	first := map[string]int{}
+	for range 4000 {
+		order := weightedOrder(routes)
+		require.Len(t, order, 2)
+		first[order[0].Provider]++
+	}
+
+	assert.InDelta(t, 3000, first["heavy"], 200)
+	assert.InDelta(t, 1000, first["light"], 200)
+}
+
+func TestParseRoutes(t *testing.T) {
+	tests := []struct {
+		specs   []string
+		want    []Route
+		wantErr bool
+	}{
+		{specs: []string{"smtp"}, want: []Route{{Provider: "smtp", Weight: 1}}},
+		{
+			specs: []string{"smtp:1:80", "sendgrid:1:20", "ses:2"},
+			want: []Route{
+				{Provider: "smtp", Priority: 1, Weight: 80},
+				{Provider: "sendgrid", Priority: 1, Weight: 20},
+				{Provider: "ses", Priority: 2, Weight: 1},
+			},
+		},
+		{specs: []string{"smtp:x"}, wantErr: true},
+		{specs: []string{"smtp:1:0"}, wantErr: true},
+		{specs: []string{":1"}, wantErr: true},
+		{specs: []string{"smtp:1:1:1"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseRoutes(tt.specs)
+		if tt.wantErr {
+			assert.Error(t, err, tt.specs)
+			continue
+		}
+		require.NoError(t, err)
+		assert.Equal(t, tt.want, got)
+	}
+}
@@ -0,0 +1,114 @@
+package sender
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/grishkovelli/betera-mailqusrv/config"
+)
+
+// Provider names.
+const (
+	Fake     = "fake"
+	SMTP     = "smtp"
+	SendGrid = "sendgrid"
+	Mailgun  = "mailgun"
+	SES      = "ses"
+)
+
+// CodeInvalidAddress is the error code of messages rejected before delivery because of a malformed recipient.
+const CodeInvalidAddress = "invalid_address"
+
+// Sender errors.
+var (
+	ErrInvalidAddress  = errors.New("invalid recipient address")
+	ErrUnknownProvider = errors.New("unknown provider")
+)
+
+// Sender delivers a single message.
+type Sender interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// Error is a delivery error annotated with whether retrying the delivery may succeed.
+type Error struct {
+	Provider  string // Provider that reported the error
+	Code      string // Provider specific error code, e.g. an SMTP reply code or an HTTP status
+	Permanent bool   // Whether the message can never be delivered as is
+	Err       error  // Underlying error
+}
+
+func (e *Error) Error() string {
+	kind := "transient"
+	if e.Permanent {
+		kind = "permanent"
+	}
+
+	return fmt.Sprintf("%s: %s error %s: %v", e.Provider, kind, e.Code, e.Err)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Result is the outcome of a delivery.
+type Result struct {
+	Provider  string // Provider that accepted or rejected the message, empty when no provider was reached
+	Permanent bool   // Whether the failure must not be retried
+	Err       error  // Delivery error, nil when the message was accepted
+}
+
+// IsPermanent reports whether err is a delivery error that must not be retried.
+// Errors without classification are treated as transient.
+func IsPermanent(err error) bool {
+	var se *Error
+	return errors.As(err, &se) && se.Permanent
+}
+
+// NewFromConfig creates a router over every provider that has credentials configured. The fake sender
+// is only registered when a route lists it, so that messages are never discarded by default.
+func NewFromConfig(cfg config.Sender, logger *slog.Logger) (*Router, error) {
+	routes, err := ParseRoutes(cfg.Routes)
+	if err != nil {
+		return nil, err
+	}
+	if len(routes) == 0 {
+		return nil, fmt.Errorf("%w: SENDER_ROUTES is empty", ErrNoBackends)
+	}
+
+	timeout := time.Duration(cfg.Timeout) * time.Second
+	client := &http.Client{Timeout: timeout}
+
+	senders := map[string]Sender{}
+	if slices.ContainsFunc(routes, func(r Route) bool { return r.Provider == Fake }) {
+		logger.Warn("the fake sender is enabled, messages routed to it are discarded")
+		senders[Fake] = NewFakeSender()
+	}
+	if cfg.SMTP.Addr != "" {
+		senders[SMTP] = NewSMTPSender(cfg.SMTP, timeout)
+	}
+	if cfg.SendGrid.APIKey != "" {
+		senders[SendGrid] = NewSendGridSender(cfg.SendGrid, client)
+	}
+	if cfg.Mailgun.APIKey != "" {
+		senders[Mailgun] = NewMailgunSender(cfg.Mailgun, client)
+	}
+	if cfg.SES.AccessKeyID != "" {
+		senders[SES] = NewSESSender(cfg.SES, client)
+	}
+
+	rules, err := ParseRules(cfg.PermanentErrors, cfg.TransientErrors)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewRouter(senders, routes, NewClassifier(rules), BreakerConfig{
+		Threshold: cfg.BreakerThreshold,
+		Timeout:   time.Duration(cfg.BreakerTimeout) * time.Second,
+	}, logger)
+}
@@ -0,0 +1,88 @@
+package sender
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/mail"
+	"strings"
+
+	"github.com/grishkovelli/betera-mailqusrv/config"
+)
+
+// SendGridSender delivers messages through the SendGrid v3 mail send API.
+type SendGridSender struct {
+	cfg    config.SendGrid
+	client *http.Client
+}
+
+type sendGridAddress struct {
+	Email string `json:"email"`
+	Name  string `json:"name,omitempty"`
+}
+
+type sendGridPersonalization struct {
+	To []sendGridAddress `json:"to"`
+}
+
+type sendGridRequest struct {
+	Personalizations []sendGridPersonalization `json:"personalizations"`
+	From             sendGridAddress           `json:"from"`
+	Subject          string                    `json:"subject"`
+	Content          []sendGridContent         `json:"content"`
+	Headers          map[string]string         `json:"headers,omitempty"`
+}
+
+type sendGridContent struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+// NewSendGridSender creates a SendGrid sender.
+func NewSendGridSender(cfg config.SendGrid, client *http.Client) *SendGridSender {
+	return &SendGridSender{cfg: cfg, client: client}
+}
+
+// Send delivers the message. SendGrid renders the message itself, so additional headers are passed as is.
+func (s *SendGridSender) Send(ctx context.Context, msg Message) error {
+	payload := sendGridRequest{
+		Personalizations: []sendGridPersonalization{{To: []sendGridAddress{{Email: msg.To}}}},
+		From:             parseAddress(msg.From),
+		Subject:          msg.Subject,
+		Content:          []sendGridContent{{Type: "text/plain", Value: msg.Body}},
+		Headers:          map[string]string{"Message-ID": msg.MessageID},
+	}
+	if msg.HTML != "" {
+		payload.Content = append(payload.Content, sendGridContent{Type: "text/html", Value: msg.HTML})
+	}
+	for _, h := range msg.Headers {
+		payload.Headers[h.Key] = h.Value
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return &Error{Provider: SendGrid, Permanent: true, Err: err}
+	}
+
+	req, err := http.NewRequestWithContext(
+		ctx, http.MethodPost, strings.TrimRight(s.cfg.URL, "/")+"/v3/mail/send", bytes.NewReader(body),
+	)
+	if err != nil {
+		return &Error{Provider: SendGrid, Permanent: true, Err: err}
+	}
+	req.Header.Set("Authorization", "Bearer "+s.cfg.APIKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	return doRequest(s.client, req, SendGrid, permanentStatus)
+}
+
+// parseAddress splits an address with an optional display name.
+func parseAddress(s string) sendGridAddress {
+	addr, err := mail.ParseAddress(s)
+	if err != nil {
+		return sendGridAddress{Email: s}
+	}
+
+	return sendGridAddress{Email: addr.Address, Name: addr.Name}
+}
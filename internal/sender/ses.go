@@ -0,0 +1,162 @@
+package sender
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/grishkovelli/betera-mailqusrv/config"
+)
+
+// sesService is the SigV4 service name of the SES API.
+const sesService = "ses"
+
+// SESSender delivers raw messages through the SES v2 SendEmail API.
+type SESSender struct {
+	cfg    config.SES
+	client *http.Client
+	now    func() time.Time
+}
+
+type sesRequest struct {
+	FromEmailAddress string         `json:"FromEmailAddress"`
+	Destination      sesDestination `json:"Destination"`
+	Content          sesContent     `json:"Content"`
+}
+
+type sesDestination struct {
+	ToAddresses []string `json:"ToAddresses"`
+}
+
+type sesContent struct {
+	Raw sesRaw `json:"Raw"`
+}
+
+type sesRaw struct {
+	Data []byte `json:"Data"` // encoded as base64 by encoding/json
+}
+
+// NewSESSender creates an SES sender.
+func NewSESSender(cfg config.SES, client *http.Client) *SESSender {
+	if cfg.URL == "" {
+		cfg.URL = fmt.Sprintf("https://email.%s.amazonaws.com", cfg.Region)
+	}
+
+	return &SESSender{cfg: cfg, client: client, now: time.Now}
+}
+
+// Send uploads the rendered message as raw content.
+func (s *SESSender) Send(ctx context.Context, msg Message) error {
+	body, err := json.Marshal(sesRequest{
+		FromEmailAddress: msg.From,
+		Destination:      sesDestination{ToAddresses: []string{msg.To}},
+		Content:          sesContent{Raw: sesRaw{Data: msg.Bytes()}},
+	})
+	if err != nil {
+		return &Error{Provider: SES, Permanent: true, Err: err}
+	}
+
+	req, err := http.NewRequestWithContext(
+		ctx, http.MethodPost, strings.TrimRight(s.cfg.URL, "/")+"/v2/email/outbound-emails", bytes.NewReader(body),
+	)
+	if err != nil {
+		return &Error{Provider: SES, Permanent: true, Err: err}
+	}
+	req.Header.Set("Content-Type", "application/json")
+	signV4(req, body, s.cfg.Region, sesService, s.cfg.AccessKeyID, s.cfg.SecretAccessKey, s.now())
+
+	return doRequest(s.client, req, SES, permanentSES)
+}
+
+// permanentSES classifies SES errors by their error type. Throttling and account level
+// errors are transient, rejected messages and unverified identities are permanent.
+func permanentSES(resp *http.Response) bool {
+	errType, _, _ := strings.Cut(resp.Header.Get("X-Amzn-Errortype"), ":")
+
+	switch errType {
+	case "MessageRejected", "MailFromDomainNotVerifiedException", "BadRequestException", "NotFoundException":
+		return true
+	case "TooManyRequestsException", "LimitExceededException", "SendingPausedException", "AccountSuspendedException":
+		return false
+	default:
+		return permanentStatus(resp)
+	}
+}
+
+// signV4 signs the request with AWS Signature Version 4.
+func signV4(req *http.Request, body []byte, region, service, accessKey, secretKey string, now time.Time) {
+	amzDate := now.UTC().Format("20060102T150405Z")
+	date := amzDate[:8]
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	if req.Host == "" {
+		req.Host = req.URL.Host
+	}
+
+	headers := map[string]string{
+		"host":       req.Host,
+		"x-amz-date": amzDate,
+	}
+	if ct := req.Header.Get("Content-Type"); ct != "" {
+		headers["content-type"] = ct
+	}
+
+	names := make([]string, 0, len(headers))
+	for k := range headers {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, k := range names {
+		canonicalHeaders.WriteString(k + ":" + strings.TrimSpace(headers[k]) + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		path,
+		req.URL.Query().Encode(),
+		canonicalHeaders.String(),
+		signedHeaders,
+		hashHex(body),
+	}, "\n")
+
+	scope := strings.Join([]string{date, region, service, "aws4_request"}, "/")
+	stringToSign := strings.Join([]string{"AWS4-HMAC-SHA256", amzDate, scope, hashHex([]byte(canonicalRequest))}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+secretKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		accessKey, scope, signedHeaders, signature,
+	))
+}
+
+func hashHex(b []byte) string {
+	h := sha256.Sum256(b)
+	return hex.EncodeToString(h[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
@@ -0,0 +1,112 @@
+package sender
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"time"
+
+	"github.com/grishkovelli/betera-mailqusrv/config"
+)
+
+// SMTPSender delivers messages through an SMTP relay.
+type SMTPSender struct {
+	cfg     config.SMTP
+	timeout time.Duration
+}
+
+// NewSMTPSender creates a sender for the configured SMTP relay. A zero timeout disables the per-message timeout.
+func NewSMTPSender(cfg config.SMTP, timeout time.Duration) *SMTPSender {
+	return &SMTPSender{cfg: cfg, timeout: timeout}
+}
+
+// Send delivers the message, using STARTTLS and PLAIN auth when available.
+func (s *SMTPSender) Send(ctx context.Context, msg Message) error {
+	if s.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.timeout)
+		defer cancel()
+	}
+
+	if err := s.send(ctx, msg); err != nil {
+		return classifySMTP(err)
+	}
+
+	return nil
+}
+
+func (s *SMTPSender) send(ctx context.Context, msg Message) error {
+	host, _, err := net.SplitHostPort(s.cfg.Addr)
+	if err != nil {
+		return err
+	}
+
+	conn, err := (&net.Dialer{}).DialContext(ctx, "tcp", s.cfg.Addr)
+	if err != nil {
+		return err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	c, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err = c.StartTLS(&tls.Config{ServerName: host, MinVersion: tls.VersionTLS12}); err != nil {
+			return err
+		}
+	}
+	if s.cfg.Username != "" {
+		if err = c.Auth(smtp.PlainAuth("", s.cfg.Username, s.cfg.Password, host)); err != nil {
+			return err
+		}
+	}
+
+	from := msg.ReturnPath
+	if from == "" {
+		from = msg.From
+	}
+	if err = c.Mail(from); err != nil {
+		return err
+	}
+	if err = c.Rcpt(msg.To); err != nil {
+		return err
+	}
+
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err = w.Write(msg.Bytes()); err != nil {
+		return err
+	}
+	if err = w.Close(); err != nil {
+		return err
+	}
+
+	return c.Quit()
+}
+
+// classifySMTP marks 5xx replies as permanent. 4xx replies and network errors are transient.
+func classifySMTP(err error) error {
+	var tpErr *textproto.Error
+	if errors.As(err, &tpErr) {
+		return &Error{
+			Provider:  SMTP,
+			Code:      strconv.Itoa(tpErr.Code),
+			Permanent: tpErr.Code >= 500,
+			Err:       err,
+		}
+	}
+
+	return &Error{Provider: SMTP, Err: err}
+}
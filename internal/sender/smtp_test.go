@@ -0,0 +1,159 @@
+package sender
+
+import (
+	"bufio"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grishkovelli/betera-mailqusrv/config"
+)
+
+// smtpServer is a minimal SMTP server that answers RCPT with rcptReply and records the DATA payload.
+func smtpServer(t *testing.T, rcptReply string, data chan<- string) string {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { ln.Close() })
+
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		r := bufio.NewReader(conn)
+		reply := func(s string) { _, _ = conn.Write([]byte(s + "\r\n")) }
+
+		reply("220 localhost ESMTP")
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+
+			switch cmd := strings.ToUpper(strings.Fields(line)[0]); cmd {
+			case "EHLO", "HELO":
+				reply("250 localhost")
+			case "MAIL":
+				reply("250 OK")
+			case "RCPT":
+				reply(rcptReply)
+			case "DATA":
+				reply("354 End data with <CR><LF>.<CR><LF>")
+				var sb strings.Builder
+				for {
+					l, err := r.ReadString('\n')
+					if err != nil || l == ".\r\n" {
+						break
+					}
+					sb.WriteString(l)
+				}
+				data <- sb.String()
+				reply("250 OK: queued")
+			case "QUIT":
+				reply("221 Bye")
+				return
+			default:
+				reply("502 Command not implemented")
+			}
+		}
+	}()
+
+	return ln.Addr().String()
+}
+
+func TestSMTPSender_Send(t *testing.T) {
+	tests := []struct {
+		name          string
+		rcptReply     string
+		wantErr       bool
+		wantPermanent bool
+		wantCode      string
+	}{
+		{name: "delivered", rcptReply: "250 OK"},
+		{name: "mailbox unavailable", rcptReply: "550 5.1.1 No such user",
+			wantErr: true, wantPermanent: true, wantCode: "550"},
+		{name: "greylisted", rcptReply: "451 4.7.1 Try again later",
+			wantErr: true, wantCode: "451"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := make(chan string, 1)
+			addr := smtpServer(t, tt.rcptReply, data)
+
+			err := NewSMTPSender(config.SMTP{Addr: addr}, time.Second).Send(t.Context(), testMessage())
+			assertSendError(t, err, tt.wantErr, tt.wantPermanent, tt.wantCode)
+
+			if !tt.wantErr {
+				assert.Contains(t, <-data, "Message-ID: <abc@mailqu.local>\r\n")
+			}
+		})
+	}
+}
+
+func TestSMTPSender_ConnectionRefused(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := ln.Addr().String()
+	ln.Close()
+
+	err = NewSMTPSender(config.SMTP{Addr: addr}, time.Second).Send(t.Context(), testMessage())
+	assertSendError(t, err, true, false, "")
+}
+
+func TestMessage_Bytes(t *testing.T) {
+	msg := testMessage()
+	msg.Subject = "Привет"
+	msg.Headers = []Header{{"X-Campaign", "welcome"}}
+
+	want := "From: Mailqu <noreply@mailqu.local>\r\n" +
+		"To: user@example.com\r\n" +
+		"Subject: =?utf-8?q?=D0=9F=D1=80=D0=B8=D0=B2=D0=B5=D1=82?=\r\n" +
+		"Date: Mon, 01 Jan 2024 10:00:00 +0000\r\n" +
+		"Message-ID: <abc@mailqu.local>\r\n" +
+		"MIME-Version: 1.0\r\n" +
+		"Content-Type: text/plain; charset=utf-8\r\n" +
+		"Content-Transfer-Encoding: quoted-printable\r\n" +
+		"X-Campaign: welcome\r\n" +
+		"\r\n" +
+		"Hello, world!"
+
+	assert.Equal(t, want, string(msg.Bytes()))
+}
+
+func TestMessage_BytesHTML(t *testing.T) {
+	msg := testMessage()
+	msg.HTML = "<p>Hello, world!</p>"
+
+	boundary := msg.boundary()
+	want := "From: Mailqu <noreply@mailqu.local>\r\n" +
+		"To: user@example.com\r\n" +
+		"Subject: Hello\r\n" +
+		"Date: Mon, 01 Jan 2024 10:00:00 +0000\r\n" +
+		"Message-ID: <abc@mailqu.local>\r\n" +
+		"MIME-Version: 1.0\r\n" +
+		"Content-Type: multipart/alternative; boundary=\"" + boundary + "\"\r\n" +
+		"\r\n" +
+		"--" + boundary + "\r\n" +
+		"Content-Transfer-Encoding: quoted-printable\r\n" +
+		"Content-Type: text/plain; charset=utf-8\r\n" +
+		"\r\n" +
+		"Hello, world!\r\n" +
+		"--" + boundary + "\r\n" +
+		"Content-Transfer-Encoding: quoted-printable\r\n" +
+		"Content-Type: text/html; charset=utf-8\r\n" +
+		"\r\n" +
+		"<p>Hello, world!</p>\r\n" +
+		"--" + boundary + "--\r\n"
+
+	assert.Equal(t, want, string(msg.Bytes()))
+	assert.Equal(t, boundary, msg.boundary(), "boundary is stable")
+}
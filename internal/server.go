@@ -3,28 +3,59 @@ package server
 import (
 	"context"
 	"errors"
+	"expvar"
 	"fmt"
+	"io"
+	"log"
 	"log/slog"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
+	"google.golang.org/grpc"
 
 	"github.com/grishkovelli/betera-mailqusrv/config"
+	"github.com/grishkovelli/betera-mailqusrv/internal/address"
+	"github.com/grishkovelli/betera-mailqusrv/internal/admin"
+	"github.com/grishkovelli/betera-mailqusrv/internal/dkim"
+	"github.com/grishkovelli/betera-mailqusrv/internal/grpcapi"
 	"github.com/grishkovelli/betera-mailqusrv/internal/handlers"
+	"github.com/grishkovelli/betera-mailqusrv/internal/metrics"
 	"github.com/grishkovelli/betera-mailqusrv/internal/repos"
+	"github.com/grishkovelli/betera-mailqusrv/internal/requestid"
+	"github.com/grishkovelli/betera-mailqusrv/internal/sender"
 	"github.com/grishkovelli/betera-mailqusrv/internal/services"
+	"github.com/grishkovelli/betera-mailqusrv/internal/signing"
+	"github.com/grishkovelli/betera-mailqusrv/internal/tracing"
+	"github.com/grishkovelli/betera-mailqusrv/internal/tracking"
 	"github.com/grishkovelli/betera-mailqusrv/internal/worker"
 	"github.com/grishkovelli/betera-mailqusrv/pkg/postgres"
 )
 
+// apiVersion prefixes the routes of the current API version.
+const apiVersion = "/v1"
+
+// stuckTimeoutFactor multiplies the longest time an email waits for its delivery into the derived stuck timeout.
+const stuckTimeoutFactor = 2
+
+// Configuration errors.
+var (
+	errLogFormat    = errors.New("unknown log format")
+	errStuckTimeout = errors.New("stuck timeout is too short")
+)
+
 // Run initializes and starts the server with database connection, worker pool, and HTTP server. It handles graceful shutdown on system signals.
 func Run() {
 	cfg := config.NewConfig()
-	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	logger, err := newLogger(cfg.Log, os.Stdout)
+	if err != nil {
+		log.Fatalf("failed to create logger: %v", err)
+	}
 
 	dbConn, err := postgres.NewPgxPool(cfg.DB)
 	if err != nil {
@@ -36,53 +67,343 @@ func Run() {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	wp := newWorkerPool(cfg.Worker, dbConn, logger)
+	shutdownTracing, err := tracing.Setup(ctx, cfg.Tracing)
+	if err != nil {
+		logger.Error("failed to set up tracing", "error", err)
+		os.Exit(1)
+	}
+
+	wp, err := newWorkerPool(cfg, dbConn, logger)
+	if err != nil {
+		logger.Error("failed to create worker pool", "error", err)
+		os.Exit(1)
+	}
 	go wp.Run(ctx)
 
-	s := newServer(cfg.Server, dbConn, logger)
+	s := newServer(ctx, cfg, dbConn, logger)
 	go func() {
 		logger.Info("server is running", "port", cfg.Server.Port)
+		if cfg.Server.APIToken == "" {
+			logger.Warn("the HTTP and gRPC APIs are not authenticated, set SERVER_API_TOKEN to require a bearer token")
+		}
 		if err = s.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
 			logger.Error("failed to start server", "error", err)
 			os.Exit(1)
 		}
 	}()
 
+	var gs *grpc.Server
+	if cfg.GRPC.Port != "" {
+		gs = newGRPCServer(cfg, dbConn, logger)
+		go func() {
+			logger.Info("grpc server is running", "port", cfg.GRPC.Port)
+			if err := serveGRPC(gs, cfg.GRPC.Port); err != nil {
+				logger.Error("failed to start grpc server", "error", err)
+				os.Exit(1)
+			}
+		}()
+	}
+
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 
-	if err = s.Shutdown(ctx); err != nil {
+	shutdownCtx, stop := context.WithTimeout(context.Background(), seconds(cfg.Server.ShutdownTimeout))
+	defer stop()
+
+	if err = s.Shutdown(shutdownCtx); err != nil {
 		logger.Error("server shutdown", "error", err)
 	}
+	if gs != nil {
+		stopGRPC(shutdownCtx, gs)
+	}
+	// abort requests that outlived the shutdown timeout along with the worker pool
+	cancel()
+
+	if err = shutdownTracing(shutdownCtx); err != nil {
+		logger.Error("tracing shutdown", "error", err)
+	}
 
 	logger.Info("server shutdown complete.")
 }
 
+// newLogger creates the application logger in the configured format and level.
+// Log lines of requests and deliveries carry their request ID.
+func newLogger(cfg config.Log, w io.Writer) (*slog.Logger, error) {
+	opts := &slog.HandlerOptions{Level: cfg.Level}
+
+	var h slog.Handler
+	switch cfg.Format {
+	case config.LogJSON:
+		h = slog.NewJSONHandler(w, opts)
+	case config.LogText:
+		h = slog.NewTextHandler(w, opts)
+	default:
+		return nil, fmt.Errorf("%w: %q", errLogFormat, cfg.Format)
+	}
+
+	return slog.New(requestid.NewLogHandler(h)), nil
+}
+
 // newMux sets up and returns the HTTP router with all application endpoints configured.
-func newMux(cfg config.Server, dbConn *pgxpool.Pool) *http.ServeMux {
+func newMux(
+	cfg config.Config,
+	dbConn *pgxpool.Pool,
+	statusChanges *services.StatusChangeService,
+	logger *slog.Logger,
+) *http.ServeMux {
 	mux := http.NewServeMux()
 
 	emailRepo := repos.NewEmailRepo(dbConn)
-	emailSrv := services.NewEmailService(emailRepo)
-	emailHdr := handlers.NewEmailHandler(cfg, emailSrv)
+	suppressionRepo := repos.NewSuppressionRepo(dbConn)
+	identityRepo := repos.NewIdentityRepo(dbConn)
 
-	mux.HandleFunc("GET /emails", emailHdr.List)
-	mux.HandleFunc("POST /send-email", emailHdr.Send)
+	emailSrv := newEmailService(cfg, dbConn, logger)
+	emailHdr := handlers.NewEmailHandler(cfg.Server, emailSrv)
+
+	suppressionSrv := services.NewSuppressionService(suppressionRepo)
+	suppressionHdr := handlers.NewSuppressionHandler(cfg.Server, suppressionSrv)
+
+	identitySrv := services.NewIdentityService(identityRepo)
+	identityHdr := handlers.NewIdentityHandler(cfg.Server, identitySrv)
+
+	tracker := newTracker(cfg.Links)
+
+	eventSrv := services.NewEventService(tracker, repos.NewEventRepo(dbConn))
+	eventHdr := handlers.NewEventHandler(eventSrv)
+
+	unsubscribeSrv := services.NewUnsubscribeService(tracker, emailRepo, suppressionRepo)
+	unsubscribeHdr := handlers.NewUnsubscribeHandler(unsubscribeSrv)
+
+	bounceSrv := services.NewBounceService(cfg.Mail, emailRepo, suppressionRepo)
+	bounceHdr := handlers.NewBounceHandler(cfg.Server, bounceSrv)
+
+	statusChangeHdr := handlers.NewStatusChangeHandler(cfg.SSE, statusChanges)
+
+	handle := apiRoute(mux, cfg.Server.APIToken, seconds(cfg.Server.RequestTimeout))
+	handleBulk := apiRoute(mux, cfg.Server.APIToken, seconds(cfg.Server.BulkTimeout))
+	// event streams stay open until the client disconnects
+	handleStream := apiRoute(mux, cfg.Server.APIToken, 0)
+	// the API description and bounce ingestion, which has its own secret, do not need the API token
+	handlePublic := route(mux, apiVersion, seconds(cfg.Server.RequestTimeout))
+	// links in sent emails are not part of the versioned API
+	handleLink := route(mux, "", seconds(cfg.Server.RequestTimeout))
+
+	handlePublic("GET /openapi.json", handlers.OpenAPI)
+
+	handle("GET /emails", emailHdr.List)
+	handle("GET /emails/{id}", emailHdr.Get)
+	handleStream("GET /emails/events", statusChangeHdr.Stream)
+	handle("POST /send-email", emailHdr.Send)
+	handleBulk("POST /send-email/batch", emailHdr.SendBatch)
+	handle("POST /emails/{id}/cancel", emailHdr.Cancel)
+	handle("POST /emails/{id}/retry", emailHdr.Retry)
+	handleBulk("POST /emails/cancel", emailHdr.BulkCancel)
+	handleBulk("POST /emails/retry", emailHdr.BulkRetry)
+
+	handle("GET /suppressions", suppressionHdr.List)
+	handle("POST /suppressions", suppressionHdr.Create)
+	handle("GET /suppressions/{id}", suppressionHdr.Get)
+	handle("DELETE /suppressions/{id}", suppressionHdr.Delete)
+
+	handle("GET /identities", identityHdr.List)
+	handle("POST /identities", identityHdr.Create)
+	handle("GET /identities/{id}", identityHdr.Get)
+	handle("DELETE /identities/{id}", identityHdr.Delete)
+	handle("POST /identities/{id}/verify", identityHdr.Verify)
+
+	// notifications name the addresses to suppress, so only the mail pipeline holding the secret may post them
+	if cfg.Mail.BounceSecret != "" {
+		ingest := withBearerToken(cfg.Mail.BounceSecret)(http.HandlerFunc(bounceHdr.Ingest))
+		handlePublic("POST /bounces", ingest.ServeHTTP)
+	}
+
+	handleLink("GET "+tracking.OpenPath+"{token}", eventHdr.Open)
+	handleLink("GET "+tracking.ClickPath+"{token}", eventHdr.Click)
+	handle("GET /stats", eventHdr.Stats)
+
+	handleLink("POST "+tracking.UnsubscribePath+"{token}", unsubscribeHdr.Unsubscribe)
+
+	// the dashboard is served with its own copy of the API routes it uses, behind the admin credentials
+	if cfg.Admin.Password != "" {
+		handleAdmin := adminRoute(mux, cfg.Admin, seconds(cfg.Server.RequestTimeout))
+
+		handleAdmin("GET /admin/", http.StripPrefix("/admin", admin.Handler()).ServeHTTP)
+		handleAdmin("GET "+admin.APIPrefix+"/stats", eventHdr.Stats)
+		handleAdmin("GET "+admin.APIPrefix+"/emails", emailHdr.List)
+		handleAdmin("GET "+admin.APIPrefix+"/emails/{id}", emailHdr.Get)
+		handleAdmin("POST "+admin.APIPrefix+"/emails/{id}/cancel", emailHdr.Cancel)
+		handleAdmin("POST "+admin.APIPrefix+"/emails/{id}/retry", emailHdr.Retry)
+
+		// process variables include the command line and memory statistics, they are not public
+		handleAdmin("GET /debug/vars", expvar.Handler().ServeHTTP)
+	}
 
 	return mux
 }
 
+// newEmailService creates the email service shared by the HTTP and gRPC APIs.
+func newEmailService(cfg config.Config, dbConn *pgxpool.Pool, logger *slog.Logger) *services.EmailService {
+	return services.NewEmailService(
+		cfg,
+		repos.NewEmailRepo(dbConn),
+		repos.NewSuppressionRepo(dbConn),
+		repos.NewIdentityRepo(dbConn),
+		address.New(cfg.Validation, net.DefaultResolver, logger),
+	)
+}
+
+// newGRPCServer creates the gRPC server of the email service.
+func newGRPCServer(cfg config.Config, dbConn *pgxpool.Pool, logger *slog.Logger) *grpc.Server {
+	emails := grpcapi.NewEmailServer(cfg, newEmailService(cfg, dbConn, logger))
+
+	return grpcapi.NewServer(emails, cfg.Server.APIToken, logger, metrics.Map("panics"))
+}
+
+// serveGRPC serves gs on the given port until it is stopped.
+func serveGRPC(gs *grpc.Server, port string) error {
+	lis, err := net.Listen("tcp", ":"+port)
+	if err != nil {
+		return err
+	}
+
+	return gs.Serve(lis)
+}
+
+// stopGRPC waits for in-flight calls until ctx is done and then closes the remaining ones,
+// which keeps long running watch streams from blocking the shutdown.
+func stopGRPC(ctx context.Context, gs *grpc.Server) {
+	stopped := make(chan struct{})
+	go func() {
+		gs.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		gs.Stop()
+	}
+}
+
+// route returns a function that registers traced handlers whose requests are cancelled after the timeout.
+// Patterns are registered under the version prefix and, for compatibility, without it.
+func route(mux *http.ServeMux, version string, timeout time.Duration) func(pattern string, h http.HandlerFunc) {
+	return func(pattern string, h http.HandlerFunc) {
+		patterns := []string{pattern}
+		if version != "" {
+			method, path, _ := strings.Cut(pattern, " ")
+			patterns = append(patterns, method+" "+version+path)
+		}
+
+		for _, p := range patterns {
+			mux.Handle(p, withSpan(p)(withTimeout(timeout)(h)))
+		}
+	}
+}
+
+// apiRoute returns a function that registers versioned routes like route, requiring the API token
+// as a bearer token if one is configured.
+func apiRoute(mux *http.ServeMux, token string, timeout time.Duration) func(pattern string, h http.HandlerFunc) {
+	handle := route(mux, apiVersion, timeout)
+	if token == "" {
+		return handle
+	}
+	auth := withBearerToken(token)
+
+	return func(pattern string, h http.HandlerFunc) {
+		handle(pattern, auth(h).ServeHTTP)
+	}
+}
+
+// adminRoute returns a function that registers routes like route, requiring the admin credentials.
+func adminRoute(mux *http.ServeMux, cfg config.Admin, timeout time.Duration) func(pattern string, h http.HandlerFunc) {
+	handle := route(mux, "", timeout)
+	auth := withAdminAuth(cfg)
+
+	return func(pattern string, h http.HandlerFunc) {
+		handle(pattern, auth(h).ServeHTTP)
+	}
+}
+
+// seconds converts a number of seconds from the configuration into a duration.
+func seconds(n int) time.Duration {
+	return time.Duration(n) * time.Second
+}
+
 // newWorkerPool creates and returns a new worker pool instance with the given configuration.
-func newWorkerPool(c config.Worker, d *pgxpool.Pool, l *slog.Logger) *worker.Pool {
-	return worker.NewPool(c, repos.NewEmailRepo(d), l)
+func newWorkerPool(c config.Config, d *pgxpool.Pool, l *slog.Logger) (*worker.Pool, error) {
+	router, err := sender.NewFromConfig(c.Sender, l)
+	if err != nil {
+		return nil, err
+	}
+
+	keyring, err := dkim.LoadKeyring(c.DKIM.Keys)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.Worker.StuckTimeout, err = stuckTimeout(c.Worker, c.Sender); err != nil {
+		return nil, err
+	}
+
+	return worker.NewPool(
+		c.Worker,
+		c.Mail,
+		repos.NewEmailRepo(d),
+		repos.NewSuppressionRepo(d),
+		router,
+		keyring,
+		newTracker(c.Links),
+		l,
+	), nil
+}
+
+// stuckTimeout returns the seconds an email may stay in processing before the stuck check requeues it.
+// The last email of a batch waits for the deliveries of all emails before it, each of which may time out
+// on every route, so a configured timeout must exceed that or emails still being delivered would be sent
+// twice. Without a configured timeout twice that time is used.
+func stuckTimeout(w config.Worker, s config.Sender) (int, error) {
+	longest := w.BatchSize * s.Timeout * max(len(s.Routes), 1)
+	switch {
+	case w.StuckTimeout == 0:
+		return stuckTimeoutFactor * longest, nil
+	case w.StuckTimeout <= longest:
+		return 0, fmt.Errorf("%w: WORKER_STUCK_TIMEOUT must exceed %d seconds, "+
+			"the batch size times the delivery timeout times the number of routes", errStuckTimeout, longest)
+	}
+
+	return w.StuckTimeout, nil
+}
+
+// newTracker creates the link tracker, tracking is disabled when no link secret is configured.
+func newTracker(cfg config.Links) *tracking.Tracker {
+	if cfg.Secret == "" {
+		return nil
+	}
+
+	return tracking.New(cfg.BaseURL, signing.New(cfg.Secret))
 }
 
 // newServer creates and returns a new HTTP server with the given configuration.
-func newServer(cfg config.Server, dbConn *pgxpool.Pool, logger *slog.Logger) *http.Server {
-	return &http.Server{
-		Addr:              fmt.Sprintf(":%v", cfg.Port),
-		Handler:           loggingAccess(logger)(newMux(cfg, dbConn)),
-		ReadHeaderTimeout: time.Duration(cfg.ReadHeaderTimeout) * time.Second,
+// Request contexts derive from ctx, so cancelling it aborts in-flight requests.
+func newServer(ctx context.Context, cfg config.Config, dbConn *pgxpool.Pool, logger *slog.Logger) *http.Server {
+	statusChanges := services.NewStatusChangeService(cfg.SSE, repos.NewStatusChangeRepo(dbConn), logger)
+	go statusChanges.Run(ctx)
+
+	handler := withRecover(logger, metrics.Map("panics"))(newMux(cfg, dbConn, statusChanges, logger))
+
+	s := &http.Server{
+		Addr:              fmt.Sprintf(":%v", cfg.Server.Port),
+		Handler:           withRequestID(loggingAccess(logger, cfg.Log.RedactParams)(handler)),
+		ReadHeaderTimeout: seconds(cfg.Server.ReadHeaderTimeout),
+		ReadTimeout:       seconds(cfg.Server.ReadTimeout),
+		WriteTimeout:      seconds(cfg.Server.WriteTimeout),
+		IdleTimeout:       seconds(cfg.Server.IdleTimeout),
+		BaseContext:       func(net.Listener) context.Context { return ctx },
 	}
+	// end event streams on shutdown, clients reconnect to another replica with Last-Event-ID
+	s.RegisterOnShutdown(statusChanges.Close)
+
+	return s
 }
@@ -0,0 +1,183 @@
+package server
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grishkovelli/betera-mailqusrv/config"
+)
+
+func TestNewLogger(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     config.Log
+		want    string
+		wantErr error
+	}{
+		{name: "json", cfg: config.Log{Format: config.LogJSON, Level: slog.LevelDebug}, want: `"level":"DEBUG"`},
+		{name: "text", cfg: config.Log{Format: config.LogText, Level: slog.LevelDebug}, want: "level=DEBUG"},
+		{name: "level", cfg: config.Log{Format: config.LogText, Level: slog.LevelInfo}},
+		{name: "unknown format", cfg: config.Log{Format: "xml"}, wantErr: errLogFormat},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			logger, err := newLogger(tt.cfg, buf)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			logger.Debug("debug")
+			if got := buf.String(); !strings.Contains(got, tt.want) || (tt.want == "") != (got == "") {
+				t.Errorf("output = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStuckTimeout(t *testing.T) {
+	snd := config.Sender{Routes: []string{"smtp", "sendgrid:1"}, Timeout: 10}
+
+	tests := []struct {
+		name    string
+		worker  config.Worker
+		sender  config.Sender
+		want    int
+		wantErr error
+	}{
+		{name: "derived", worker: config.Worker{BatchSize: 10}, sender: snd, want: 400},
+		{name: "without routes", worker: config.Worker{BatchSize: 10}, sender: config.Sender{Timeout: 10}, want: 200},
+		{name: "configured", worker: config.Worker{BatchSize: 10, StuckTimeout: 201}, sender: snd, want: 201},
+		{
+			name:    "too short",
+			worker:  config.Worker{BatchSize: 10, StuckTimeout: 200},
+			sender:  snd,
+			wantErr: errStuckTimeout,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := stuckTimeout(tt.worker, tt.sender)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("stuckTimeout() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRoute(t *testing.T) {
+	mux := http.NewServeMux()
+	ok := func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusNoContent) }
+	route(mux, apiVersion, time.Second)("GET /emails", ok)
+	route(mux, "", time.Second)("GET /t/o/{token}", ok)
+
+	tests := []struct {
+		target string
+		want   int
+	}{
+		{target: "/v1/emails", want: http.StatusNoContent},
+		{target: "/emails", want: http.StatusNoContent},
+		{target: "/t/o/abc", want: http.StatusNoContent},
+		{target: "/v1/t/o/abc", want: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.target, nil))
+
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d", w.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewMux_DebugVars(t *testing.T) {
+	tests := []struct {
+		name  string
+		admin config.Admin
+		user  string
+		pass  string
+		want  int
+	}{
+		{name: "admin credentials", admin: config.Admin{Username: "admin", Password: "secret"},
+			user: "admin", pass: "secret", want: http.StatusOK},
+		{name: "no credentials", admin: config.Admin{Username: "admin", Password: "secret"},
+			want: http.StatusUnauthorized},
+		{name: "admin disabled", admin: config.Admin{Username: "admin"}, want: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mux := newMux(config.Config{Admin: tt.admin}, nil, nil, slog.New(slog.DiscardHandler))
+
+			r := httptest.NewRequest(http.MethodGet, "/debug/vars", nil)
+			if tt.user != "" {
+				r.SetBasicAuth(tt.user, tt.pass)
+			}
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, r)
+
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d", w.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewMux_APIToken(t *testing.T) {
+	tests := []struct {
+		name   string
+		token  string
+		method string
+		target string
+		auth   string
+		want   int
+	}{
+		{name: "cancel without token", token: "secret", method: http.MethodPost, target: "/v1/emails/cancel",
+			want: http.StatusUnauthorized},
+		{name: "unversioned retry without token", token: "secret", method: http.MethodPost,
+			target: "/emails/1/retry", want: http.StatusUnauthorized},
+		{name: "list with wrong token", token: "secret", method: http.MethodGet, target: "/v1/emails",
+			auth: "Bearer guess", want: http.StatusUnauthorized},
+		{name: "list with token", token: "secret", method: http.MethodGet, target: "/v1/emails?status=unknown",
+			auth: "Bearer secret", want: http.StatusBadRequest},
+		{name: "openapi is public", token: "secret", method: http.MethodGet, target: "/v1/openapi.json",
+			want: http.StatusOK},
+		{name: "open api without token", method: http.MethodGet, target: "/v1/emails?status=unknown",
+			want: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.Config{Server: config.Server{APIToken: tt.token}}
+			mux := newMux(cfg, nil, nil, slog.New(slog.DiscardHandler))
+
+			r := httptest.NewRequest(tt.method, tt.target, nil)
+			if tt.auth != "" {
+				r.Header.Set("Authorization", tt.auth)
+			}
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, r)
+
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d", w.Code, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/grishkovelli/betera-mailqusrv/config"
+	"github.com/grishkovelli/betera-mailqusrv/internal/bounce"
+	"github.com/grishkovelli/betera-mailqusrv/internal/entities"
+)
+
+type bounceEmailRepo interface {
+	GetByID(ctx context.Context, id int) (entities.Email, error)
+	GetByMessageID(ctx context.Context, messageID string) (entities.Email, error)
+	UpdateStatus(ctx context.Context, id int, status entities.Status) (entities.Email, error)
+}
+
+type bounceSuppressionRepo interface {
+	Upsert(ctx context.Context, s entities.CreateSuppression) (entities.Suppression, error)
+}
+
+// BounceService processes inbound delivery status notifications.
+type BounceService struct {
+	cfg          config.Mail
+	emails       bounceEmailRepo
+	suppressions bounceSuppressionRepo
+}
+
+// NewBounceService creates a new instance of BounceService with the provided repositories.
+func NewBounceService(cfg config.Mail, emails bounceEmailRepo, suppressions bounceSuppressionRepo) *BounceService {
+	return &BounceService{cfg: cfg, emails: emails, suppressions: suppressions}
+}
+
+// Process parses a raw delivery status notification, matches it to the original email through
+// its Message-ID or VERP return path, marks the email as bounced and suppresses hard-bounced addresses.
+// Only failures of the email's own recipient are acted on, so that a notification naming other
+// addresses cannot suppress them.
+func (s *BounceService) Process(ctx context.Context, raw io.Reader) (entities.BounceResult, error) {
+	dsn, err := bounce.Parse(raw)
+	if err != nil {
+		return entities.BounceResult{}, err
+	}
+
+	email, err := s.match(ctx, dsn)
+	if err != nil {
+		return entities.BounceResult{}, err
+	}
+
+	result := entities.BounceResult{EmailID: email.ID, Suppressed: []string{}}
+	for _, rcpt := range dsn.Recipients {
+		if !rcpt.Failed() || !strings.EqualFold(rcpt.Address, email.To) {
+			continue
+		}
+
+		if !result.Bounced {
+			_, err = s.emails.UpdateStatus(ctx, email.ID, entities.Bounced)
+			// a repeated notification for an already bounced email is not an error
+			if err != nil && !errors.Is(err, entities.ErrInvalidTransition) {
+				return result, err
+			}
+			result.Bounced = err == nil
+		}
+
+		if rcpt.Permanent() {
+			if _, err = s.suppressions.Upsert(ctx, entities.CreateSuppression{
+				Address: rcpt.Address,
+				Reason:  entities.Bounce,
+			}); err != nil {
+				return result, err
+			}
+			result.Suppressed = append(result.Suppressed, rcpt.Address)
+		}
+	}
+
+	return result, nil
+}
+
+// match finds the email a notification refers to.
+func (s *BounceService) match(ctx context.Context, dsn *bounce.DSN) (entities.Email, error) {
+	if dsn.OriginalMessageID != "" {
+		email, err := s.emails.GetByMessageID(ctx, dsn.OriginalMessageID)
+		if !errors.Is(err, entities.ErrEmailNotFound) {
+			return email, err
+		}
+	}
+
+	for _, addr := range dsn.ReturnPaths {
+		if !strings.HasSuffix(addr, "@"+strings.ToLower(s.cfg.Domain)) {
+			continue
+		}
+		if id, ok := bounce.ParseVERP(s.cfg.BouncePrefix, addr); ok {
+			return s.emails.GetByID(ctx, id)
+		}
+	}
+
+	return entities.Email{}, entities.ErrEmailNotFound
+}
@@ -0,0 +1,122 @@
+package services
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/grishkovelli/betera-mailqusrv/config"
+	"github.com/grishkovelli/betera-mailqusrv/internal/entities"
+)
+
+// memSuppressionRepo records upserted suppressions.
+type memSuppressionRepo struct {
+	suppressions []entities.CreateSuppression
+}
+
+func (r *memSuppressionRepo) Upsert(
+	_ context.Context,
+	s entities.CreateSuppression,
+) (entities.Suppression, error) {
+	r.suppressions = append(r.suppressions, s)
+	return entities.Suppression{Address: s.Address, Reason: s.Reason}, nil
+}
+
+// bounceEmails adds the Message-ID lookup of bounce processing to memEmailRepo.
+type bounceEmails struct {
+	*memEmailRepo
+}
+
+func (r bounceEmails) GetByMessageID(_ context.Context, messageID string) (entities.Email, error) {
+	for _, e := range r.emails {
+		if e.MessageID == messageID {
+			return e, nil
+		}
+	}
+
+	return entities.Email{}, entities.ErrEmailNotFound
+}
+
+// dsn builds a hard bounce of the message with the given Message-ID for the given recipients.
+func dsn(messageID string, recipients ...string) string {
+	var b strings.Builder
+	b.WriteString("To: bounces+1@mailqu.local\r\n" +
+		"Content-Type: multipart/report; report-type=delivery-status; boundary=b\r\n" +
+		"\r\n" +
+		"--b\r\n" +
+		"Content-Type: message/delivery-status\r\n" +
+		"\r\n" +
+		"Reporting-MTA: dns; mx.example.com\r\n")
+	for _, rcpt := range recipients {
+		b.WriteString("\r\nFinal-Recipient: rfc822; " + rcpt + "\r\nAction: failed\r\nStatus: 5.1.1\r\n")
+	}
+	b.WriteString("--b\r\n" +
+		"Content-Type: text/rfc822-headers\r\n" +
+		"\r\n" +
+		"Message-ID: " + messageID + "\r\n" +
+		"--b--\r\n")
+
+	return b.String()
+}
+
+func TestBounceService_Process(t *testing.T) {
+	tests := []struct {
+		name           string
+		recipients     []string
+		wantBounced    bool
+		wantSuppressed []string
+	}{
+		{
+			name:           "own recipient",
+			recipients:     []string{"User@Example.com"},
+			wantBounced:    true,
+			wantSuppressed: []string{"user@example.com"},
+		},
+		{
+			name:           "foreign recipient",
+			recipients:     []string{"victim@example.org"},
+			wantSuppressed: []string{},
+		},
+		{
+			name:           "own and foreign recipients",
+			recipients:     []string{"victim@example.org", "user@example.com"},
+			wantBounced:    true,
+			wantSuppressed: []string{"user@example.com"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			emails := &memEmailRepo{emails: []entities.Email{
+				{ID: 1, To: "user@example.com", Status: entities.Sent, MessageID: "<abc@mailqu.local>"},
+			}}
+			suppressions := &memSuppressionRepo{}
+			s := NewBounceService(config.Mail{Domain: "mailqu.local", BouncePrefix: "bounces"},
+				bounceEmails{emails}, suppressions)
+
+			res, err := s.Process(t.Context(), strings.NewReader(dsn("<abc@mailqu.local>", tt.recipients...)))
+			if err != nil {
+				t.Fatalf("Process() error = %v", err)
+			}
+
+			if res.Bounced != tt.wantBounced {
+				t.Errorf("Bounced = %v, want %v", res.Bounced, tt.wantBounced)
+			}
+			if !slices.Equal(res.Suppressed, tt.wantSuppressed) {
+				t.Errorf("Suppressed = %v, want %v", res.Suppressed, tt.wantSuppressed)
+			}
+			if len(suppressions.suppressions) != len(tt.wantSuppressed) {
+				t.Errorf("suppressed %d addresses, want %d", len(suppressions.suppressions), len(tt.wantSuppressed))
+			}
+
+			wantStatus := entities.Sent
+			if tt.wantBounced {
+				wantStatus = entities.Bounced
+			}
+			if got := emails.emails[0].Status; got != wantStatus {
+				t.Errorf("status = %s, want %s", got, wantStatus)
+			}
+		})
+	}
+}
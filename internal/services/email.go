@@ -1,35 +1,262 @@
 package services
 
 import (
+	"cmp"
 	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
 
+	"github.com/grishkovelli/betera-mailqusrv/config"
 	"github.com/grishkovelli/betera-mailqusrv/internal/entities"
+	"github.com/grishkovelli/betera-mailqusrv/internal/requestid"
+	"github.com/grishkovelli/betera-mailqusrv/internal/tracing"
 )
 
 type emailRepo interface {
-	Create(ctx context.Context, email entities.CreateEmail) (entities.Email, error)
-	GetByStatus(ctx context.Context, status string, limit, cursor int) ([]entities.Email, error)
+	Create(ctx context.Context, email entities.Email) (entities.Email, error)
+	GetByID(ctx context.Context, id int) (entities.Email, error)
+	GetByIdempotencyKey(ctx context.Context, key string) (entities.Email, error)
+	GetByStatus(ctx context.Context, status entities.Status, limit, cursor int) ([]entities.Email, error)
+	GetByFilter(ctx context.Context, f entities.EmailFilter, limit, cursor int) ([]entities.Email, error)
+	UpdateStatus(ctx context.Context, id int, status entities.Status) (entities.Email, error)
+	UpdateStatusByFilter(ctx context.Context, f entities.EmailFilter, status entities.Status) (int64, error)
+}
+
+type suppressionChecker interface {
+	FilterSuppressed(ctx context.Context, category string, addresses []string) ([]string, error)
+}
+
+type addressValidator interface {
+	Validate(ctx context.Context, addr string) (string, error)
+}
+
+type identityFinder interface {
+	FindForAddress(ctx context.Context, address string) (entities.Identity, error)
 }
 
 // EmailService handles business logic for email operations.
 type EmailService struct {
-	repo emailRepo
+	cfg          config.Config
+	repo         emailRepo
+	suppressions suppressionChecker
+	identities   identityFinder
+	addresses    addressValidator
+}
+
+// NewEmailService creates a new instance of EmailService with the provided repositories.
+func NewEmailService(
+	cfg config.Config,
+	repo emailRepo,
+	suppressions suppressionChecker,
+	identities identityFinder,
+	addresses addressValidator,
+) *EmailService {
+	return &EmailService{
+		cfg:          cfg,
+		repo:         repo,
+		suppressions: suppressions,
+		identities:   identities,
+		addresses:    addresses,
+	}
+}
+
+// Create creates a new email record in the system. The recipient address is normalized and undeliverable
+// addresses are rejected with an *address.Error. Emails from addresses that are not covered by
+// a verified identity are rejected with entities.ErrUnverifiedFrom. Emails to suppressed recipients are
+// either rejected with entities.ErrRecipientSuppressed or stored as suppressed, depending on the configured mode.
+// Requests repeated with the same idempotency key return the email created by the first one, a key reused
+// for a different email is rejected with entities.ErrIdempotencyConflict.
+func (s *EmailService) Create(ctx context.Context, p entities.CreateEmail) (entities.Email, error) {
+	if p.IdempotencyKey != "" {
+		email, err := s.repo.GetByIdempotencyKey(ctx, p.IdempotencyKey)
+		if !errors.Is(err, entities.ErrEmailNotFound) {
+			if err != nil {
+				return entities.Email{}, err
+			}
+			if !s.sameEmail(ctx, email, p) {
+				return entities.Email{}, fmt.Errorf("%w: %s", entities.ErrIdempotencyConflict, p.IdempotencyKey)
+			}
+			return email, nil
+		}
+	}
+
+	to, err := s.addresses.Validate(ctx, p.To)
+	if err != nil {
+		return entities.Email{}, err
+	}
+
+	from, identity, err := s.identity(ctx, p.From)
+	if err != nil {
+		return entities.Email{}, err
+	}
+
+	suppressed, err := s.suppressions.FilterSuppressed(ctx, p.Category, []string{to})
+	if err != nil {
+		return entities.Email{}, err
+	}
+
+	status := entities.Pending
+	if len(suppressed) > 0 {
+		if s.cfg.Suppression.Mode != config.SuppressionMark {
+			return entities.Email{}, fmt.Errorf("%w: %s", entities.ErrRecipientSuppressed, to)
+		}
+
+		status = entities.Suppressed
+	}
+
+	messageID, err := newMessageID(s.cfg.Mail.Domain)
+	if err != nil {
+		return entities.Email{}, err
+	}
+
+	return s.repo.Create(ctx, entities.Email{
+		To:             to,
+		Subject:        p.Subject,
+		Body:           p.Body,
+		Status:         status,
+		MessageID:      messageID,
+		Provider:       p.Provider,
+		From:           from,
+		FromName:       identity.DisplayName,
+		ReplyTo:        identity.ReplyTo,
+		HTML:           p.HTML,
+		Track:          p.Track,
+		Category:       p.Category,
+		RequestID:      requestid.FromContext(ctx),
+		TraceParent:    tracing.TraceParent(ctx),
+		IdempotencyKey: p.IdempotencyKey,
+	})
 }
 
-// NewEmailService creates a new instance of EmailService with the provided repository.
-func NewEmailService(repo emailRepo) *EmailService {
-	return &EmailService{repo: repo}
+// sameEmail reports whether the email created for an idempotency key was requested with the recipient,
+// subject, body and from address of p. Recipients are compared after normalization, emails without
+// a from address are sent from the default identity.
+func (s *EmailService) sameEmail(ctx context.Context, email entities.Email, p entities.CreateEmail) bool {
+	if email.Subject != p.Subject || email.Body != p.Body ||
+		!strings.EqualFold(email.From, cmp.Or(p.From, s.cfg.Mail.From)) {
+		return false
+	}
+	if strings.EqualFold(email.To, p.To) {
+		return true
+	}
+
+	to, err := s.addresses.Validate(ctx, p.To)
+	return err == nil && strings.EqualFold(email.To, to)
+}
+
+// identity returns the from address and the verified identity an email is sent from.
+// Emails without a from address are sent from the default identity.
+func (s *EmailService) identity(ctx context.Context, from string) (string, entities.Identity, error) {
+	if from == "" {
+		return s.cfg.Mail.From, entities.Identity{
+			Address:     s.cfg.Mail.From,
+			DisplayName: s.cfg.Mail.FromName,
+			ReplyTo:     s.cfg.Mail.ReplyTo,
+			Verified:    true,
+		}, nil
+	}
+
+	identity, err := s.identities.FindForAddress(ctx, from)
+	if errors.Is(err, entities.ErrIdentityNotFound) || (err == nil && !identity.Verified) {
+		return "", entities.Identity{}, fmt.Errorf("%w: %s", entities.ErrUnverifiedFrom, from)
+	}
+	if err != nil {
+		return "", entities.Identity{}, err
+	}
+
+	return from, identity, nil
 }
 
-// Create creates a new email record in the system.
-func (s *EmailService) Create(ctx context.Context, p entities.CreateEmail) error {
-	_, err := s.repo.Create(ctx, p)
-	return err
+// Get retrieves a single email by its ID.
+func (s *EmailService) Get(ctx context.Context, id int) (entities.Email, error) {
+	return s.repo.GetByID(ctx, id)
 }
 
 // GetByStatus retrieves a list of emails filtered by their status
 // limit specifies the maximum number of records to return
 // cursor is used for pagination.
-func (s *EmailService) GetByStatus(ctx context.Context, status string, limit, cursor int) ([]entities.Email, error) {
+func (s *EmailService) GetByStatus(
+	ctx context.Context,
+	status entities.Status,
+	limit, cursor int,
+) ([]entities.Email, error) {
 	return s.repo.GetByStatus(ctx, status, limit, cursor)
 }
+
+// GetByFilter retrieves a page of emails matching the filter, e.g. all emails to a recipient.
+func (s *EmailService) GetByFilter(
+	ctx context.Context,
+	f entities.EmailFilter,
+	limit, cursor int,
+) ([]entities.Email, error) {
+	return s.repo.GetByFilter(ctx, f, limit, cursor)
+}
+
+// Cancel stops delivery of a pending or failed email.
+func (s *EmailService) Cancel(ctx context.Context, id int) (entities.Email, error) {
+	return s.transition(ctx, id, entities.Cancelled)
+}
+
+// Retry puts a failed or dead email back into the queue.
+func (s *EmailService) Retry(ctx context.Context, id int) (entities.Email, error) {
+	return s.transition(ctx, id, entities.Pending)
+}
+
+// BulkCancel cancels all emails matching the filter that can be cancelled
+// and returns the number of cancelled emails.
+func (s *EmailService) BulkCancel(ctx context.Context, f entities.EmailFilter) (int64, error) {
+	return s.bulkTransition(ctx, f, entities.Cancelled)
+}
+
+// BulkRetry requeues all emails matching the filter that can be retried
+// and returns the number of requeued emails.
+func (s *EmailService) BulkRetry(ctx context.Context, f entities.EmailFilter) (int64, error) {
+	return s.bulkTransition(ctx, f, entities.Pending)
+}
+
+// transition moves a single email to the given status if the state machine allows it.
+// The repository re-checks the transition atomically, so a concurrent status change
+// between the lookup and the update is reported as an *entities.TransitionError as well.
+func (s *EmailService) transition(ctx context.Context, id int, status entities.Status) (entities.Email, error) {
+	email, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return entities.Email{}, err
+	}
+
+	if !email.Status.CanTransitionTo(status) {
+		return entities.Email{}, &entities.TransitionError{ID: id, From: email.Status, To: status}
+	}
+
+	return s.repo.UpdateStatus(ctx, id, status)
+}
+
+// bulkTransition moves all emails matching the filter to the given status,
+// skipping those for which the state machine does not allow it.
+func (s *EmailService) bulkTransition(
+	ctx context.Context,
+	f entities.EmailFilter,
+	status entities.Status,
+) (int64, error) {
+	if f.IsEmpty() {
+		return 0, entities.ErrEmptyFilter
+	}
+
+	if f.Status != "" && !f.Status.CanTransitionTo(status) {
+		return 0, &entities.TransitionError{From: f.Status, To: status}
+	}
+
+	return s.repo.UpdateStatusByFilter(ctx, f, status)
+}
+
+// newMessageID generates a unique Message-ID header value for the given domain.
+func newMessageID(domain string) (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generate message id: %w", err)
+	}
+
+	return fmt.Sprintf("<%s@%s>", hex.EncodeToString(b), domain), nil
+}
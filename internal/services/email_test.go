@@ -0,0 +1,202 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/grishkovelli/betera-mailqusrv/config"
+	"github.com/grishkovelli/betera-mailqusrv/internal/entities"
+)
+
+// memEmailRepo keeps emails in memory and applies status updates like the SQL of repos.EmailRepo,
+// which only moves emails whose current status is a source status of the target.
+type memEmailRepo struct {
+	emailRepo
+
+	emails []entities.Email
+}
+
+func (r *memEmailRepo) GetByID(_ context.Context, id int) (entities.Email, error) {
+	for _, e := range r.emails {
+		if e.ID == id {
+			return e, nil
+		}
+	}
+
+	return entities.Email{}, entities.ErrEmailNotFound
+}
+
+func (r *memEmailRepo) UpdateStatus(_ context.Context, id int, status entities.Status) (entities.Email, error) {
+	for i, e := range r.emails {
+		if e.ID != id {
+			continue
+		}
+		if !slices.Contains(entities.SourceStatuses(status), e.Status) {
+			return entities.Email{}, &entities.TransitionError{ID: id, From: e.Status, To: status}
+		}
+		r.emails[i].Status = status
+
+		return r.emails[i], nil
+	}
+
+	return entities.Email{}, entities.ErrEmailNotFound
+}
+
+func (r *memEmailRepo) UpdateStatusByFilter(
+	_ context.Context,
+	f entities.EmailFilter,
+	status entities.Status,
+) (int64, error) {
+	var n int64
+	for i, e := range r.emails {
+		if f.To != "" && e.To != f.To {
+			continue
+		}
+		if slices.Contains(entities.SourceStatuses(status), e.Status) {
+			r.emails[i].Status = status
+			n++
+		}
+	}
+
+	return n, nil
+}
+
+func (r *memEmailRepo) Create(_ context.Context, email entities.Email) (entities.Email, error) {
+	email.ID = len(r.emails) + 1
+	r.emails = append(r.emails, email)
+
+	return email, nil
+}
+
+func (r *memEmailRepo) GetByIdempotencyKey(_ context.Context, key string) (entities.Email, error) {
+	for _, e := range r.emails {
+		if e.IdempotencyKey == key {
+			return e, nil
+		}
+	}
+
+	return entities.Email{}, entities.ErrEmailNotFound
+}
+
+// noSuppressions is a suppression list without addresses.
+type noSuppressions struct{}
+
+func (noSuppressions) FilterSuppressed(context.Context, string, []string) ([]string, error) {
+	return nil, nil
+}
+
+// lowerAddresses normalizes addresses by lowercasing them.
+type lowerAddresses struct{}
+
+func (lowerAddresses) Validate(_ context.Context, addr string) (string, error) {
+	return strings.ToLower(addr), nil
+}
+
+func TestEmailService_CreateIdempotent(t *testing.T) {
+	first := entities.CreateEmail{
+		To:             "user@example.com",
+		Subject:        "Subject",
+		Body:           "Body",
+		IdempotencyKey: "order-1",
+	}
+
+	tests := []struct {
+		name    string
+		change  func(p *entities.CreateEmail)
+		wantErr error
+	}{
+		{name: "same email", change: func(*entities.CreateEmail) {}},
+		{name: "recipient in other case", change: func(p *entities.CreateEmail) { p.To = "User@Example.com" }},
+		{name: "default from address", change: func(p *entities.CreateEmail) { p.From = "noreply@mailqu.local" }},
+		{
+			name:    "other recipient",
+			change:  func(p *entities.CreateEmail) { p.To = "other@example.com" },
+			wantErr: entities.ErrIdempotencyConflict,
+		},
+		{
+			name:    "other subject",
+			change:  func(p *entities.CreateEmail) { p.Subject = "Other" },
+			wantErr: entities.ErrIdempotencyConflict,
+		},
+		{
+			name:    "other body",
+			change:  func(p *entities.CreateEmail) { p.Body = "Other" },
+			wantErr: entities.ErrIdempotencyConflict,
+		},
+		{
+			name:    "other from address",
+			change:  func(p *entities.CreateEmail) { p.From = "news@mailqu.local" },
+			wantErr: entities.ErrIdempotencyConflict,
+		},
+	}
+
+	cfg := config.Config{Mail: config.Mail{Domain: "mailqu.local", From: "noreply@mailqu.local"}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &memEmailRepo{}
+			s := NewEmailService(cfg, repo, noSuppressions{}, nil, lowerAddresses{})
+
+			created, err := s.Create(t.Context(), first)
+			if err != nil {
+				t.Fatalf("Create() error = %v", err)
+			}
+
+			replay := first
+			tt.change(&replay)
+			got, err := s.Create(t.Context(), replay)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Create() replay error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && got.ID != created.ID {
+				t.Errorf("Create() replay ID = %d, want %d", got.ID, created.ID)
+			}
+			if len(repo.emails) != 1 {
+				t.Errorf("stored %d emails, want 1", len(repo.emails))
+			}
+		})
+	}
+}
+
+func TestEmailService_RetryProcessing(t *testing.T) {
+	repo := &memEmailRepo{emails: []entities.Email{{ID: 1, To: "user@example.com", Status: entities.Processing}}}
+	s := NewEmailService(config.Config{}, repo, nil, nil, nil)
+
+	_, err := s.Retry(t.Context(), 1)
+	var te *entities.TransitionError
+	if !errors.As(err, &te) || te.From != entities.Processing || te.To != entities.Pending {
+		t.Errorf("Retry() error = %v, want a transition error from %s to %s",
+			err, entities.Processing, entities.Pending)
+	}
+
+	if got := repo.emails[0].Status; got != entities.Processing {
+		t.Errorf("status = %s, want %s", got, entities.Processing)
+	}
+}
+
+func TestEmailService_BulkRetrySkipsProcessing(t *testing.T) {
+	repo := &memEmailRepo{emails: []entities.Email{
+		{ID: 1, To: "user@example.com", Status: entities.Processing},
+		{ID: 2, To: "user@example.com", Status: entities.Failed},
+		{ID: 3, To: "user@example.com", Status: entities.Dead},
+		{ID: 4, To: "user@example.com", Status: entities.Sent},
+	}}
+	s := NewEmailService(config.Config{}, repo, nil, nil, nil)
+
+	n, err := s.BulkRetry(t.Context(), entities.EmailFilter{To: "user@example.com"})
+	if err != nil {
+		t.Fatalf("BulkRetry() error = %v", err)
+	}
+	if n != 2 {
+		t.Errorf("BulkRetry() = %d, want 2", n)
+	}
+
+	want := []entities.Status{entities.Processing, entities.Pending, entities.Pending, entities.Sent}
+	for i, e := range repo.emails {
+		if e.Status != want[i] {
+			t.Errorf("email %d status = %s, want %s", e.ID, e.Status, want[i])
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package services
+
+import (
+	"context"
+
+	"github.com/grishkovelli/betera-mailqusrv/internal/entities"
+)
+
+type eventRepo interface {
+	Create(ctx context.Context, e entities.Event) error
+	Stats(ctx context.Context) (entities.Stats, error)
+}
+
+type tokenParser interface {
+	ParseOpen(token string) (int, error)
+	ParseClick(token string) (int, string, error)
+}
+
+// EventService handles business logic for open and click tracking.
+type EventService struct {
+	tracker tokenParser
+	repo    eventRepo
+}
+
+// NewEventService creates a new instance of EventService with the provided tracker and repository.
+func NewEventService(tracker tokenParser, repo eventRepo) *EventService {
+	return &EventService{tracker: tracker, repo: repo}
+}
+
+// Open records an open of the email referenced by the pixel token.
+func (s *EventService) Open(ctx context.Context, token, userAgent string) error {
+	id, err := s.tracker.ParseOpen(token)
+	if err != nil {
+		return err
+	}
+
+	return s.repo.Create(ctx, entities.Event{EmailID: id, Type: entities.Open, UserAgent: userAgent})
+}
+
+// Click records a click on the link referenced by the token and returns the link target.
+// The target is returned even if the click could not be recorded, so the recipient is still redirected.
+func (s *EventService) Click(ctx context.Context, token, userAgent string) (string, error) {
+	id, target, err := s.tracker.ParseClick(token)
+	if err != nil {
+		return "", err
+	}
+
+	event := entities.Event{EmailID: id, Type: entities.Click, URL: target, UserAgent: userAgent}
+	return target, s.repo.Create(ctx, event)
+}
+
+// Stats returns the number of emails per status and engagement totals.
+func (s *EventService) Stats(ctx context.Context) (entities.Stats, error) {
+	return s.repo.Stats(ctx)
+}
@@ -0,0 +1,52 @@
+package services
+
+import (
+	"context"
+
+	"github.com/grishkovelli/betera-mailqusrv/internal/entities"
+)
+
+type identityRepo interface {
+	Create(ctx context.Context, i entities.CreateIdentity) (entities.Identity, error)
+	GetByID(ctx context.Context, id int) (entities.Identity, error)
+	List(ctx context.Context, limit, cursor int) ([]entities.Identity, error)
+	Verify(ctx context.Context, id int) (entities.Identity, error)
+	Delete(ctx context.Context, id int) error
+}
+
+// IdentityService handles business logic for sender identities.
+type IdentityService struct {
+	repo identityRepo
+}
+
+// NewIdentityService creates a new instance of IdentityService with the provided repository.
+func NewIdentityService(repo identityRepo) *IdentityService {
+	return &IdentityService{repo: repo}
+}
+
+// Create registers a from address or domain. New identities are unverified.
+func (s *IdentityService) Create(ctx context.Context, p entities.CreateIdentity) (entities.Identity, error) {
+	return s.repo.Create(ctx, p)
+}
+
+// Get retrieves a single identity by its ID.
+func (s *IdentityService) Get(ctx context.Context, id int) (entities.Identity, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+// List retrieves a page of identities
+// limit specifies the maximum number of records to return
+// cursor is used for pagination.
+func (s *IdentityService) List(ctx context.Context, limit, cursor int) ([]entities.Identity, error) {
+	return s.repo.List(ctx, limit, cursor)
+}
+
+// Verify allows emails to be sent from an identity.
+func (s *IdentityService) Verify(ctx context.Context, id int) (entities.Identity, error) {
+	return s.repo.Verify(ctx, id)
+}
+
+// Delete removes an identity. Emails that are already queued keep their from address.
+func (s *IdentityService) Delete(ctx context.Context, id int) error {
+	return s.repo.Delete(ctx, id)
+}
@@ -0,0 +1,199 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/grishkovelli/betera-mailqusrv/config"
+	"github.com/grishkovelli/betera-mailqusrv/internal/entities"
+)
+
+// Limits of the status change feed.
+const (
+	subscriberBuffer  = 64               // Changes buffered per subscriber before it is dropped
+	replayPageSize    = 500              // Changes read from the log per query of a replay
+	minListenBackoff  = time.Second      // Wait before listening again after the connection failed
+	maxListenBackoff  = 30 * time.Second // Longest wait between listen attempts
+	pruneInterval     = time.Hour        // Interval of removing expired changes from the log
+	retentionTimeUnit = time.Hour        // Unit of the configured retention
+)
+
+type statusChangeRepo interface {
+	List(ctx context.Context, afterID int64, f entities.StatusChangeFilter, limit int) ([]entities.StatusChange, error)
+	ReplayStart(ctx context.Context, afterID int64, overlap time.Duration) (int64, error)
+	DeleteBefore(ctx context.Context, t time.Time) (int64, error)
+	Listen(ctx context.Context, fn func(entities.StatusChange)) error
+}
+
+// StatusChangeService fans out the status changes announced by the database to subscribers
+// of this replica and replays changes from the log.
+type StatusChangeService struct {
+	cfg    config.SSE
+	repo   statusChangeRepo
+	logger *slog.Logger
+
+	mu          sync.Mutex
+	subscribers map[*subscriber]struct{}
+	closed      bool
+}
+
+type subscriber struct {
+	filter  entities.StatusChangeFilter
+	changes chan entities.StatusChange
+}
+
+// NewStatusChangeService creates a new instance of StatusChangeService.
+func NewStatusChangeService(cfg config.SSE, repo statusChangeRepo, logger *slog.Logger) *StatusChangeService {
+	return &StatusChangeService{
+		cfg:         cfg,
+		repo:        repo,
+		logger:      logger,
+		subscribers: map[*subscriber]struct{}{},
+	}
+}
+
+// Run listens for status changes until ctx is cancelled, listening is resumed with a backoff
+// after connection failures. Changes older than the retention are removed from the log periodically.
+func (s *StatusChangeService) Run(ctx context.Context) {
+	go s.prune(ctx)
+
+	backoff := minListenBackoff
+	for {
+		started := time.Now()
+		err := s.repo.Listen(ctx, s.publish)
+		if ctx.Err() != nil {
+			s.logger.InfoContext(ctx, "status change listener shutting down")
+			return
+		}
+
+		if time.Since(started) > maxListenBackoff {
+			backoff = minListenBackoff
+		}
+		s.logger.ErrorContext(ctx, "listen for status changes", "error", err, "backoff", backoff)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, maxListenBackoff)
+	}
+}
+
+// Subscribe returns a channel of live changes matching the filter and a function that ends the subscription.
+// Subscribers that fall behind by more than subscriberBuffer changes are dropped by closing the channel,
+// they can catch up with Replay.
+func (s *StatusChangeService) Subscribe(f entities.StatusChangeFilter) (<-chan entities.StatusChange, func()) {
+	sub := &subscriber{filter: f, changes: make(chan entities.StatusChange, subscriberBuffer)}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		close(sub.changes)
+	} else {
+		s.subscribers[sub] = struct{}{}
+	}
+
+	return sub.changes, func() { s.unsubscribe(sub) }
+}
+
+// Close ends all subscriptions and the ones made afterwards, so that streams do not hold up a shutdown.
+func (s *StatusChangeService) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.closed = true
+	for sub := range s.subscribers {
+		delete(s.subscribers, sub)
+		close(sub.changes)
+	}
+}
+
+// Replay calls fn with all logged changes matching the filter with an ID greater than afterID, ordered by ID.
+// Changes are committed out of ID order, so the changes logged within the configured overlap before afterID
+// are replayed as well. Clients may receive these changes twice and tell them apart by their IDs.
+func (s *StatusChangeService) Replay(
+	ctx context.Context,
+	afterID int64,
+	f entities.StatusChangeFilter,
+	fn func(entities.StatusChange) error,
+) error {
+	afterID, err := s.repo.ReplayStart(ctx, afterID, time.Duration(s.cfg.ReplayOverlap)*time.Second)
+	if err != nil {
+		return err
+	}
+
+	for {
+		changes, err := s.repo.List(ctx, afterID, f, replayPageSize)
+		if err != nil {
+			return err
+		}
+
+		for _, c := range changes {
+			if err = fn(c); err != nil {
+				return err
+			}
+			afterID = c.ID
+		}
+		if len(changes) < replayPageSize {
+			return nil
+		}
+	}
+}
+
+// publish passes a change to all matching subscribers without blocking the listener.
+func (s *StatusChangeService) publish(c entities.StatusChange) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for sub := range s.subscribers {
+		if !sub.filter.Match(c) {
+			continue
+		}
+
+		select {
+		case sub.changes <- c:
+		default:
+			delete(s.subscribers, sub)
+			close(sub.changes)
+		}
+	}
+}
+
+func (s *StatusChangeService) unsubscribe(sub *subscriber) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.subscribers[sub]; ok {
+		delete(s.subscribers, sub)
+		close(sub.changes)
+	}
+}
+
+// prune removes changes older than the retention until ctx is cancelled.
+func (s *StatusChangeService) prune(ctx context.Context) {
+	if s.cfg.Retention <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(pruneInterval)
+	defer ticker.Stop()
+
+	for {
+		cutoff := time.Now().Add(-time.Duration(s.cfg.Retention) * retentionTimeUnit)
+		if n, err := s.repo.DeleteBefore(ctx, cutoff); err != nil && ctx.Err() == nil {
+			s.logger.ErrorContext(ctx, "prune status changes", "error", err)
+		} else if n > 0 {
+			s.logger.InfoContext(ctx, "pruned status changes", "count", n)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package services
+
+import (
+	"context"
+
+	"github.com/grishkovelli/betera-mailqusrv/internal/entities"
+)
+
+type suppressionRepo interface {
+	Upsert(ctx context.Context, s entities.CreateSuppression) (entities.Suppression, error)
+	GetByID(ctx context.Context, id int) (entities.Suppression, error)
+	List(ctx context.Context, limit, cursor int) ([]entities.Suppression, error)
+	Delete(ctx context.Context, id int) error
+}
+
+// SuppressionService handles business logic for the suppression list.
+type SuppressionService struct {
+	repo suppressionRepo
+}
+
+// NewSuppressionService creates a new instance of SuppressionService with the provided repository.
+func NewSuppressionService(repo suppressionRepo) *SuppressionService {
+	return &SuppressionService{repo: repo}
+}
+
+// Create suppresses an address or updates an existing suppression.
+func (s *SuppressionService) Create(ctx context.Context, p entities.CreateSuppression) (entities.Suppression, error) {
+	return s.repo.Upsert(ctx, p)
+}
+
+// Get retrieves a single suppression by its ID.
+func (s *SuppressionService) Get(ctx context.Context, id int) (entities.Suppression, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+// List retrieves a page of suppressions
+// limit specifies the maximum number of records to return
+// cursor is used for pagination.
+func (s *SuppressionService) List(ctx context.Context, limit, cursor int) ([]entities.Suppression, error) {
+	return s.repo.List(ctx, limit, cursor)
+}
+
+// Delete removes an address from the suppression list.
+func (s *SuppressionService) Delete(ctx context.Context, id int) error {
+	return s.repo.Delete(ctx, id)
+}
@@ -0,0 +1,55 @@
+package services
+
+import (
+	"context"
+
+	"github.com/grishkovelli/betera-mailqusrv/internal/entities"
+)
+
+type unsubscribeTokenParser interface {
+	ParseUnsubscribe(token string) (int, error)
+}
+
+type unsubscribeEmailRepo interface {
+	GetByID(ctx context.Context, id int) (entities.Email, error)
+}
+
+type unsubscribeSuppressionRepo interface {
+	Upsert(ctx context.Context, s entities.CreateSuppression) (entities.Suppression, error)
+}
+
+// UnsubscribeService handles one-click unsubscribe requests of bulk email recipients.
+type UnsubscribeService struct {
+	tracker      unsubscribeTokenParser
+	emails       unsubscribeEmailRepo
+	suppressions unsubscribeSuppressionRepo
+}
+
+// NewUnsubscribeService creates a new instance of UnsubscribeService with the provided tracker and repositories.
+func NewUnsubscribeService(
+	tracker unsubscribeTokenParser,
+	emails unsubscribeEmailRepo,
+	suppressions unsubscribeSuppressionRepo,
+) *UnsubscribeService {
+	return &UnsubscribeService{tracker: tracker, emails: emails, suppressions: suppressions}
+}
+
+// Unsubscribe suppresses the recipient of the email referenced by the token
+// for the list or category of that email.
+func (s *UnsubscribeService) Unsubscribe(ctx context.Context, token string) (entities.Suppression, error) {
+	id, err := s.tracker.ParseUnsubscribe(token)
+	if err != nil {
+		return entities.Suppression{}, err
+	}
+
+	email, err := s.emails.GetByID(ctx, id)
+	if err != nil {
+		return entities.Suppression{}, err
+	}
+
+	return s.suppressions.Upsert(ctx, entities.CreateSuppression{
+		Address:  email.To,
+		Reason:   entities.Unsubscribe,
+		Category: email.Category,
+	})
+}
@@ -0,0 +1,48 @@
+package signing
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSigner(t *testing.T) {
+	s := New("secret")
+
+	token := s.Sign("click", "42:https://example.com/?a=1&b=2")
+	assert.NotContains(t, token, "/")
+	assert.NotContains(t, token, "+")
+
+	payload, err := s.Verify("click", token)
+	require.NoError(t, err)
+	assert.Equal(t, "42:https://example.com/?a=1&b=2", payload)
+
+	tests := []struct {
+		name    string
+		signer  *Signer
+		purpose string
+		token   string
+	}{
+		{name: "other purpose", signer: s, purpose: "open", token: token},
+		{name: "other key", signer: New("other"), purpose: "click", token: token},
+		{name: "tampered payload", signer: s, purpose: "click", token: s.Sign("click", "43:x")[:4] + token[4:]},
+		{name: "no signature", signer: s, purpose: "click", token: "NDI"},
+		{name: "malformed", signer: s, purpose: "click", token: "!!.!!"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.signer.Verify(tt.purpose, tt.token)
+			require.ErrorIs(t, err, ErrInvalidToken)
+		})
+	}
+}
+
+func TestEqual(t *testing.T) {
+	assert.True(t, Equal("secret", "secret"))
+	assert.True(t, Equal("", ""))
+	assert.False(t, Equal("secret", "Secret"))
+	assert.False(t, Equal("secret", "secret2"))
+	assert.False(t, Equal("secret", ""))
+}
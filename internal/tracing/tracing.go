@@ -0,0 +1,96 @@
+// Package tracing sets up OpenTelemetry tracing with OTLP export and provides helpers to start spans
+// and to carry trace context through the emails table. Without an endpoint the global no-op
+// provider stays in place and spans cost next to nothing.
+package tracing
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/grishkovelli/betera-mailqusrv/config"
+)
+
+// Name is the instrumentation scope of the service's spans.
+const Name = "github.com/grishkovelli/betera-mailqusrv"
+
+// traceParentKey is the W3C header whose value is stored on emails.
+const traceParentKey = "traceparent"
+
+// Setup installs the global tracer provider exporting spans over OTLP/HTTP and the W3C propagator.
+// Tracing stays disabled if no endpoint is configured. The returned function flushes and stops
+// the exporter.
+func Setup(ctx context.Context, cfg config.Tracing) (func(context.Context) error, error) {
+	if cfg.Endpoint == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	opts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(cfg.Endpoint)}
+	if cfg.Insecure {
+		opts = append(opts, otlptracehttp.WithInsecure())
+	}
+
+	exporter, err := otlptracehttp.New(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	tp := NewProvider(cfg, sdktrace.WithBatcher(exporter))
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	return tp.Shutdown, nil
+}
+
+// NewProvider creates a tracer provider of the service with the configured sampling ratio.
+// Tests pass an in-memory exporter through sdktrace.WithSyncer.
+func NewProvider(cfg config.Tracing, opts ...sdktrace.TracerProviderOption) *sdktrace.TracerProvider {
+	opts = append([]sdktrace.TracerProviderOption{
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(cfg.ServiceName))),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+	}, opts...)
+
+	return sdktrace.NewTracerProvider(opts...)
+}
+
+// Start starts a span of the service as a child of the span in ctx.
+func Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	return otel.Tracer(Name).Start(ctx, name, opts...)
+}
+
+// End records err on the span, if any, and ends it.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// TraceParent returns the W3C traceparent of the span in ctx, or an empty string if there is none.
+func TraceParent(ctx context.Context) string {
+	carrier := propagation.MapCarrier{}
+	propagation.TraceContext{}.Inject(ctx, carrier)
+
+	return carrier.Get(traceParentKey)
+}
+
+// SpanContext parses a W3C traceparent stored by TraceParent. The result is invalid for empty
+// or malformed values.
+func SpanContext(traceParent string) trace.SpanContext {
+	if traceParent == "" {
+		return trace.SpanContext{}
+	}
+
+	carrier := propagation.MapCarrier{traceParentKey: traceParent}
+	ctx := propagation.TraceContext{}.Extract(context.Background(), carrier)
+
+	return trace.SpanContextFromContext(ctx)
+}
@@ -0,0 +1,70 @@
+package tracing
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+
+	"github.com/grishkovelli/betera-mailqusrv/config"
+)
+
+func newExporter(t *testing.T) *tracetest.InMemoryExporter {
+	t.Helper()
+
+	exp := tracetest.NewInMemoryExporter()
+	prev := otel.GetTracerProvider()
+	otel.SetTracerProvider(NewProvider(config.Tracing{ServiceName: "test", SampleRatio: 1}, sdktrace.WithSyncer(exp)))
+	t.Cleanup(func() { otel.SetTracerProvider(prev) })
+
+	return exp
+}
+
+func TestSetup_Disabled(t *testing.T) {
+	shutdown, err := Setup(t.Context(), config.Tracing{})
+	require.NoError(t, err)
+	require.NoError(t, shutdown(t.Context()))
+}
+
+func TestStartEnd(t *testing.T) {
+	exp := newExporter(t)
+
+	_, succeeded := Start(t.Context(), "succeeded")
+	End(succeeded, nil)
+	_, failed := Start(t.Context(), "failed")
+	End(failed, errors.New("boom"))
+
+	spans := exp.GetSpans()
+	require.Len(t, spans, 2)
+	assert.Equal(t, codes.Unset, spans[0].Status.Code)
+	assert.Equal(t, codes.Error, spans[1].Status.Code)
+	assert.Equal(t, "boom", spans[1].Status.Description)
+	name, ok := spans[1].Resource.Set().Value(semconv.ServiceNameKey)
+	require.True(t, ok)
+	assert.Equal(t, "test", name.AsString())
+}
+
+func TestTraceParent(t *testing.T) {
+	newExporter(t)
+
+	assert.Empty(t, TraceParent(t.Context()))
+	assert.False(t, SpanContext("").IsValid())
+	assert.False(t, SpanContext("garbage").IsValid())
+
+	ctx, span := Start(t.Context(), "enqueue")
+	defer span.End()
+
+	tp := TraceParent(ctx)
+	require.Len(t, tp, 55)
+
+	sc := SpanContext(tp)
+	assert.True(t, sc.IsValid())
+	assert.Equal(t, span.SpanContext().TraceID(), sc.TraceID())
+	assert.Equal(t, span.SpanContext().SpanID(), sc.SpanID())
+}
@@ -0,0 +1,140 @@
+// Package tracking rewrites HTML bodies of outgoing emails so that opens and clicks
+// are reported back to the API through signed links, and builds signed one-click unsubscribe links.
+package tracking
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/grishkovelli/betera-mailqusrv/internal/signing"
+)
+
+// Link paths served by the API.
+const (
+	OpenPath        = "/t/o/"
+	ClickPath       = "/t/c/"
+	UnsubscribePath = "/unsubscribe/"
+)
+
+// Pixel is a transparent 1x1 GIF served for open tracking.
+const Pixel = "GIF89a\x01\x00\x01\x00\x80\x00\x00\x00\x00\x00\xff\xff\xff" +
+	"!\xf9\x04\x01\x00\x00\x00\x00,\x00\x00\x00\x00\x01\x00\x01\x00\x00\x02\x02D\x01\x00;"
+
+// token purposes.
+const (
+	openPurpose        = "open"
+	clickPurpose       = "click"
+	unsubscribePurpose = "unsubscribe"
+)
+
+// Tracker rewrites links and parses the tokens of tracked links.
+// A nil tracker leaves bodies unchanged and rejects all tokens.
+type Tracker struct {
+	baseURL string
+	signer  *signing.Signer
+	links   *regexp.Regexp
+	body    *regexp.Regexp
+}
+
+// New creates a tracker for links served from baseURL.
+func New(baseURL string, signer *signing.Signer) *Tracker {
+	return &Tracker{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		signer:  signer,
+		links:   regexp.MustCompile(`(?i)(<a\s[^>]*?href\s*=\s*)("|')(https?://[^"']+)("|')`),
+		body:    regexp.MustCompile(`(?i)</body\s*>`),
+	}
+}
+
+// Rewrite points all http(s) links of an HTML body to the click endpoint
+// and adds the open tracking pixel to the end of the body.
+func (t *Tracker) Rewrite(emailID int, body string) string {
+	if t == nil {
+		return body
+	}
+
+	body = t.links.ReplaceAllStringFunc(body, func(tag string) string {
+		m := t.links.FindStringSubmatch(tag)
+		target := html.UnescapeString(m[3])
+		return m[1] + m[2] + t.ClickURL(emailID, target) + m[4]
+	})
+
+	pixel := fmt.Sprintf(`<img src="%s" width="1" height="1" alt="" style="display:none">`, t.OpenURL(emailID))
+	if loc := t.body.FindStringIndex(body); loc != nil {
+		return body[:loc[0]] + pixel + body[loc[0]:]
+	}
+
+	return body + pixel
+}
+
+// OpenURL returns the URL of the tracking pixel of an email.
+func (t *Tracker) OpenURL(emailID int) string {
+	return t.baseURL + OpenPath + t.signer.Sign(openPurpose, strconv.Itoa(emailID))
+}
+
+// ClickURL returns the tracked URL of a link in an email.
+func (t *Tracker) ClickURL(emailID int, target string) string {
+	return t.baseURL + ClickPath + t.signer.Sign(clickPurpose, strconv.Itoa(emailID)+":"+target)
+}
+
+// UnsubscribeURL returns the one-click unsubscribe URL of an email.
+// A nil tracker returns an empty URL.
+func (t *Tracker) UnsubscribeURL(emailID int) string {
+	if t == nil {
+		return ""
+	}
+
+	return t.baseURL + UnsubscribePath + t.signer.Sign(unsubscribePurpose, strconv.Itoa(emailID))
+}
+
+// ParseOpen returns the email ID of an open tracking token.
+func (t *Tracker) ParseOpen(token string) (int, error) {
+	return t.parseID(openPurpose, token)
+}
+
+// ParseUnsubscribe returns the email ID of an unsubscribe token.
+func (t *Tracker) ParseUnsubscribe(token string) (int, error) {
+	return t.parseID(unsubscribePurpose, token)
+}
+
+// parseID returns the email ID of a token signed for the purpose.
+func (t *Tracker) parseID(purpose, token string) (int, error) {
+	if t == nil {
+		return 0, signing.ErrInvalidToken
+	}
+
+	payload, err := t.signer.Verify(purpose, token)
+	if err != nil {
+		return 0, err
+	}
+
+	id, err := strconv.Atoi(payload)
+	if err != nil {
+		return 0, signing.ErrInvalidToken
+	}
+
+	return id, nil
+}
+
+// ParseClick returns the email ID and the original URL of a click tracking token.
+func (t *Tracker) ParseClick(token string) (int, string, error) {
+	if t == nil {
+		return 0, "", signing.ErrInvalidToken
+	}
+
+	payload, err := t.signer.Verify(clickPurpose, token)
+	if err != nil {
+		return 0, "", err
+	}
+
+	rawID, target, _ := strings.Cut(payload, ":")
+	id, err := strconv.Atoi(rawID)
+	if err != nil {
+		return 0, "", signing.ErrInvalidToken
+	}
+
+	return id, target, nil
+}
@@ -0,0 +1,103 @@
+package tracking
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grishkovelli/betera-mailqusrv/internal/signing"
+)
+
+func TestTracker_Rewrite(t *testing.T) {
+	tr := New("https://mail.example.com/", signing.New("secret"))
+
+	body := `<html><body><p>Hi</p>` +
+		`<a href="https://example.com/a?x=1&amp;y=2">A</a> ` +
+		`<A class="btn" HREF='http://example.com/b'>B</A> ` +
+		`<a href="mailto:news@example.com">mail</a> ` +
+		`<a href="#top">top</a>` +
+		`</BODY></html>`
+
+	got := tr.Rewrite(7, body)
+
+	clicks := regexp.MustCompile(`https://mail\.example\.com/t/c/([\w.-]+)`).FindAllStringSubmatch(got, -1)
+	require.Len(t, clicks, 2)
+
+	var targets []string
+	for _, m := range clicks {
+		id, target, err := tr.ParseClick(m[1])
+		require.NoError(t, err)
+		assert.Equal(t, 7, id)
+		targets = append(targets, target)
+	}
+	assert.Equal(t, []string{"https://example.com/a?x=1&y=2", "http://example.com/b"}, targets)
+
+	assert.Contains(t, got, `<a href="mailto:news@example.com">mail</a>`)
+	assert.Contains(t, got, `<a href="#top">top</a>`)
+	assert.Contains(t, got, `<A class="btn" HREF='https://mail.example.com/t/c/`)
+
+	pixel := regexp.MustCompile(`<img src="https://mail\.example\.com/t/o/([\w.-]+)" width="1" height="1"[^>]*></BODY>`).
+		FindStringSubmatch(got)
+	require.NotNil(t, pixel)
+	id, err := tr.ParseOpen(pixel[1])
+	require.NoError(t, err)
+	assert.Equal(t, 7, id)
+}
+
+func TestTracker_RewriteWithoutBody(t *testing.T) {
+	tr := New("https://mail.example.com", signing.New("secret"))
+
+	got := tr.Rewrite(1, `<p>Hi</p>`)
+	assert.True(t, strings.HasPrefix(got, `<p>Hi</p><img src="https://mail.example.com/t/o/`))
+}
+
+func TestTracker_ParseInvalid(t *testing.T) {
+	tr := New("https://mail.example.com", signing.New("secret"))
+	open := strings.TrimPrefix(tr.OpenURL(1), "https://mail.example.com"+OpenPath)
+	click := strings.TrimPrefix(tr.ClickURL(1, "https://example.com"), "https://mail.example.com"+ClickPath)
+
+	_, _, err := tr.ParseClick(open)
+	require.ErrorIs(t, err, signing.ErrInvalidToken)
+
+	_, err = tr.ParseOpen(click)
+	require.ErrorIs(t, err, signing.ErrInvalidToken)
+
+	_, err = New("https://mail.example.com", signing.New("other")).ParseOpen(open)
+	require.ErrorIs(t, err, signing.ErrInvalidToken)
+}
+
+func TestTracker_Unsubscribe(t *testing.T) {
+	tr := New("https://mail.example.com", signing.New("secret"))
+
+	url := tr.UnsubscribeURL(9)
+	require.True(t, strings.HasPrefix(url, "https://mail.example.com"+UnsubscribePath))
+
+	id, err := tr.ParseUnsubscribe(strings.TrimPrefix(url, "https://mail.example.com"+UnsubscribePath))
+	require.NoError(t, err)
+	assert.Equal(t, 9, id)
+
+	open := strings.TrimPrefix(tr.OpenURL(9), "https://mail.example.com"+OpenPath)
+	_, err = tr.ParseUnsubscribe(open)
+	require.ErrorIs(t, err, signing.ErrInvalidToken)
+}
+
+func TestPixel(t *testing.T) {
+	assert.Len(t, Pixel, 43)
+	assert.True(t, strings.HasPrefix(Pixel, "GIF89a"))
+}
+
+func TestTracker_Nil(t *testing.T) {
+	var tr *Tracker
+
+	assert.Equal(t, "<p>Hi</p>", tr.Rewrite(1, "<p>Hi</p>"))
+	_, err := tr.ParseOpen("token")
+	require.ErrorIs(t, err, signing.ErrInvalidToken)
+	_, _, err = tr.ParseClick("token")
+	require.ErrorIs(t, err, signing.ErrInvalidToken)
+	_, err = tr.ParseUnsubscribe("token")
+	require.ErrorIs(t, err, signing.ErrInvalidToken)
+	assert.Empty(t, tr.UnsubscribeURL(1))
+}
@@ -2,31 +2,95 @@ package worker
 
 import (
 	"context"
+	"expvar"
 	"fmt"
 	"log/slog"
+	"net/mail"
+	"runtime/debug"
+	"slices"
+	"strings"
 	"time"
 
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
 	"github.com/grishkovelli/betera-mailqusrv/config"
+	"github.com/grishkovelli/betera-mailqusrv/internal/bounce"
 	"github.com/grishkovelli/betera-mailqusrv/internal/entities"
+	"github.com/grishkovelli/betera-mailqusrv/internal/metrics"
+	"github.com/grishkovelli/betera-mailqusrv/internal/requestid"
+	"github.com/grishkovelli/betera-mailqusrv/internal/sender"
+	"github.com/grishkovelli/betera-mailqusrv/internal/tracing"
 )
 
 type emailRepo interface {
-	BatchUpdateStatus(ctx context.Context, ids []int, status string) error
+	BatchUpdateStatus(ctx context.Context, ids []int, status entities.Status) error
 	LockPendingFailed(ctx context.Context, batchSize int) ([]entities.Email, error)
+	MarkProcessing(ctx context.Context, ids []int) error
+	MarkSent(ctx context.Context, ids []int, provider string) error
 	WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error
 	MarkStuckEmailsAsPending(ctx context.Context, seconds int) error
 }
 
+type suppressionRepo interface {
+	FilterSuppressed(ctx context.Context, category string, addresses []string) ([]string, error)
+}
+
+type emailSender interface {
+	Deliver(ctx context.Context, msg sender.Message) sender.Result
+}
+
+type messageSigner interface {
+	Sign(from string, msg []byte) (string, error)
+}
+
+type linkTracker interface {
+	Rewrite(emailID int, body string) string
+	UnsubscribeURL(emailID int) string
+}
+
+// Backoff of restarting a crashed worker, doubled on consecutive crashes.
+const (
+	minRestartBackoff = time.Second
+	maxRestartBackoff = time.Minute
+)
+
+// delivery is the outcome of sending an email.
+type delivery struct {
+	status   entities.Status
+	provider string // provider that accepted a sent email
+}
+
 // Pool represents a worker pool that processes emails concurrently.
 type Pool struct {
-	conf   config.Worker
-	repo   emailRepo
-	logger *slog.Logger
+	conf         config.Worker
+	mail         config.Mail
+	repo         emailRepo
+	suppressions suppressionRepo
+	sender       emailSender
+	signer       messageSigner
+	tracker      linkTracker
+	logger       *slog.Logger
+	panics       *expvar.Map
+	backoff      time.Duration // first restart backoff of crashed workers
 }
 
-// NewPool creates a new worker pool with the provided configuration and repository.
-func NewPool(conf config.Worker, repo emailRepo, logger *slog.Logger) *Pool {
-	return &Pool{conf, repo, logger}
+// NewPool creates a new worker pool with the provided configuration, repositories, sender,
+// DKIM signer and link tracker.
+func NewPool(
+	conf config.Worker,
+	mail config.Mail,
+	repo emailRepo,
+	suppressions suppressionRepo,
+	snd emailSender,
+	signer messageSigner,
+	tracker linkTracker,
+	logger *slog.Logger,
+) *Pool {
+	return &Pool{
+		conf, mail, repo, suppressions, snd, signer, tracker, logger,
+		metrics.Map("panics"), minRestartBackoff,
+	}
 }
 
 // Run starts the worker pool by launching multiple worker goroutines and a goroutine to handle stuck emails.
@@ -40,13 +104,47 @@ func (p *Pool) Run(ctx context.Context) {
 	}
 }
 
-// startWorker runs a single worker that processes emails in a loop until the context is cancelled.
+// startWorker runs a single worker until the context is cancelled. A worker that panics is
+// restarted after a backoff, its emails stay in processing until the stuck check releases them.
 func (p *Pool) startWorker(ctx context.Context) {
+	backoff := p.backoff
 	for {
+		started := time.Now()
+		if !p.runWorker(ctx) {
+			return
+		}
+
+		// a worker that ran for a while before crashing starts over with the shortest backoff
+		if time.Since(started) > maxRestartBackoff {
+			backoff = p.backoff
+		}
+		p.logger.InfoContext(ctx, "restarting worker", "backoff", backoff)
+
 		select {
 		case <-ctx.Done():
 			p.logger.InfoContext(ctx, "worker shutting down")
 			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, maxRestartBackoff)
+	}
+}
+
+// runWorker processes emails in a loop until the context is cancelled and reports whether it crashed.
+func (p *Pool) runWorker(ctx context.Context) (crashed bool) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			p.panics.Add("worker", 1)
+			p.logger.ErrorContext(ctx, "worker panic", "panic", rec, "stack", string(debug.Stack()))
+			crashed = true
+		}
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			p.logger.InfoContext(ctx, "worker shutting down")
+			return false
 		default:
 			p.processEmails(ctx)
 			time.Sleep(time.Second)
@@ -56,13 +154,18 @@ func (p *Pool) startWorker(ctx context.Context) {
 
 // processEmails handles a single batch of email processing by selecting, marking, and processing emails.
 func (p *Pool) processEmails(ctx context.Context) {
+	ctx, span := tracing.Start(ctx, "worker.batch")
+
 	emails, err := p.selectAndMarkEmails(ctx)
+	span.SetAttributes(attribute.Int("emails", len(emails)))
+	defer func() { tracing.End(span, err) }()
 
 	if err != nil {
 		p.logger.ErrorContext(ctx, "transaction failed", "error", err)
 		return
 	}
 
+	emails = p.skipSuppressed(ctx, emails)
 	if len(emails) == 0 {
 		return
 	}
@@ -70,11 +173,73 @@ func (p *Pool) processEmails(ctx context.Context) {
 	p.sendAndUpdateEmails(ctx, emails)
 }
 
-// sendAndUpdateEmails processes a batch of emails by sending them and updating their status in the database.
+// skipSuppressed re-checks the suppression list right before delivery, marks emails to
+// suppressed recipients as suppressed and returns the remaining ones. Bulk emails are also
+// skipped when the recipient unsubscribed from their category.
+func (p *Pool) skipSuppressed(ctx context.Context, emails []entities.Email) []entities.Email {
+	if len(emails) == 0 {
+		return emails
+	}
+
+	addresses := map[string][]string{}
+	for _, m := range emails {
+		addresses[m.Category] = append(addresses[m.Category], m.To)
+	}
+
+	suppressed := map[string][]string{}
+	for category, list := range addresses {
+		found, err := p.suppressions.FilterSuppressed(ctx, category, list)
+		if err != nil {
+			// sending to a suppressed address is worse than a delayed delivery,
+			// the emails are picked up again once they are reset by the stuck emails check
+			p.logger.ErrorContext(ctx, "check suppressions", "error", err)
+			return nil
+		}
+		if len(found) > 0 {
+			suppressed[category] = found
+		}
+	}
+	if len(suppressed) == 0 {
+		return emails
+	}
+
+	var ids []int
+	rest := make([]entities.Email, 0, len(emails))
+	for _, m := range emails {
+		if slices.Contains(suppressed[m.Category], strings.ToLower(m.To)) {
+			ids = append(ids, m.ID)
+			p.logger.InfoContext(requestid.NewContext(ctx, m.RequestID), "email status change",
+				"id", m.ID,
+				"addr", m.To,
+				"from", entities.Processing,
+				"to", entities.Suppressed)
+			continue
+		}
+		rest = append(rest, m)
+	}
+
+	if err := p.repo.BatchUpdateStatus(ctx, ids, entities.Suppressed); err != nil {
+		p.logger.ErrorContext(ctx, "update status", "error", err)
+	}
+
+	return rest
+}
+
+// sendAndUpdateEmails delivers emails one by one and updates the status of each email as soon as
+// its delivery finishes, so that no email stays in processing while the rest of the batch is sent.
 func (p *Pool) sendAndUpdateEmails(ctx context.Context, emails []entities.Email) {
-	for status, ids := range sendEmails(emails, p.logger) {
-		if err := p.repo.BatchUpdateStatus(ctx, ids, status); err != nil {
-			p.logger.ErrorContext(ctx, "update status", "error", err)
+	for _, email := range emails {
+		d := p.sendEmail(ctx, email)
+
+		var err error
+		if d.status == entities.Sent {
+			err = p.repo.MarkSent(ctx, []int{email.ID}, d.provider)
+		} else {
+			err = p.repo.BatchUpdateStatus(ctx, []int{email.ID}, d.status)
+		}
+
+		if err != nil {
+			p.logger.ErrorContext(ctx, "update status", "id", email.ID, "error", err)
 		}
 	}
 }
@@ -98,7 +263,7 @@ func (p *Pool) selectAndMarkEmails(ctx context.Context) ([]entities.Email, error
 			ids[i] = m.ID
 		}
 
-		if err = p.repo.BatchUpdateStatus(ctx, ids, entities.Processing); err != nil {
+		if err = p.repo.MarkProcessing(ctx, ids); err != nil {
 			return fmt.Errorf("update status to processing: %w", err)
 		}
 
@@ -107,7 +272,7 @@ func (p *Pool) selectAndMarkEmails(ctx context.Context) ([]entities.Email, error
 	return emails, err
 }
 
-// processStuckEmails periodically checks for and handles emails that are stuck in processing state.
+// processStuckEmails periodically requeues emails that have been in processing for longer than the stuck timeout.
 func (p *Pool) processStuckEmails(ctx context.Context) {
 	tkr := time.NewTicker(time.Second * time.Duration(p.conf.StuckCheckInterval))
 	defer tkr.Stop()
@@ -118,34 +283,123 @@ func (p *Pool) processStuckEmails(ctx context.Context) {
 			p.logger.InfoContext(ctx, "stuck emails processing shutting down")
 			return
 		case <-tkr.C:
-			if err := p.repo.MarkStuckEmailsAsPending(ctx, p.conf.StuckCheckInterval); err != nil {
+			if err := p.repo.MarkStuckEmailsAsPending(ctx, p.conf.StuckTimeout); err != nil {
 				p.logger.InfoContext(ctx, "update stuck emails", "error", err)
 			}
 		}
 	}
 }
 
-// sendEmails simulates email processing by randomly marking emails as sent or failed.
-func sendEmails(emails []entities.Email, logger *slog.Logger) map[string][]int {
-	result := map[string][]int{
-		entities.Sent:   make([]int, 0, len(emails)/2+1),
-		entities.Failed: make([]int, 0, len(emails)/2+1),
+// sendEmail delivers an email and returns its resulting status and provider.
+// Permanently rejected emails are marked as rejected, transient failures are retried
+// until the maximum number of attempts is used up.
+func (p *Pool) sendEmail(ctx context.Context, email entities.Email) delivery {
+	// log lines of the delivery carry the ID of the request that created the email
+	ctx = requestid.NewContext(ctx, email.RequestID)
+
+	ctx, span := tracing.Start(ctx, "worker.send", sendSpanOptions(email)...)
+	res := p.sender.Deliver(ctx, p.message(ctx, email))
+	d := delivery{status: deliveryStatus(email, res, p.conf.MaxAttempts)}
+	if d.status == entities.Sent {
+		d.provider = res.Provider
 	}
+	span.SetAttributes(attribute.String("email.status", string(d.status)), attribute.String("provider", res.Provider))
+	tracing.End(span, res.Err)
 
-	for i, email := range emails {
-		status := entities.Failed
-		if i%2 == 0 {
-			status = entities.Sent
-		}
+	attrs := []any{
+		"id", email.ID,
+		"addr", email.To,
+		"from", email.Status,
+		"to", d.status,
+	}
+	if res.Provider != "" {
+		attrs = append(attrs, "provider", res.Provider)
+	}
+	if res.Err != nil {
+		attrs = append(attrs, "error", res.Err)
+	}
+	p.logger.InfoContext(ctx, "email status change", attrs...)
+
+	return d
+}
 
-		result[status] = append(result[status], email.ID)
+// sendSpanOptions returns the options of the span of an email delivery, linking it
+// to the span of the request that enqueued the email.
+func sendSpanOptions(email entities.Email) []trace.SpanStartOption {
+	opts := []trace.SpanStartOption{trace.WithAttributes(attribute.Int("email.id", email.ID))}
+	if sc := tracing.SpanContext(email.TraceParent); sc.IsValid() {
+		opts = append(opts, trace.WithLinks(trace.Link{SpanContext: sc}))
+	}
+
+	return opts
+}
+
+// message converts an email into an outgoing message signed with the DKIM key of the From domain.
+// Links of tracked HTML bodies are rewritten and bulk emails get one-click unsubscribe headers.
+// Messages that cannot be signed are delivered unsigned.
+func (p *Pool) message(ctx context.Context, email entities.Email) sender.Message {
+	msg := sender.Message{
+		Provider:   email.Provider,
+		MessageID:  email.MessageID,
+		From:       p.from(email),
+		ReturnPath: bounce.VERPAddress(p.mail.BouncePrefix, p.mail.Domain, email.ID),
+		To:         email.To,
+		Subject:    email.Subject,
+		Body:       email.Body,
+		HTML:       email.HTML,
+		Date:       time.Now(),
+	}
+	if email.Track && email.HTML != "" {
+		msg.HTML = p.tracker.Rewrite(email.ID, email.HTML)
+	}
+	if email.ReplyTo != "" {
+		msg.Headers = append(msg.Headers, sender.Header{Key: "Reply-To", Value: email.ReplyTo})
+	}
+	if url := p.tracker.UnsubscribeURL(email.ID); email.Category != "" && url != "" {
+		// RFC 8058 one-click unsubscribe
+		msg.Headers = append(msg.Headers,
+			sender.Header{Key: "List-Unsubscribe", Value: "<" + url + ">"},
+			sender.Header{Key: "List-Unsubscribe-Post", Value: "List-Unsubscribe=One-Click"},
+		)
+	}
+
+	signature, err := p.signer.Sign(msg.From, msg.Bytes())
+	if err != nil {
+		p.logger.ErrorContext(ctx, "dkim sign", "id", email.ID, "error", err)
+	}
+	msg.Signature = signature
+
+	return msg
+}
+
+// from returns the From header value of an email. Emails queued before identities were
+// introduced have no from address and are sent from the default identity.
+func (p *Pool) from(email entities.Email) string {
+	addr := email.From
+	if addr == "" {
+		addr = p.mail.From
+	}
+	if email.FromName == "" {
+		return addr
+	}
+
+	return (&mail.Address{Name: email.FromName, Address: addr}).String()
+}
+
+// deliveryStatus returns the status of an email after the delivery attempt with the given result.
+func deliveryStatus(email entities.Email, res sender.Result, maxAttempts int) entities.Status {
+	switch {
+	case res.Err == nil:
+		return entities.Sent
+	case res.Permanent:
+		return entities.Rejected
+	}
 
-		logger.Info("email status change",
-			"id", email.ID,
-			"addr", email.To,
-			"from", email.Status,
-			"to", status)
+	// the attempt being made was counted when the email was marked as processing,
+	// but the email was loaded before that
+	if maxAttempts > 0 && email.Attempts+1 >= maxAttempts {
+		return entities.Dead
 	}
 
-	return result
+	return entities.Failed
 }
@@ -4,22 +4,36 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"expvar"
+	"fmt"
 	"log/slog"
+	"slices"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
 	"github.com/grishkovelli/betera-mailqusrv/config"
 	"github.com/grishkovelli/betera-mailqusrv/internal/entities"
+	"github.com/grishkovelli/betera-mailqusrv/internal/requestid"
+	"github.com/grishkovelli/betera-mailqusrv/internal/sender"
+	"github.com/grishkovelli/betera-mailqusrv/internal/tracing"
 )
 
 // mockEmailRepo implements the emailRepo interface for testing.
 type mockEmailRepo struct {
 	emails            []entities.Email
 	updateStatusCalls int
+	markProcessCalls  int
+	markSentCalls     int
 	lockEmailsCalls   int
 	transactionCalls  int
 	markStuckCalls    int
+	lockEmailsPanics  int // number of LockPendingFailed calls that panic
 
 	updateStatusErr error
 	lockEmailsErr   error
@@ -27,13 +41,27 @@ type mockEmailRepo struct {
 	markStuckErr    error
 }
 
-func (m *mockEmailRepo) BatchUpdateStatus(_ context.Context, _ []int, _ string) error {
+func (m *mockEmailRepo) BatchUpdateStatus(_ context.Context, _ []int, _ entities.Status) error {
 	m.updateStatusCalls++
 	return m.updateStatusErr
 }
 
+func (m *mockEmailRepo) MarkProcessing(_ context.Context, _ []int) error {
+	m.markProcessCalls++
+	return m.updateStatusErr
+}
+
+func (m *mockEmailRepo) MarkSent(_ context.Context, _ []int, _ string) error {
+	m.markSentCalls++
+	return m.updateStatusErr
+}
+
 func (m *mockEmailRepo) LockPendingFailed(_ context.Context, _ int) ([]entities.Email, error) {
 	m.lockEmailsCalls++
+	if m.lockEmailsPanics > 0 {
+		m.lockEmailsPanics--
+		panic("corrupt email")
+	}
 	if m.lockEmailsErr != nil {
 		return nil, m.lockEmailsErr
 	}
@@ -53,6 +81,55 @@ func (m *mockEmailRepo) MarkStuckEmailsAsPending(_ context.Context, _ int) error
 	return m.markStuckErr
 }
 
+// mockSuppressionRepo implements the suppressionRepo interface for testing.
+type mockSuppressionRepo struct {
+	suppressed []string            // addresses suppressed for all emails
+	categories map[string][]string // addresses suppressed for a category
+	err        error
+}
+
+func (m *mockSuppressionRepo) FilterSuppressed(
+	_ context.Context,
+	category string,
+	addresses []string,
+) ([]string, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+
+	var res []string
+	for _, a := range addresses {
+		a = strings.ToLower(a)
+		if slices.Contains(m.suppressed, a) || (category != "" && slices.Contains(m.categories[category], a)) {
+			res = append(res, a)
+		}
+	}
+	return res, nil
+}
+
+// mockSigner implements the messageSigner interface for testing.
+type mockSigner struct {
+	signature string
+	err       error
+	from      []string
+}
+
+func (m *mockSigner) Sign(from string, _ []byte) (string, error) {
+	m.from = append(m.from, from)
+	return m.signature, m.err
+}
+
+// mockTracker implements the linkTracker interface for testing.
+type mockTracker struct{}
+
+func (m *mockTracker) Rewrite(emailID int, body string) string {
+	return fmt.Sprintf("tracked %d: %s", emailID, body)
+}
+
+func (m *mockTracker) UnsubscribeURL(emailID int) string {
+	return fmt.Sprintf("https://mail.example.com/unsubscribe/%d", emailID)
+}
+
 func newConf() config.Worker {
 	return config.Worker{
 		PoolSize:           1,
@@ -61,22 +138,79 @@ func newConf() config.Worker {
 	}
 }
 
+// recordingSender keeps the messages it accepts and rejects those for which fail returns an error.
+type recordingSender struct {
+	mu   sync.Mutex
+	fail func(msg sender.Message) error
+	sent []sender.Message
+}
+
+func (s *recordingSender) Send(_ context.Context, msg sender.Message) error {
+	if s.fail != nil {
+		if err := s.fail(msg); err != nil {
+			return err
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sent = append(s.sent, msg)
+
+	return nil
+}
+
+// Sent returns all messages accepted so far.
+func (s *recordingSender) Sent() []sender.Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]sender.Message(nil), s.sent...)
+}
+
+// newFakeSender returns a sender that temporarily fails to deliver to test2@example.com
+// and permanently rejects test4@example.com.
+func newFakeSender() *recordingSender {
+	return &recordingSender{fail: func(msg sender.Message) error {
+		switch msg.To {
+		case "test2@example.com":
+			return &sender.Error{Provider: sender.Fake, Code: "451", Err: errors.New("try again later")}
+		case "test4@example.com":
+			return &sender.Error{Provider: sender.Fake, Code: "550", Permanent: true, Err: errors.New("no such user")}
+		}
+		return nil
+	}}
+}
+
+// newSender returns a router over the given fake sender.
+func newSender(fake *recordingSender) *sender.Router {
+	_, logger := newLogger()
+	r, _ := sender.NewRouter(
+		map[string]sender.Sender{sender.Fake: fake},
+		[]sender.Route{{Provider: sender.Fake, Weight: 1}},
+		nil,
+		sender.BreakerConfig{},
+		logger,
+	)
+	return r
+}
+
 func newLogger() (*bytes.Buffer, *slog.Logger) {
 	buf := &bytes.Buffer{}
 	logger := slog.New(slog.NewTextHandler(buf, nil))
 	return buf, logger
 }
 
-func TestSendEmails(t *testing.T) {
+func TestSendEmail(t *testing.T) {
 	tests := []struct {
-		name   string
-		emails []entities.Email
-		want   map[string][]int
+		name        string
+		emails      []entities.Email
+		maxAttempts int
+		want        map[entities.Status][]int
 	}{
 		{
 			name:   "empty emails",
 			emails: []entities.Email{},
-			want: map[string][]int{
+			want: map[entities.Status][]int{
 				entities.Sent:   {},
 				entities.Failed: {},
 			},
@@ -88,35 +222,220 @@ func TestSendEmails(t *testing.T) {
 				{ID: 2, To: "test2@example.com", Status: entities.Pending},
 				{ID: 3, To: "test3@example.com", Status: entities.Pending},
 			},
-			want: map[string][]int{
+			want: map[entities.Status][]int{
 				entities.Sent:   {1, 3},
 				entities.Failed: {2},
 			},
 		},
+		{
+			name: "attempts exhausted",
+			emails: []entities.Email{
+				{ID: 1, To: "test1@example.com", Status: entities.Pending},
+				{ID: 2, To: "test2@example.com", Status: entities.Failed, Attempts: 2},
+				{ID: 3, To: "test3@example.com", Status: entities.Pending},
+				{ID: 4, To: "test2@example.com", Status: entities.Failed, Attempts: 1},
+			},
+			maxAttempts: 3,
+			want: map[entities.Status][]int{
+				entities.Sent:   {1, 3},
+				entities.Failed: {4},
+				entities.Dead:   {2},
+			},
+		},
+		{
+			name: "permanent failure",
+			emails: []entities.Email{
+				{ID: 1, To: "test1@example.com", Status: entities.Pending},
+				{ID: 2, To: "test4@example.com", Status: entities.Pending},
+				{ID: 3, To: "invalid", Status: entities.Failed, Attempts: 1},
+			},
+			maxAttempts: 3,
+			want: map[entities.Status][]int{
+				entities.Sent:     {1},
+				entities.Rejected: {2, 3},
+			},
+		},
+	}
+
+	_, logger := newLogger()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conf := newConf()
+			conf.MaxAttempts = tt.maxAttempts
+			fake := newFakeSender()
+			pool := NewPool(
+				conf,
+				config.Mail{},
+				&mockEmailRepo{},
+				&mockSuppressionRepo{},
+				newSender(fake),
+				&mockSigner{},
+				&mockTracker{},
+				logger,
+			)
+
+			got := map[entities.Status][]int{}
+			for _, email := range tt.emails {
+				d := pool.sendEmail(t.Context(), email)
+				got[d.status] = append(got[d.status], email.ID)
+			}
+			if len(fake.Sent()) != len(tt.want[entities.Sent]) {
+				t.Errorf("sender accepted %v messages, want %v", len(fake.Sent()), len(tt.want[entities.Sent]))
+			}
+			for _, status := range []entities.Status{entities.Sent, entities.Failed, entities.Dead, entities.Rejected} {
+				if len(got[status]) != len(tt.want[status]) {
+					t.Errorf("sendEmail() %s count = %v, want %v", status, len(got[status]), len(tt.want[status]))
+				}
+			}
+		})
 	}
+}
+
+func TestPool_UpdatesStatusAfterEachDelivery(t *testing.T) {
+	repo := &mockEmailRepo{}
+	var updated []int
+	fake := &recordingSender{fail: func(msg sender.Message) error {
+		// the statuses of the emails delivered before must already be stored
+		updated = append(updated, repo.markSentCalls+repo.updateStatusCalls)
+		if msg.To == "test2@example.com" {
+			return errors.New("try again later")
+		}
+		return nil
+	}}
 
 	_, logger := newLogger()
+	pool := NewPool(
+		newConf(),
+		config.Mail{},
+		repo,
+		&mockSuppressionRepo{},
+		newSender(fake),
+		&mockSigner{},
+		&mockTracker{},
+		logger,
+	)
+
+	pool.sendAndUpdateEmails(t.Context(), []entities.Email{
+		{ID: 1, To: "test1@example.com", Status: entities.Processing},
+		{ID: 2, To: "test2@example.com", Status: entities.Processing},
+		{ID: 3, To: "test3@example.com", Status: entities.Processing},
+	})
+
+	if want := []int{0, 1, 2}; !slices.Equal(updated, want) {
+		t.Errorf("stored statuses before each delivery = %v, want %v", updated, want)
+	}
+	if repo.markSentCalls != 2 || repo.updateStatusCalls != 1 {
+		t.Errorf("MarkSent calls = %d, BatchUpdateStatus calls = %d, want 2 and 1",
+			repo.markSentCalls, repo.updateStatusCalls)
+	}
+}
+
+func TestDeliveryStatus(t *testing.T) {
+	transient := &sender.Error{Provider: sender.SMTP, Code: "421", Err: errors.New("service not available")}
+	permanent := &sender.Error{Provider: sender.SMTP, Code: "550", Permanent: true, Err: errors.New("no such user")}
+
+	tests := []struct {
+		name     string
+		attempts int
+		res      sender.Result
+		want     entities.Status
+	}{
+		{name: "delivered", res: sender.Result{Provider: sender.SMTP}, want: entities.Sent},
+		{name: "transient", res: sender.Result{Err: transient}, want: entities.Failed},
+		{name: "transient last attempt", attempts: 2, res: sender.Result{Err: transient}, want: entities.Dead},
+		{name: "permanent", res: sender.Result{Err: permanent, Permanent: true}, want: entities.Rejected},
+		{
+			name:     "permanent last attempt",
+			attempts: 2,
+			res:      sender.Result{Err: permanent, Permanent: true},
+			want:     entities.Rejected,
+		},
+	}
+
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := sendEmails(tt.emails, logger)
-			if len(got[entities.Sent]) != len(tt.want[entities.Sent]) {
-				t.Errorf(
-					"sendEmails() sent count = %v, want %v",
-					len(got[entities.Sent]),
-					len(tt.want[entities.Sent]),
-				)
-			}
-			if len(got[entities.Failed]) != len(tt.want[entities.Failed]) {
-				t.Errorf(
-					"sendEmails() failed count = %v, want %v",
-					len(got[entities.Failed]),
-					len(tt.want[entities.Failed]),
-				)
+			email := entities.Email{ID: 1, Status: entities.Failed, Attempts: tt.attempts}
+			if got := deliveryStatus(email, tt.res, 3); got != tt.want {
+				t.Errorf("deliveryStatus() = %v, want %v", got, tt.want)
 			}
 		})
 	}
 }
 
+func TestSendEmail_LogsRequestID(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := slog.New(requestid.NewLogHandler(slog.NewTextHandler(buf, nil)))
+	pool := NewPool(
+		newConf(),
+		config.Mail{},
+		&mockEmailRepo{},
+		&mockSuppressionRepo{},
+		newSender(newFakeSender()),
+		&mockSigner{},
+		&mockTracker{},
+		logger,
+	)
+
+	pool.sendAndUpdateEmails(t.Context(), []entities.Email{
+		{ID: 1, To: "test1@example.com", Status: entities.Processing, RequestID: "req-1"},
+		{ID: 3, To: "test3@example.com", Status: entities.Processing},
+	})
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d log lines, want 2", len(lines))
+	}
+	if !strings.Contains(lines[0], "id=1 ") || !strings.Contains(lines[0], "request_id=req-1") {
+		t.Errorf("log line %q has no request_id=req-1", lines[0])
+	}
+	if strings.Contains(lines[1], "request_id") {
+		t.Errorf("log line %q of an email without request ID has a request_id", lines[1])
+	}
+}
+
+func TestSendEmail_LinksEnqueueSpan(t *testing.T) {
+	exp := tracetest.NewInMemoryExporter()
+	prev := otel.GetTracerProvider()
+	otel.SetTracerProvider(tracing.NewProvider(config.Tracing{SampleRatio: 1}, sdktrace.WithSyncer(exp)))
+	t.Cleanup(func() { otel.SetTracerProvider(prev) })
+
+	ctx, enqueue := tracing.Start(t.Context(), "enqueue")
+	traceParent := tracing.TraceParent(ctx)
+	enqueue.End()
+
+	_, logger := newLogger()
+	pool := NewPool(
+		newConf(),
+		config.Mail{},
+		&mockEmailRepo{},
+		&mockSuppressionRepo{},
+		newSender(newFakeSender()),
+		&mockSigner{},
+		&mockTracker{},
+		logger,
+	)
+
+	pool.sendAndUpdateEmails(t.Context(), []entities.Email{
+		{ID: 1, To: "test1@example.com", Status: entities.Processing, TraceParent: traceParent},
+		{ID: 2, To: "test2@example.com", Status: entities.Processing},
+	})
+
+	spans := exp.GetSpans()
+	if len(spans) != 3 {
+		t.Fatalf("got %d spans, want 3", len(spans))
+	}
+	linked, unlinked := spans[1], spans[2]
+	if linked.Name != "worker.send" {
+		t.Errorf("span name = %q, want worker.send", linked.Name)
+	}
+	if len(linked.Links) != 1 || linked.Links[0].SpanContext.SpanID() != enqueue.SpanContext().SpanID() {
+		t.Errorf("delivery span links = %v, want the enqueue span", linked.Links)
+	}
+	if len(unlinked.Links) != 0 {
+		t.Errorf("delivery span of an email without trace context has links %v", unlinked.Links)
+	}
+}
+
 func TestPool_StartWorker(t *testing.T) {
 	ctx, cancel := context.WithTimeout(t.Context(), 100*time.Millisecond)
 	defer cancel()
@@ -129,7 +448,16 @@ func TestPool_StartWorker(t *testing.T) {
 	}
 
 	_, logger := newLogger()
-	pool := NewPool(newConf(), mockRepo, logger)
+	pool := NewPool(
+		newConf(),
+		config.Mail{},
+		mockRepo,
+		&mockSuppressionRepo{},
+		newSender(newFakeSender()),
+		&mockSigner{},
+		&mockTracker{},
+		logger,
+	)
 	pool.Run(ctx)
 
 	<-ctx.Done()
@@ -140,19 +468,77 @@ func TestPool_StartWorker(t *testing.T) {
 	if mockRepo.updateStatusCalls == 0 {
 		t.Error("BatchUpdateStatus was not called")
 	}
+	if mockRepo.markProcessCalls == 0 {
+		t.Error("MarkProcessing was not called")
+	}
+	if mockRepo.markSentCalls == 0 {
+		t.Error("MarkSent was not called")
+	}
 	if mockRepo.transactionCalls == 0 {
 		t.Error("WithTransaction was not called")
 	}
 }
 
+func TestPool_RestartsCrashedWorker(t *testing.T) {
+	ctx, cancel := context.WithTimeout(t.Context(), 100*time.Millisecond)
+	defer cancel()
+
+	mockRepo := &mockEmailRepo{
+		emails:           []entities.Email{{ID: 1, To: "test1@example.com", Status: entities.Pending}},
+		lockEmailsPanics: 2,
+	}
+
+	buf, logger := newLogger()
+	pool := NewPool(
+		newConf(),
+		config.Mail{},
+		mockRepo,
+		&mockSuppressionRepo{},
+		newSender(newFakeSender()),
+		&mockSigner{},
+		&mockTracker{},
+		logger,
+	)
+	pool.backoff = time.Millisecond
+	panics := panicCount(pool)
+
+	pool.Run(ctx)
+	<-ctx.Done()
+
+	if mockRepo.lockEmailsCalls < 3 {
+		t.Errorf("LockPendingFailed called %d times, want a restart after each panic", mockRepo.lockEmailsCalls)
+	}
+	if mockRepo.markSentCalls == 0 {
+		t.Error("MarkSent was not called after the restart")
+	}
+	if got := panicCount(pool) - panics; got != 2 {
+		t.Errorf("panic count increased by %d, want 2", got)
+	}
+	if n := strings.Count(buf.String(), "worker panic"); n != 2 {
+		t.Errorf("logged %d worker panics, want 2", n)
+	}
+	if !strings.Contains(buf.String(), "corrupt email") || !strings.Contains(buf.String(), "stack=") {
+		t.Error("worker panic was logged without its value and stack")
+	}
+}
+
+// panicCount returns the number of worker panics counted in the pool's metrics.
+func panicCount(p *Pool) int64 {
+	if v, ok := p.panics.Get("worker").(*expvar.Int); ok {
+		return v.Value()
+	}
+
+	return 0
+}
+
 func TestPool_ProcessStuckEmails(t *testing.T) {
 	ctx, cancel := context.WithTimeout(t.Context(), 1100*time.Millisecond)
 	defer cancel()
 
 	wantLogs := []string{
-		`level=INFO msg="email status change" id=1 addr=test1@example.com from=processing to=sent`,
+		`level=INFO msg="email status change" id=1 addr=test1@example.com from=processing to=sent provider=fake`,
 		`level=INFO msg="email status change" id=2 addr=test2@example.com from=processing to=failed`,
-		`level=INFO msg="email status change" id=1 addr=test1@example.com from=processing to=sent`,
+		`level=INFO msg="email status change" id=1 addr=test1@example.com from=processing to=sent provider=fake`,
 		`level=INFO msg="email status change" id=2 addr=test2@example.com from=processing to=failed`,
 	}
 	mockRepo := &mockEmailRepo{
@@ -163,7 +549,16 @@ func TestPool_ProcessStuckEmails(t *testing.T) {
 	}
 
 	buf, logger := newLogger()
-	pool := NewPool(newConf(), mockRepo, logger)
+	pool := NewPool(
+		newConf(),
+		config.Mail{},
+		mockRepo,
+		&mockSuppressionRepo{},
+		newSender(newFakeSender()),
+		&mockSigner{},
+		&mockTracker{},
+		logger,
+	)
 	pool.Run(ctx)
 
 	<-ctx.Done()
@@ -191,7 +586,16 @@ func TestPool_TransactionErrorHandling(t *testing.T) {
 	}
 
 	buf, logger := newLogger()
-	pool := NewPool(newConf(), mockRepo, logger)
+	pool := NewPool(
+		newConf(),
+		config.Mail{},
+		mockRepo,
+		&mockSuppressionRepo{},
+		newSender(newFakeSender()),
+		&mockSigner{},
+		&mockTracker{},
+		logger,
+	)
 	pool.Run(ctx)
 
 	<-ctx.Done()
@@ -204,3 +608,222 @@ func TestPool_TransactionErrorHandling(t *testing.T) {
 		t.Errorf("Unexpected log output %s", s)
 	}
 }
+
+func TestPool_SkipSuppressed(t *testing.T) {
+	emails := []entities.Email{
+		{ID: 1, To: "test1@example.com", Status: entities.Processing},
+		{ID: 2, To: "Test2@Example.com", Status: entities.Processing},
+		{ID: 3, To: "test3@example.com", Status: entities.Processing, Category: "news"},
+	}
+
+	tests := []struct {
+		name        string
+		suppression *mockSuppressionRepo
+		wantIDs     []int
+		wantUpdates int
+	}{
+		{
+			name:        "nothing suppressed",
+			suppression: &mockSuppressionRepo{},
+			wantIDs:     []int{1, 2, 3},
+		},
+		{
+			name:        "suppressed recipient",
+			suppression: &mockSuppressionRepo{suppressed: []string{"test2@example.com"}},
+			wantIDs:     []int{1, 3},
+			wantUpdates: 1,
+		},
+		{
+			name: "unsubscribed from category",
+			suppression: &mockSuppressionRepo{
+				categories: map[string][]string{"news": {"test1@example.com", "test3@example.com"}},
+			},
+			wantIDs:     []int{1, 2},
+			wantUpdates: 1,
+		},
+		{
+			name:        "suppression check error",
+			suppression: &mockSuppressionRepo{err: errors.New("connection refused")},
+			wantIDs:     nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := &mockEmailRepo{}
+			_, logger := newLogger()
+			pool := NewPool(
+				newConf(),
+				config.Mail{},
+				mockRepo,
+				tt.suppression,
+				newSender(newFakeSender()),
+				&mockSigner{},
+				&mockTracker{},
+				logger,
+			)
+
+			got := pool.skipSuppressed(t.Context(), emails)
+
+			ids := make([]int, 0, len(got))
+			for _, m := range got {
+				ids = append(ids, m.ID)
+			}
+			if !slices.Equal(ids, tt.wantIDs) {
+				t.Errorf("skipSuppressed() = %v, want %v", ids, tt.wantIDs)
+			}
+			if mockRepo.updateStatusCalls != tt.wantUpdates {
+				t.Errorf("BatchUpdateStatus calls = %v, want %v", mockRepo.updateStatusCalls, tt.wantUpdates)
+			}
+		})
+	}
+}
+
+func TestPool_SignsMessages(t *testing.T) {
+	tests := []struct {
+		name   string
+		signer *mockSigner
+		want   string
+	}{
+		{
+			name:   "signed",
+			signer: &mockSigner{signature: "v=1; a=ed25519-sha256; d=mailqu.local; b=abc"},
+			want:   "DKIM-Signature: v=1; a=ed25519-sha256; d=mailqu.local; b=abc\r\nFrom: noreply@mailqu.local\r\n",
+		},
+		{
+			name:   "sign error",
+			signer: &mockSigner{err: errors.New("boom")},
+			want:   "From: noreply@mailqu.local\r\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := newFakeSender()
+			buf, logger := newLogger()
+			mail := config.Mail{Domain: "mailqu.local", BouncePrefix: "bounces", From: "noreply@mailqu.local"}
+			pool := NewPool(
+				newConf(),
+				mail,
+				&mockEmailRepo{},
+				&mockSuppressionRepo{},
+				newSender(fake),
+				tt.signer,
+				&mockTracker{},
+				logger,
+			)
+
+			pool.sendAndUpdateEmails(t.Context(), []entities.Email{{ID: 1, To: "test1@example.com", Status: entities.Pending}})
+
+			if len(fake.Sent()) != 1 {
+				t.Fatalf("sender accepted %v messages, want 1", len(fake.Sent()))
+			}
+			if got := string(fake.Sent()[0].Bytes()); !strings.HasPrefix(got, tt.want) {
+				t.Errorf("message = %q, want prefix %q", got, tt.want)
+			}
+			if !slices.Equal(tt.signer.from, []string{mail.From}) {
+				t.Errorf("signed from %v, want %v", tt.signer.from, mail.From)
+			}
+			if logged := strings.Contains(buf.String(), "dkim sign"); logged != (tt.signer.err != nil) {
+				t.Errorf("sign error logged = %v, want %v", logged, tt.signer.err != nil)
+			}
+		})
+	}
+}
+
+func TestPool_Message(t *testing.T) {
+	tests := []struct {
+		name        string
+		email       entities.Email
+		from        string
+		replyTo     string
+		html        string
+		unsubscribe string
+	}{
+		{
+			name:  "queued before identities",
+			email: entities.Email{ID: 1, To: "test1@example.com"},
+			from:  "noreply@mailqu.local",
+		},
+		{
+			name:  "address only",
+			email: entities.Email{ID: 1, To: "test1@example.com", From: "news@example.com"},
+			from:  "news@example.com",
+		},
+		{
+			name: "display name and reply-to",
+			email: entities.Email{
+				ID:       1,
+				To:       "test1@example.com",
+				From:     "news@example.com",
+				FromName: "Example News",
+				ReplyTo:  "support@example.com",
+			},
+			from:    `"Example News" <news@example.com>`,
+			replyTo: "support@example.com",
+		},
+		{
+			name:  "untracked html",
+			email: entities.Email{ID: 2, To: "test1@example.com", HTML: "<p>Hi</p>"},
+			from:  "noreply@mailqu.local",
+			html:  "<p>Hi</p>",
+		},
+		{
+			name:  "tracked html",
+			email: entities.Email{ID: 2, To: "test1@example.com", HTML: "<p>Hi</p>", Track: true},
+			from:  "noreply@mailqu.local",
+			html:  "tracked 2: <p>Hi</p>",
+		},
+		{
+			name:  "tracked without html",
+			email: entities.Email{ID: 2, To: "test1@example.com", Track: true},
+			from:  "noreply@mailqu.local",
+		},
+		{
+			name:        "bulk category",
+			email:       entities.Email{ID: 3, To: "test1@example.com", Category: "news"},
+			from:        "noreply@mailqu.local",
+			unsubscribe: "<https://mail.example.com/unsubscribe/3>",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, logger := newLogger()
+			mail := config.Mail{From: "noreply@mailqu.local"}
+			pool := NewPool(
+				newConf(),
+				mail,
+				&mockEmailRepo{},
+				&mockSuppressionRepo{},
+				newSender(newFakeSender()),
+				&mockSigner{},
+				&mockTracker{},
+				logger,
+			)
+
+			msg := pool.message(t.Context(), tt.email)
+			if msg.From != tt.from {
+				t.Errorf("From = %q, want %q", msg.From, tt.from)
+			}
+
+			if msg.HTML != tt.html {
+				t.Errorf("HTML = %q, want %q", msg.HTML, tt.html)
+			}
+
+			headers := map[string]string{}
+			for _, h := range msg.Headers {
+				headers[h.Key] = h.Value
+			}
+			if headers["Reply-To"] != tt.replyTo {
+				t.Errorf("Reply-To = %q, want %q", headers["Reply-To"], tt.replyTo)
+			}
+			if headers["List-Unsubscribe"] != tt.unsubscribe {
+				t.Errorf("List-Unsubscribe = %q, want %q", headers["List-Unsubscribe"], tt.unsubscribe)
+			}
+			if tt.unsubscribe != "" && headers["List-Unsubscribe-Post"] != "List-Unsubscribe=One-Click" {
+				t.Errorf("List-Unsubscribe-Post = %q, want one-click", headers["List-Unsubscribe-Post"])
+			}
+		})
+	}
+}
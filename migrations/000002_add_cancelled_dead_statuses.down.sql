@@ -0,0 +1,10 @@
+ALTER TABLE emails DROP COLUMN attempts;
+
+UPDATE emails SET status = 'failed' WHERE status IN ('cancelled', 'dead');
+
+ALTER TYPE STATUS RENAME TO STATUS_OLD;
+CREATE TYPE STATUS AS ENUM ('pending', 'sent', 'failed', 'processing');
+ALTER TABLE emails ALTER COLUMN status DROP DEFAULT;
+ALTER TABLE emails ALTER COLUMN status TYPE STATUS USING status::text::STATUS;
+ALTER TABLE emails ALTER COLUMN status SET DEFAULT 'pending';
+DROP TYPE STATUS_OLD;
\ No newline at end of file
@@ -0,0 +1,4 @@
+ALTER TYPE STATUS ADD VALUE 'cancelled';
+ALTER TYPE STATUS ADD VALUE 'dead';
+
+ALTER TABLE emails ADD COLUMN attempts INTEGER NOT NULL DEFAULT 0;
\ No newline at end of file
@@ -0,0 +1,12 @@
+ALTER TYPE STATUS ADD VALUE 'suppressed';
+
+CREATE TYPE SUPPRESSION_REASON AS ENUM ('bounce', 'complaint', 'unsubscribe', 'manual');
+
+CREATE TABLE suppressions (
+  id SERIAL PRIMARY KEY,
+  address VARCHAR(255) NOT NULL UNIQUE,
+  reason SUPPRESSION_REASON NOT NULL,
+  expires_at TIMESTAMP,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
\ No newline at end of file
@@ -0,0 +1,11 @@
+DROP INDEX emails_message_id_idx;
+ALTER TABLE emails DROP COLUMN message_id;
+
+UPDATE emails SET status = 'sent' WHERE status = 'bounced';
+
+ALTER TYPE STATUS RENAME TO STATUS_OLD;
+CREATE TYPE STATUS AS ENUM ('pending', 'sent', 'failed', 'processing', 'cancelled', 'dead', 'suppressed');
+ALTER TABLE emails ALTER COLUMN status DROP DEFAULT;
+ALTER TABLE emails ALTER COLUMN status TYPE STATUS USING status::text::STATUS;
+ALTER TABLE emails ALTER COLUMN status SET DEFAULT 'pending';
+DROP TYPE STATUS_OLD;
\ No newline at end of file
@@ -0,0 +1,5 @@
+ALTER TYPE STATUS ADD VALUE 'bounced';
+
+ALTER TABLE emails ADD COLUMN message_id VARCHAR(255) NOT NULL DEFAULT ('<' || gen_random_uuid() || '@mailqu.local>');
+ALTER TABLE emails ALTER COLUMN message_id DROP DEFAULT;
+CREATE UNIQUE INDEX emails_message_id_idx ON emails (message_id);
\ No newline at end of file
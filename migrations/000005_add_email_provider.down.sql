@@ -0,0 +1 @@
+ALTER TABLE emails DROP COLUMN provider;
\ No newline at end of file
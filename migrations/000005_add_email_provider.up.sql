@@ -0,0 +1 @@
+ALTER TABLE emails ADD COLUMN provider VARCHAR(32) NOT NULL DEFAULT '';
\ No newline at end of file
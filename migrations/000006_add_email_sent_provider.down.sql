@@ -0,0 +1 @@
+ALTER TABLE emails DROP COLUMN sent_provider;
\ No newline at end of file
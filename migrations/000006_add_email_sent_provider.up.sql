@@ -0,0 +1 @@
+ALTER TABLE emails ADD COLUMN sent_provider VARCHAR(32) NOT NULL DEFAULT '';
\ No newline at end of file
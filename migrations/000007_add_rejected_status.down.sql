@@ -0,0 +1,8 @@
+UPDATE emails SET status = 'dead' WHERE status = 'rejected';
+
+ALTER TYPE STATUS RENAME TO STATUS_OLD;
+CREATE TYPE STATUS AS ENUM ('pending', 'sent', 'failed', 'processing', 'cancelled', 'dead', 'suppressed', 'bounced');
+ALTER TABLE emails ALTER COLUMN status DROP DEFAULT;
+ALTER TABLE emails ALTER COLUMN status TYPE STATUS USING status::text::STATUS;
+ALTER TABLE emails ALTER COLUMN status SET DEFAULT 'pending';
+DROP TYPE STATUS_OLD;
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TYPE STATUS ADD VALUE 'rejected';
\ No newline at end of file
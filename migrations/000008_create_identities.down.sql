@@ -0,0 +1,5 @@
+ALTER TABLE emails DROP COLUMN reply_to;
+ALTER TABLE emails DROP COLUMN from_name;
+ALTER TABLE emails DROP COLUMN from_address;
+
+DROP TABLE identities;
\ No newline at end of file
@@ -0,0 +1,13 @@
+CREATE TABLE identities (
+  id SERIAL PRIMARY KEY,
+  address VARCHAR(255) NOT NULL UNIQUE,
+  display_name VARCHAR(255) NOT NULL DEFAULT '',
+  reply_to VARCHAR(255) NOT NULL DEFAULT '',
+  verified BOOLEAN NOT NULL DEFAULT FALSE,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE emails ADD COLUMN from_address VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE emails ADD COLUMN from_name VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE emails ADD COLUMN reply_to VARCHAR(255) NOT NULL DEFAULT '';
\ No newline at end of file
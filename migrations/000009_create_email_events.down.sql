@@ -0,0 +1,5 @@
+DROP TABLE email_events;
+DROP TYPE EVENT_TYPE;
+
+ALTER TABLE emails DROP COLUMN track;
+ALTER TABLE emails DROP COLUMN html_body;
\ No newline at end of file
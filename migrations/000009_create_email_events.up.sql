@@ -0,0 +1,15 @@
+ALTER TABLE emails ADD COLUMN html_body TEXT NOT NULL DEFAULT '';
+ALTER TABLE emails ADD COLUMN track BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TYPE EVENT_TYPE AS ENUM ('open', 'click');
+
+CREATE TABLE email_events (
+  id SERIAL PRIMARY KEY,
+  email_id INTEGER NOT NULL REFERENCES emails (id) ON DELETE CASCADE,
+  type EVENT_TYPE NOT NULL,
+  url TEXT NOT NULL DEFAULT '',
+  user_agent VARCHAR(512) NOT NULL DEFAULT '',
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX email_events_email_id_idx ON email_events (email_id);
\ No newline at end of file
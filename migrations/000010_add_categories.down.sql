@@ -0,0 +1,6 @@
+DELETE FROM suppressions WHERE category <> '';
+ALTER TABLE suppressions DROP CONSTRAINT suppressions_address_category_key;
+ALTER TABLE suppressions ADD CONSTRAINT suppressions_address_key UNIQUE (address);
+ALTER TABLE suppressions DROP COLUMN category;
+
+ALTER TABLE emails DROP COLUMN category;
\ No newline at end of file
@@ -0,0 +1,5 @@
+ALTER TABLE emails ADD COLUMN category VARCHAR(64) NOT NULL DEFAULT '';
+
+ALTER TABLE suppressions ADD COLUMN category VARCHAR(64) NOT NULL DEFAULT '';
+ALTER TABLE suppressions DROP CONSTRAINT suppressions_address_key;
+ALTER TABLE suppressions ADD CONSTRAINT suppressions_address_category_key UNIQUE (address, category);
\ No newline at end of file
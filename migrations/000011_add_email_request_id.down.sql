@@ -0,0 +1 @@
+ALTER TABLE emails DROP COLUMN request_id;
\ No newline at end of file
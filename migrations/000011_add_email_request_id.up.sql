@@ -0,0 +1 @@
+ALTER TABLE emails ADD COLUMN request_id VARCHAR(128) NOT NULL DEFAULT '';
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE emails DROP COLUMN traceparent;
\ No newline at end of file
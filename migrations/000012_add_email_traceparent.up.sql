@@ -0,0 +1 @@
+ALTER TABLE emails ADD COLUMN traceparent VARCHAR(55) NOT NULL DEFAULT '';
\ No newline at end of file
@@ -0,0 +1,2 @@
+DROP INDEX emails_idempotency_key_idx;
+ALTER TABLE emails DROP COLUMN idempotency_key;
\ No newline at end of file
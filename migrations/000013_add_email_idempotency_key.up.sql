@@ -0,0 +1,2 @@
+ALTER TABLE emails ADD COLUMN idempotency_key VARCHAR(255) NOT NULL DEFAULT '';
+CREATE UNIQUE INDEX emails_idempotency_key_idx ON emails (idempotency_key) WHERE idempotency_key <> '';
\ No newline at end of file
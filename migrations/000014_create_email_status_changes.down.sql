@@ -0,0 +1,4 @@
+DROP TRIGGER emails_status_update ON emails;
+DROP TRIGGER emails_status_insert ON emails;
+DROP FUNCTION log_email_status_change();
+DROP TABLE email_status_changes;
\ No newline at end of file
@@ -0,0 +1,31 @@
+CREATE TABLE email_status_changes (
+  id BIGSERIAL PRIMARY KEY,
+  email_id INTEGER NOT NULL REFERENCES emails (id) ON DELETE CASCADE,
+  from_status STATUS,
+  status STATUS NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX email_status_changes_created_at_idx ON email_status_changes (created_at);
+
+-- every status change is logged and announced on the email_status_changes channel,
+-- so that API replicas can stream changes made by any replica or worker
+CREATE FUNCTION log_email_status_change() RETURNS TRIGGER AS $$
+DECLARE
+  change email_status_changes;
+BEGIN
+  INSERT INTO email_status_changes (email_id, from_status, status)
+  VALUES (NEW.id, CASE WHEN TG_OP = 'UPDATE' THEN OLD.status END, NEW.status)
+  RETURNING * INTO change;
+
+  PERFORM pg_notify('email_status_changes', row_to_json(change)::text);
+
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER emails_status_insert AFTER INSERT ON emails
+  FOR EACH ROW EXECUTE FUNCTION log_email_status_change();
+
+CREATE TRIGGER emails_status_update AFTER UPDATE OF status ON emails
+  FOR EACH ROW WHEN (OLD.status IS DISTINCT FROM NEW.status) EXECUTE FUNCTION log_email_status_change();
\ No newline at end of file
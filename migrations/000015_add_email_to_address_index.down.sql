@@ -0,0 +1 @@
+DROP INDEX emails_to_address_idx;
\ No newline at end of file
@@ -0,0 +1 @@
+CREATE INDEX emails_to_address_idx ON emails (to_address, id);
\ No newline at end of file
@@ -0,0 +1,295 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: mailqu/v1/mailqu.proto
+
+package mailquv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	MailQueue_SendEmail_FullMethodName  = "/mailqu.v1.MailQueue/SendEmail"
+	MailQueue_BatchSend_FullMethodName  = "/mailqu.v1.MailQueue/BatchSend"
+	MailQueue_GetEmail_FullMethodName   = "/mailqu.v1.MailQueue/GetEmail"
+	MailQueue_ListEmails_FullMethodName = "/mailqu.v1.MailQueue/ListEmails"
+	MailQueue_WatchEmail_FullMethodName = "/mailqu.v1.MailQueue/WatchEmail"
+)
+
+// MailQueueClient is the client API for MailQueue service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// MailQueue queues emails and reports their delivery status. It mirrors the emails of the v1 HTTP API.
+type MailQueueClient interface {
+	// SendEmail queues an email. Requests repeated with the same idempotency key return the email queued first,
+	// reusing a key for a different email fails with ALREADY_EXISTS.
+	SendEmail(ctx context.Context, in *SendEmailRequest, opts ...grpc.CallOption) (*Email, error)
+	// BatchSend queues up to 100 emails. Emails that cannot be queued are reported per item.
+	BatchSend(ctx context.Context, in *BatchSendRequest, opts ...grpc.CallOption) (*BatchSendResponse, error)
+	// GetEmail returns a single email.
+	GetEmail(ctx context.Context, in *GetEmailRequest, opts ...grpc.CallOption) (*Email, error)
+	// ListEmails returns a page of emails with the given status, ordered by ID.
+	ListEmails(ctx context.Context, in *ListEmailsRequest, opts ...grpc.CallOption) (*ListEmailsResponse, error)
+	// WatchEmail streams the email and then every change of its status. The stream ends
+	// once the email reaches a terminal status.
+	WatchEmail(ctx context.Context, in *WatchEmailRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Email], error)
+}
+
+type mailQueueClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewMailQueueClient(cc grpc.ClientConnInterface) MailQueueClient {
+	return &mailQueueClient{cc}
+}
+
+func (c *mailQueueClient) SendEmail(ctx context.Context, in *SendEmailRequest, opts ...grpc.CallOption) (*Email, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Email)
+	err := c.cc.Invoke(ctx, MailQueue_SendEmail_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *mailQueueClient) BatchSend(ctx context.Context, in *BatchSendRequest, opts ...grpc.CallOption) (*BatchSendResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BatchSendResponse)
+	err := c.cc.Invoke(ctx, MailQueue_BatchSend_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *mailQueueClient) GetEmail(ctx context.Context, in *GetEmailRequest, opts ...grpc.CallOption) (*Email, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Email)
+	err := c.cc.Invoke(ctx, MailQueue_GetEmail_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *mailQueueClient) ListEmails(ctx context.Context, in *ListEmailsRequest, opts ...grpc.CallOption) (*ListEmailsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListEmailsResponse)
+	err := c.cc.Invoke(ctx, MailQueue_ListEmails_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *mailQueueClient) WatchEmail(ctx context.Context, in *WatchEmailRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Email], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &MailQueue_ServiceDesc.Streams[0], MailQueue_WatchEmail_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchEmailRequest, Email]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type MailQueue_WatchEmailClient = grpc.ServerStreamingClient[Email]
+
+// MailQueueServer is the server API for MailQueue service.
+// All implementations must embed UnimplementedMailQueueServer
+// for forward compatibility.
+//
+// MailQueue queues emails and reports their delivery status. It mirrors the emails of the v1 HTTP API.
+type MailQueueServer interface {
+	// SendEmail queues an email. Requests repeated with the same idempotency key return the email queued first,
+	// reusing a key for a different email fails with ALREADY_EXISTS.
+	SendEmail(context.Context, *SendEmailRequest) (*Email, error)
+	// BatchSend queues up to 100 emails. Emails that cannot be queued are reported per item.
+	BatchSend(context.Context, *BatchSendRequest) (*BatchSendResponse, error)
+	// GetEmail returns a single email.
+	GetEmail(context.Context, *GetEmailRequest) (*Email, error)
+	// ListEmails returns a page of emails with the given status, ordered by ID.
+	ListEmails(context.Context, *ListEmailsRequest) (*ListEmailsResponse, error)
+	// WatchEmail streams the email and then every change of its status. The stream ends
+	// once the email reaches a terminal status.
+	WatchEmail(*WatchEmailRequest, grpc.ServerStreamingServer[Email]) error
+	mustEmbedUnimplementedMailQueueServer()
+}
+
+// UnimplementedMailQueueServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedMailQueueServer struct{}
+
+func (UnimplementedMailQueueServer) SendEmail(context.Context, *SendEmailRequest) (*Email, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SendEmail not implemented")
+}
+func (UnimplementedMailQueueServer) BatchSend(context.Context, *BatchSendRequest) (*BatchSendResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchSend not implemented")
+}
+func (UnimplementedMailQueueServer) GetEmail(context.Context, *GetEmailRequest) (*Email, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetEmail not implemented")
+}
+func (UnimplementedMailQueueServer) ListEmails(context.Context, *ListEmailsRequest) (*ListEmailsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListEmails not implemented")
+}
+func (UnimplementedMailQueueServer) WatchEmail(*WatchEmailRequest, grpc.ServerStreamingServer[Email]) error {
+	return status.Errorf(codes.Unimplemented, "method WatchEmail not implemented")
+}
+func (UnimplementedMailQueueServer) mustEmbedUnimplementedMailQueueServer() {}
+func (UnimplementedMailQueueServer) testEmbeddedByValue()                   {}
+
+// UnsafeMailQueueServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to MailQueueServer will
+// result in compilation errors.
+type UnsafeMailQueueServer interface {
+	mustEmbedUnimplementedMailQueueServer()
+}
+
+func RegisterMailQueueServer(s grpc.ServiceRegistrar, srv MailQueueServer) {
+	// If the following call pancis, it indicates UnimplementedMailQueueServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&MailQueue_ServiceDesc, srv)
+}
+
+func _MailQueue_SendEmail_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SendEmailRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MailQueueServer).SendEmail(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MailQueue_SendEmail_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MailQueueServer).SendEmail(ctx, req.(*SendEmailRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MailQueue_BatchSend_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchSendRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MailQueueServer).BatchSend(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MailQueue_BatchSend_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MailQueueServer).BatchSend(ctx, req.(*BatchSendRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MailQueue_GetEmail_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetEmailRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MailQueueServer).GetEmail(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MailQueue_GetEmail_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MailQueueServer).GetEmail(ctx, req.(*GetEmailRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MailQueue_ListEmails_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListEmailsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MailQueueServer).ListEmails(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MailQueue_ListEmails_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MailQueueServer).ListEmails(ctx, req.(*ListEmailsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MailQueue_WatchEmail_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchEmailRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(MailQueueServer).WatchEmail(m, &grpc.GenericServerStream[WatchEmailRequest, Email]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type MailQueue_WatchEmailServer = grpc.ServerStreamingServer[Email]
+
+// MailQueue_ServiceDesc is the grpc.ServiceDesc for MailQueue service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var MailQueue_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "mailqu.v1.MailQueue",
+	HandlerType: (*MailQueueServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "SendEmail",
+			Handler:    _MailQueue_SendEmail_Handler,
+		},
+		{
+			MethodName: "BatchSend",
+			Handler:    _MailQueue_BatchSend_Handler,
+		},
+		{
+			MethodName: "GetEmail",
+			Handler:    _MailQueue_GetEmail_Handler,
+		},
+		{
+			MethodName: "ListEmails",
+			Handler:    _MailQueue_ListEmails_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchEmail",
+			Handler:       _MailQueue_WatchEmail_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "mailqu/v1/mailqu.proto",
+}
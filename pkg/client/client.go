@@ -0,0 +1,224 @@
+// Package client is a Go client of the mailqusrv v1 API.
+//
+// Requests that are safe to repeat are retried with exponential backoff on network errors and 5xx
+// responses, all requests are retried on 429. Sends are made safe to repeat with an idempotency key,
+// one is generated when none is given with WithIdempotencyKey.
+package client
+
+import (
+	"bytes"
+	"context"
+	crand "crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Defaults of the retry policy.
+const (
+	DefaultMaxRetries = 3
+	DefaultMinBackoff = 100 * time.Millisecond
+	DefaultMaxBackoff = 2 * time.Second
+)
+
+// Client calls the mailqusrv API. It is safe for concurrent use.
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	header     http.Header
+	maxRetries int
+	minBackoff time.Duration
+	maxBackoff time.Duration
+}
+
+// Option configures a Client.
+type Option func(c *Client)
+
+// WithHTTPClient sets the HTTP client used for requests, http.DefaultClient by default.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) { c.httpClient = hc }
+}
+
+// WithHeader adds a header to all requests, e.g. credentials of a gateway in front of the API.
+func WithHeader(key, value string) Option {
+	return func(c *Client) { c.header.Add(key, value) }
+}
+
+// WithRetries sets how often a failed request is retried, 0 disables retries.
+func WithRetries(n int) Option {
+	return func(c *Client) { c.maxRetries = n }
+}
+
+// WithBackoff sets the wait before the first retry, it doubles with every retry up to maxBackoff.
+func WithBackoff(minBackoff, maxBackoff time.Duration) Option {
+	return func(c *Client) { c.minBackoff, c.maxBackoff = minBackoff, maxBackoff }
+}
+
+// New creates a client of the API served at baseURL, e.g. http://localhost:3000.
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:    strings.TrimSuffix(baseURL, "/") + "/v1",
+		httpClient: http.DefaultClient,
+		header:     http.Header{},
+		maxRetries: DefaultMaxRetries,
+		minBackoff: DefaultMinBackoff,
+		maxBackoff: DefaultMaxBackoff,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+// RequestOption configures a single request.
+type RequestOption func(r *requestOptions)
+
+type requestOptions struct {
+	idempotencyKey string
+	requestID      string
+}
+
+// WithIdempotencyKey sets the Idempotency-Key of a send, repeated sends with the same key
+// return the email queued by the first one.
+func WithIdempotencyKey(key string) RequestOption {
+	return func(r *requestOptions) { r.idempotencyKey = key }
+}
+
+// WithRequestID sets the X-Request-ID of a request, which is stored on created emails
+// and added to the server's log lines.
+func WithRequestID(id string) RequestOption {
+	return func(r *requestOptions) { r.requestID = id }
+}
+
+// do sends a request with a JSON body and decodes the JSON response into out. Failed requests
+// are retried according to the retry policy, the error of the last attempt is returned.
+func (c *Client) do(ctx context.Context, method, path string, body, out any, opts []RequestOption) error {
+	var ro requestOptions
+	for _, opt := range opts {
+		opt(&ro)
+	}
+
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return fmt.Errorf("mailqusrv: encode request: %w", err)
+		}
+	}
+
+	// repeating a request that the server may have processed is only safe for reads and idempotent sends
+	replayable := method == http.MethodGet || ro.idempotencyKey != ""
+
+	for attempt := 0; ; attempt++ {
+		wait, err := c.attempt(ctx, method, path, payload, out, ro)
+		if err == nil {
+			return nil
+		}
+
+		var retryable bool
+		if apiErr, ok := err.(*Error); ok { //nolint:errorlint // attempt returns *Error unwrapped
+			retryable = apiErr.StatusCode == http.StatusTooManyRequests ||
+				(apiErr.StatusCode >= http.StatusInternalServerError && replayable)
+		} else {
+			retryable = replayable && ctx.Err() == nil
+		}
+		if !retryable || attempt >= c.maxRetries {
+			return err
+		}
+
+		timer := time.NewTimer(max(wait, c.backoff(attempt)))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+// attempt sends a request once. The returned duration is the Retry-After of a failed request.
+func (c *Client) attempt(
+	ctx context.Context,
+	method, path string,
+	payload []byte,
+	out any,
+	ro requestOptions,
+) (time.Duration, error) {
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, bytes.NewReader(payload))
+	if err != nil {
+		return 0, fmt.Errorf("mailqusrv: %w", err)
+	}
+
+	for key, values := range c.header {
+		req.Header[key] = values
+	}
+	req.Header.Set("Accept", "application/json")
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if ro.idempotencyKey != "" {
+		req.Header.Set("Idempotency-Key", ro.idempotencyKey)
+	}
+	if ro.requestID != "" {
+		req.Header.Set("X-Request-ID", ro.requestID)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("mailqusrv: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return retryAfter(resp), newError(resp)
+	}
+
+	if out == nil {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return 0, nil
+	}
+	if err = json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return 0, fmt.Errorf("mailqusrv: decode response: %w", err)
+	}
+
+	return 0, nil
+}
+
+// backoff returns the wait before the retry following the given attempt, with jitter
+// so that clients failing at the same time do not retry in lockstep.
+func (c *Client) backoff(attempt int) time.Duration {
+	d := c.minBackoff << min(attempt, 30) //nolint:mnd // keeps the shift from overflowing
+	if d <= 0 || d > c.maxBackoff {
+		d = c.maxBackoff
+	}
+	if d <= 0 {
+		return 0
+	}
+
+	return d/2 + rand.N(d/2+1) //nolint:gosec // jitter does not need a secure source
+}
+
+// retryAfter returns the wait requested by the Retry-After header in seconds, 0 if there is none.
+func retryAfter(resp *http.Response) time.Duration {
+	seconds, err := strconv.Atoi(resp.Header.Get("Retry-After"))
+	if err != nil || seconds < 0 {
+		return 0
+	}
+
+	return time.Duration(seconds) * time.Second
+}
+
+// newIdempotencyKey generates a random idempotency key.
+func newIdempotencyKey() string {
+	b := make([]byte, 16)
+	_, _ = crand.Read(b) // never fails, see crypto/rand.Read
+
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,535 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grishkovelli/betera-mailqusrv/config"
+	"github.com/grishkovelli/betera-mailqusrv/internal/entities"
+	"github.com/grishkovelli/betera-mailqusrv/internal/handlers"
+)
+
+// fakeEmailService is an in-memory email service behind the real handlers.
+type fakeEmailService struct {
+	mu     sync.Mutex
+	emails []entities.Email
+}
+
+func (s *fakeEmailService) Create(_ context.Context, p entities.CreateEmail) (entities.Email, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if p.To == "bounced@example.com" {
+		return entities.Email{}, entities.ErrRecipientSuppressed
+	}
+	for _, e := range s.emails {
+		if p.IdempotencyKey == "" || e.IdempotencyKey != p.IdempotencyKey {
+			continue
+		}
+		if e.To != p.To || e.Subject != p.Subject || e.Body != p.Body {
+			return entities.Email{}, entities.ErrIdempotencyConflict
+		}
+		return e, nil
+	}
+
+	email := entities.Email{
+		ID:             len(s.emails) + 1,
+		To:             p.To,
+		Subject:        p.Subject,
+		Body:           p.Body,
+		Status:         entities.Pending,
+		IdempotencyKey: p.IdempotencyKey,
+	}
+	s.emails = append(s.emails, email)
+
+	return email, nil
+}
+
+func (s *fakeEmailService) Get(_ context.Context, id int) (entities.Email, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if id < 1 || id > len(s.emails) {
+		return entities.Email{}, entities.ErrEmailNotFound
+	}
+
+	return s.emails[id-1], nil
+}
+
+func (s *fakeEmailService) GetByStatus(
+	_ context.Context,
+	status entities.Status,
+	limit, cursor int,
+) ([]entities.Email, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	page := []entities.Email{}
+	for _, e := range s.emails {
+		if e.ID > cursor && e.Status == status && len(page) < limit {
+			page = append(page, e)
+		}
+	}
+
+	return page, nil
+}
+
+func (s *fakeEmailService) GetByFilter(
+	_ context.Context,
+	f entities.EmailFilter,
+	limit, cursor int,
+) ([]entities.Email, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	page := []entities.Email{}
+	for _, e := range s.emails {
+		if e.ID > cursor && e.To == f.To && (f.Status == "" || e.Status == f.Status) && len(page) < limit {
+			page = append(page, e)
+		}
+	}
+
+	return page, nil
+}
+
+func (s *fakeEmailService) Cancel(ctx context.Context, id int) (entities.Email, error) {
+	return s.transition(ctx, id, entities.Cancelled)
+}
+
+func (s *fakeEmailService) Retry(ctx context.Context, id int) (entities.Email, error) {
+	return s.transition(ctx, id, entities.Pending)
+}
+
+func (s *fakeEmailService) transition(ctx context.Context, id int, status entities.Status) (entities.Email, error) {
+	email, err := s.Get(ctx, id)
+	if err != nil {
+		return entities.Email{}, err
+	}
+	if !email.Status.CanTransitionTo(status) {
+		return entities.Email{}, &entities.TransitionError{ID: id, From: email.Status, To: status}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.emails[id-1].Status = status
+
+	return s.emails[id-1], nil
+}
+
+func (s *fakeEmailService) BulkCancel(_ context.Context, f entities.EmailFilter) (int64, error) {
+	if f.IsEmpty() {
+		return 0, entities.ErrEmptyFilter
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var n int64
+	for i, e := range s.emails {
+		if slices.Contains(f.IDs, e.ID) && e.Status.CanTransitionTo(entities.Cancelled) {
+			s.emails[i].Status = entities.Cancelled
+			n++
+		}
+	}
+
+	return n, nil
+}
+
+func (s *fakeEmailService) BulkRetry(_ context.Context, f entities.EmailFilter) (int64, error) {
+	if f.IsEmpty() {
+		return 0, entities.ErrEmptyFilter
+	}
+
+	return 0, nil
+}
+
+// newServer starts the email handlers under /v1. Requests pass through wrap, which may fail them.
+// fakeSuppressionService is an in-memory suppression list behind the real handlers.
+type fakeSuppressionService struct {
+	mu           sync.Mutex
+	suppressions []entities.Suppression
+}
+
+func (s *fakeSuppressionService) Create(
+	_ context.Context,
+	p entities.CreateSuppression,
+) (entities.Suppression, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sup := entities.Suppression{
+		ID:        len(s.suppressions) + 1,
+		Address:   p.Address,
+		Reason:    p.Reason,
+		ExpiresAt: p.ExpiresAt,
+		Category:  p.Category,
+	}
+	s.suppressions = append(s.suppressions, sup)
+
+	return sup, nil
+}
+
+func (s *fakeSuppressionService) Get(_ context.Context, id int) (entities.Suppression, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, sup := range s.suppressions {
+		if sup.ID == id {
+			return sup, nil
+		}
+	}
+
+	return entities.Suppression{}, entities.ErrSuppressionNotFound
+}
+
+func (s *fakeSuppressionService) List(_ context.Context, limit, cursor int) ([]entities.Suppression, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var out []entities.Suppression
+	for _, sup := range s.suppressions {
+		if sup.ID > cursor && len(out) < limit {
+			out = append(out, sup)
+		}
+	}
+
+	return out, nil
+}
+
+func (s *fakeSuppressionService) Delete(_ context.Context, id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, sup := range s.suppressions {
+		if sup.ID == id {
+			s.suppressions = slices.Delete(s.suppressions, i, i+1)
+			return nil
+		}
+	}
+
+	return entities.ErrSuppressionNotFound
+}
+
+func newServer(t *testing.T, wrap func(http.Handler) http.Handler) (*fakeEmailService, *httptest.Server) {
+	t.Helper()
+
+	srv := &fakeEmailService{}
+	h := handlers.NewEmailHandler(config.Server{PageSize: 2, MaxBodySize: 1 << 20}, srv)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v1/send-email", h.Send)
+	mux.HandleFunc("POST /v1/send-email/batch", h.SendBatch)
+	mux.HandleFunc("GET /v1/emails", h.List)
+	mux.HandleFunc("GET /v1/emails/{id}", h.Get)
+	mux.HandleFunc("POST /v1/emails/{id}/cancel", h.Cancel)
+	mux.HandleFunc("POST /v1/emails/{id}/retry", h.Retry)
+	mux.HandleFunc("POST /v1/emails/cancel", h.BulkCancel)
+	mux.HandleFunc("POST /v1/emails/retry", h.BulkRetry)
+
+	sh := handlers.NewSuppressionHandler(config.Server{PageSize: 2}, &fakeSuppressionService{})
+	mux.HandleFunc("GET /v1/suppressions", sh.List)
+	mux.HandleFunc("POST /v1/suppressions", sh.Create)
+	mux.HandleFunc("GET /v1/suppressions/{id}", sh.Get)
+	mux.HandleFunc("DELETE /v1/suppressions/{id}", sh.Delete)
+
+	var handler http.Handler = mux
+	if wrap != nil {
+		handler = wrap(mux)
+	}
+	ts := httptest.NewServer(handler)
+	t.Cleanup(ts.Close)
+
+	return srv, ts
+}
+
+// failFirst fails the first n requests with status. With process set the request is handled
+// before the failure is returned, like a response lost on the way back.
+func failFirst(n int32, status int, process bool) func(http.Handler) http.Handler {
+	var calls atomic.Int32
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if calls.Add(1) > n {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if process {
+				next.ServeHTTP(httptest.NewRecorder(), r)
+			}
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(status)
+		})
+	}
+}
+
+func newClient(ts *httptest.Server, opts ...Option) *Client {
+	return New(ts.URL, append([]Option{WithBackoff(time.Millisecond, 5*time.Millisecond)}, opts...)...)
+}
+
+func TestClient_SendAndGet(t *testing.T) {
+	_, ts := newServer(t, nil)
+	c := newClient(ts)
+
+	sent, err := c.Send(t.Context(), CreateEmail{To: "test@example.com", Subject: "Subject", Body: "Body"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, sent.ID)
+	assert.Equal(t, Pending, sent.Status)
+
+	got, err := c.Get(t.Context(), sent.ID)
+	require.NoError(t, err)
+	assert.Equal(t, sent, got)
+}
+
+func TestClient_IdempotencyKey(t *testing.T) {
+	srv, ts := newServer(t, nil)
+	c := newClient(ts)
+	email := CreateEmail{To: "test@example.com", Subject: "Subject", Body: "Body"}
+
+	first, err := c.Send(t.Context(), email, WithIdempotencyKey("order-1"))
+	require.NoError(t, err)
+	second, err := c.Send(t.Context(), email, WithIdempotencyKey("order-1"))
+	require.NoError(t, err)
+	third, err := c.Send(t.Context(), email)
+	require.NoError(t, err)
+
+	assert.Equal(t, first.ID, second.ID)
+	assert.NotEqual(t, first.ID, third.ID)
+	assert.Len(t, srv.emails, 2)
+
+	// the key of the first email cannot queue a different one
+	email.Subject = "Other subject"
+	_, err = c.Send(t.Context(), email, WithIdempotencyKey("order-1"))
+	require.ErrorIs(t, err, ErrConflict)
+	var apiErr *Error
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusConflict, apiErr.StatusCode)
+	assert.Len(t, srv.emails, 2)
+}
+
+func TestClient_SendBatch(t *testing.T) {
+	_, ts := newServer(t, nil)
+	c := newClient(ts)
+
+	results, err := c.SendBatch(t.Context(), []CreateEmail{
+		{To: "test@example.com", Subject: "Subject", Body: "Body"},
+		{To: "bounced@example.com", Subject: "Subject", Body: "Body"},
+	})
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	require.NotNil(t, results[0].Email)
+	assert.Equal(t, "test@example.com", results[0].Email.To)
+	require.NotNil(t, results[1].Error)
+	require.ErrorIs(t, results[1].Error, ErrUnprocessable)
+
+	_, err = c.SendBatch(t.Context(), []CreateEmail{{To: "invalid"}})
+	var apiErr *Error
+	require.ErrorAs(t, err, &apiErr)
+	require.ErrorIs(t, err, ErrInvalidRequest)
+	assert.Equal(t, http.StatusBadRequest, apiErr.StatusCode)
+	assert.Contains(t, apiErr.Fields, FieldError{
+		Field: "emails[0].to_address", Rule: "email", Message: "must be a valid email address",
+	})
+}
+
+func TestClient_Emails(t *testing.T) {
+	_, ts := newServer(t, nil)
+	c := newClient(ts)
+
+	for range 5 {
+		_, err := c.Send(t.Context(), CreateEmail{To: "test@example.com", Subject: "Subject", Body: "Body"})
+		require.NoError(t, err)
+	}
+	_, err := c.Cancel(t.Context(), 2)
+	require.NoError(t, err)
+
+	var ids []int
+	for email, err := range c.Emails(t.Context(), Pending) {
+		require.NoError(t, err)
+		ids = append(ids, email.ID)
+	}
+	assert.Equal(t, []int{1, 3, 4, 5}, ids)
+
+	// stopping early does not fetch further pages
+	for email := range c.Emails(t.Context(), Pending) {
+		assert.Equal(t, 1, email.ID)
+		break
+	}
+}
+
+func TestClient_ListByRecipient(t *testing.T) {
+	_, ts := newServer(t, nil)
+	c := newClient(ts)
+
+	for _, to := range []string{"a@example.com", "b@example.com", "a@example.com", "a@example.com"} {
+		_, err := c.Send(t.Context(), CreateEmail{To: to, Subject: "Subject", Body: "Body"})
+		require.NoError(t, err)
+	}
+	_, err := c.Cancel(t.Context(), 3)
+	require.NoError(t, err)
+
+	page, err := c.ListByRecipient(t.Context(), "a@example.com", "", 0)
+	require.NoError(t, err)
+	require.Len(t, page, 2)
+	assert.Equal(t, []int{1, 3}, []int{page[0].ID, page[1].ID})
+
+	page, err = c.ListByRecipient(t.Context(), "a@example.com", "", page[1].ID)
+	require.NoError(t, err)
+	require.Len(t, page, 1)
+	assert.Equal(t, 4, page[0].ID)
+
+	page, err = c.ListByRecipient(t.Context(), "a@example.com", Cancelled, 0)
+	require.NoError(t, err)
+	require.Len(t, page, 1)
+	assert.Equal(t, 3, page[0].ID)
+}
+
+func TestClient_Suppressions(t *testing.T) {
+	_, ts := newServer(t, nil)
+	c := newClient(ts)
+
+	created, err := c.Suppress(t.Context(), CreateSuppression{Address: "user@example.com", Reason: Manual})
+	require.NoError(t, err)
+	assert.Equal(t, "user@example.com", created.Address)
+	assert.Equal(t, Manual, created.Reason)
+
+	got, err := c.GetSuppression(t.Context(), created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, created, got)
+
+	list, err := c.ListSuppressions(t.Context(), 0)
+	require.NoError(t, err)
+	assert.Equal(t, []Suppression{created}, list)
+
+	require.NoError(t, c.DeleteSuppression(t.Context(), created.ID))
+	err = c.DeleteSuppression(t.Context(), created.ID)
+	require.ErrorIs(t, err, ErrNotFound)
+
+	_, err = c.Suppress(t.Context(), CreateSuppression{Address: "user@example.com", Reason: "spam"})
+	require.ErrorIs(t, err, ErrInvalidRequest)
+}
+
+func TestClient_Errors(t *testing.T) {
+	_, ts := newServer(t, nil)
+	c := newClient(ts)
+
+	_, err := c.Get(t.Context(), 42)
+	require.ErrorIs(t, err, ErrNotFound)
+
+	_, err = c.Send(t.Context(), CreateEmail{To: "bounced@example.com", Subject: "Subject", Body: "Body"})
+	require.ErrorIs(t, err, ErrUnprocessable)
+
+	sent, err := c.Send(t.Context(), CreateEmail{To: "test@example.com", Subject: "Subject", Body: "Body"})
+	require.NoError(t, err)
+	_, err = c.Retry(t.Context(), sent.ID)
+	require.ErrorIs(t, err, ErrConflict)
+
+	cancelled, err := c.BulkCancel(t.Context(), EmailFilter{IDs: []int{sent.ID}})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), cancelled)
+
+	_, err = c.BulkRetry(t.Context(), EmailFilter{})
+	require.ErrorIs(t, err, ErrInvalidRequest)
+	assert.False(t, errors.Is(err, ErrNotFound))
+
+	// gateways in front of the API answer in their own format
+	_, gw := newServer(t, func(http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			http.Error(w, "denied", http.StatusForbidden)
+		})
+	})
+	_, err = newClient(gw).Get(t.Context(), 1)
+	require.ErrorIs(t, err, ErrUnauthorized)
+}
+
+func TestClient_Retries(t *testing.T) {
+	tests := []struct {
+		name      string
+		wrap      func(http.Handler) http.Handler
+		call      func(c *Client) error
+		wantErr   error
+		wantCount int
+	}{
+		{
+			name: "send retried after a lost response",
+			wrap: failFirst(2, http.StatusBadGateway, true),
+			call: func(c *Client) error {
+				_, err := c.Send(context.Background(), CreateEmail{To: "test@example.com", Subject: "S", Body: "B"})
+				return err
+			},
+			wantCount: 1,
+		},
+		{
+			name: "get retried on server errors",
+			wrap: failFirst(3, http.StatusServiceUnavailable, false),
+			call: func(c *Client) error {
+				_, err := c.List(context.Background(), Pending, 0)
+				return err
+			},
+		},
+		{
+			name: "retries exhausted",
+			wrap: failFirst(4, http.StatusInternalServerError, false),
+			call: func(c *Client) error {
+				_, err := c.List(context.Background(), Pending, 0)
+				return err
+			},
+			wantErr: ErrServer,
+		},
+		{
+			name: "cancel not retried on server errors",
+			wrap: failFirst(1, http.StatusInternalServerError, false),
+			call: func(c *Client) error {
+				_, err := c.Cancel(context.Background(), 1)
+				return err
+			},
+			wantErr: ErrServer,
+		},
+		{
+			name: "cancel retried when rate limited",
+			wrap: failFirst(1, http.StatusTooManyRequests, false),
+			call: func(c *Client) error {
+				_, err := c.Cancel(context.Background(), 1)
+				return err
+			},
+			wantErr: ErrNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, ts := newServer(t, tt.wrap)
+			c := newClient(ts)
+
+			err := tt.call(c)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Len(t, srv.emails, tt.wantCount)
+		})
+	}
+}
+
+func TestClient_ContextCancelled(t *testing.T) {
+	_, ts := newServer(t, failFirst(100, http.StatusServiceUnavailable, false))
+	c := New(ts.URL, WithBackoff(time.Hour, time.Hour))
+
+	ctx, cancel := context.WithTimeout(t.Context(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := c.Get(ctx, 1)
+	require.ErrorIs(t, err, ErrServer)
+	assert.Less(t, time.Since(start), time.Second)
+}
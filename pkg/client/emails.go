@@ -0,0 +1,142 @@
+package client
+
+import (
+	"context"
+	"iter"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// Send queues an email. Without WithIdempotencyKey a key is generated, so that retries
+// of the request cannot queue the email twice. Reusing a key for a different email fails with ErrConflict.
+func (c *Client) Send(ctx context.Context, email CreateEmail, opts ...RequestOption) (Email, error) {
+	var out Email
+	err := c.do(ctx, http.MethodPost, "/send-email", email, &out, withIdempotencyKey(opts))
+
+	return out, err
+}
+
+// SendBatch queues up to 100 emails. The results are in the order of emails, emails that could not
+// be queued have a BatchResult.Error. Invalid batches are rejected as a whole with ErrInvalidRequest.
+func (c *Client) SendBatch(ctx context.Context, emails []CreateEmail, opts ...RequestOption) ([]BatchResult, error) {
+	var out batchResponse
+	err := c.do(ctx, http.MethodPost, "/send-email/batch", batchRequest{Emails: emails}, &out, withIdempotencyKey(opts))
+
+	return out.Results, err
+}
+
+// Get returns the email with the given ID.
+func (c *Client) Get(ctx context.Context, id int, opts ...RequestOption) (Email, error) {
+	var out Email
+	err := c.do(ctx, http.MethodGet, "/emails/"+strconv.Itoa(id), nil, &out, opts)
+
+	return out, err
+}
+
+// List returns a page of emails with the given status and an ID greater than cursor,
+// ordered by ID. The ID of the last email is the cursor of the next page.
+func (c *Client) List(ctx context.Context, status Status, cursor int, opts ...RequestOption) ([]Email, error) {
+	query := url.Values{"status": {string(status)}}
+	if cursor > 0 {
+		query.Set("cursor", strconv.Itoa(cursor))
+	}
+
+	var out []Email
+	err := c.do(ctx, http.MethodGet, "/emails?"+query.Encode(), nil, &out, opts)
+
+	return out, err
+}
+
+// ListByRecipient returns a page of emails to the given address with an ID greater than cursor,
+// ordered by ID. An empty status returns emails of all statuses.
+func (c *Client) ListByRecipient(
+	ctx context.Context,
+	to string,
+	status Status,
+	cursor int,
+	opts ...RequestOption,
+) ([]Email, error) {
+	query := url.Values{"to_address": {to}}
+	if status != "" {
+		query.Set("status", string(status))
+	}
+	if cursor > 0 {
+		query.Set("cursor", strconv.Itoa(cursor))
+	}
+
+	var out []Email
+	err := c.do(ctx, http.MethodGet, "/emails?"+query.Encode(), nil, &out, opts)
+
+	return out, err
+}
+
+// Emails iterates over all emails with the given status, fetching pages as needed.
+// Iteration stops after the first error, which is yielded with a zero Email.
+func (c *Client) Emails(ctx context.Context, status Status, opts ...RequestOption) iter.Seq2[Email, error] {
+	return func(yield func(Email, error) bool) {
+		cursor := 0
+		for {
+			page, err := c.List(ctx, status, cursor, opts...)
+			if err != nil {
+				yield(Email{}, err)
+				return
+			}
+			if len(page) == 0 {
+				return
+			}
+
+			for _, email := range page {
+				if !yield(email, nil) {
+					return
+				}
+			}
+			cursor = page[len(page)-1].ID
+		}
+	}
+}
+
+// Cancel stops delivery of a pending or failed email.
+func (c *Client) Cancel(ctx context.Context, id int, opts ...RequestOption) (Email, error) {
+	var out Email
+	err := c.do(ctx, http.MethodPost, "/emails/"+strconv.Itoa(id)+"/cancel", nil, &out, opts)
+
+	return out, err
+}
+
+// Retry puts a failed or dead email back into the queue.
+func (c *Client) Retry(ctx context.Context, id int, opts ...RequestOption) (Email, error) {
+	var out Email
+	err := c.do(ctx, http.MethodPost, "/emails/"+strconv.Itoa(id)+"/retry", nil, &out, opts)
+
+	return out, err
+}
+
+// BulkCancel cancels all emails matching the filter and returns the number of cancelled emails.
+func (c *Client) BulkCancel(ctx context.Context, f EmailFilter, opts ...RequestOption) (int64, error) {
+	var out bulkResult
+	err := c.do(ctx, http.MethodPost, "/emails/cancel", f, &out, opts)
+
+	return out.Updated, err
+}
+
+// BulkRetry requeues all emails matching the filter and returns the number of requeued emails.
+func (c *Client) BulkRetry(ctx context.Context, f EmailFilter, opts ...RequestOption) (int64, error) {
+	var out bulkResult
+	err := c.do(ctx, http.MethodPost, "/emails/retry", f, &out, opts)
+
+	return out.Updated, err
+}
+
+// Stats returns the number of emails per status and engagement totals.
+func (c *Client) Stats(ctx context.Context, opts ...RequestOption) (Stats, error) {
+	var out Stats
+	err := c.do(ctx, http.MethodGet, "/stats", nil, &out, opts)
+
+	return out, err
+}
+
+// withIdempotencyKey prepends a generated idempotency key, options of the caller take precedence.
+func withIdempotencyKey(opts []RequestOption) []RequestOption {
+	return append([]RequestOption{WithIdempotencyKey(newIdempotencyKey())}, opts...)
+}
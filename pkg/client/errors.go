@@ -0,0 +1,99 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// Error classes matched by *Error with errors.Is.
+var (
+	ErrInvalidRequest = errors.New("invalid request")       // Malformed or invalid request, see Error.Fields
+	ErrUndeliverable  = errors.New("undeliverable address") // Recipient address failed a deliverability check
+	ErrNotFound       = errors.New("not found")             // Email does not exist
+	ErrConflict       = errors.New("conflict")              // Status change not allowed or idempotency key reused
+	ErrUnprocessable  = errors.New("unprocessable")         // Recipient is suppressed or From is not verified
+	ErrRateLimited    = errors.New("rate limited")          // Too many requests
+	ErrUnauthorized   = errors.New("unauthorized")          // Missing or rejected credentials
+	ErrServer         = errors.New("server error")          // Server failed or timed out
+)
+
+// maxErrorBody limits how much of an error response is read.
+const maxErrorBody = 64 << 10
+
+// Error is an error response of the API.
+type Error struct {
+	StatusCode int          `json:"-"`                // HTTP status, 0 for errors of batch items
+	RequestID  string       `json:"-"`                // X-Request-ID of the failed request
+	Code       string       `json:"code"`             // Stable machine readable error code
+	Message    string       `json:"message"`          // Human readable description
+	Fields     []FieldError `json:"fields,omitempty"` // Invalid request fields
+}
+
+// FieldError describes a request field that failed validation.
+type FieldError struct {
+	Field   string `json:"field"`           // JSON path of the field, e.g. to_address or emails[0].subject
+	Rule    string `json:"rule"`            // Failed rule, e.g. required, max or type
+	Param   string `json:"param,omitempty"` // Constraint of the rule, e.g. 64 for max=64
+	Message string `json:"message"`         // Human readable description
+}
+
+func (e *Error) Error() string {
+	msg := e.Message
+	for _, f := range e.Fields {
+		msg += fmt.Sprintf("; %s %s", f.Field, f.Message)
+	}
+
+	if e.StatusCode == 0 {
+		return fmt.Sprintf("mailqusrv: %s: %s", e.Code, msg)
+	}
+
+	return fmt.Sprintf("mailqusrv: %d %s: %s", e.StatusCode, e.Code, msg)
+}
+
+// Is reports whether the error belongs to one of the error classes of the package.
+func (e *Error) Is(target error) bool {
+	switch e.Code {
+	case "invalid_json", "validation_failed", "bad_request", "request_too_large", "unsupported_media_type":
+		return target == ErrInvalidRequest
+	case "undeliverable_address":
+		return target == ErrUndeliverable
+	case "not_found":
+		return target == ErrNotFound
+	case "conflict":
+		return target == ErrConflict
+	case "unprocessable":
+		return target == ErrUnprocessable
+	case "timeout", "internal":
+		return target == ErrServer
+	case "unauthorized", "forbidden":
+		return target == ErrUnauthorized
+	}
+
+	switch {
+	case e.StatusCode == http.StatusTooManyRequests:
+		return target == ErrRateLimited
+	case e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden:
+		return target == ErrUnauthorized
+	case e.StatusCode >= http.StatusInternalServerError:
+		return target == ErrServer
+	default:
+		return false
+	}
+}
+
+// newError reads the error response, bodies of proxies that are not in the API format
+// are reported with the status text.
+func newError(resp *http.Response) *Error {
+	e := &Error{StatusCode: resp.StatusCode, RequestID: resp.Header.Get("X-Request-ID")}
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	if err := json.Unmarshal(body, e); err != nil || e.Code == "" {
+		e.Code = ""
+		e.Message = http.StatusText(resp.StatusCode)
+	}
+
+	return e
+}
@@ -0,0 +1,42 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// Suppress adds an address to the suppression list.
+func (c *Client) Suppress(ctx context.Context, s CreateSuppression, opts ...RequestOption) (Suppression, error) {
+	var out Suppression
+	err := c.do(ctx, http.MethodPost, "/suppressions", s, &out, opts)
+
+	return out, err
+}
+
+// GetSuppression returns the suppression with the given ID.
+func (c *Client) GetSuppression(ctx context.Context, id int, opts ...RequestOption) (Suppression, error) {
+	var out Suppression
+	err := c.do(ctx, http.MethodGet, "/suppressions/"+strconv.Itoa(id), nil, &out, opts)
+
+	return out, err
+}
+
+// ListSuppressions returns a page of suppressions with an ID greater than cursor, ordered by ID.
+func (c *Client) ListSuppressions(ctx context.Context, cursor int, opts ...RequestOption) ([]Suppression, error) {
+	path := "/suppressions"
+	if cursor > 0 {
+		path += "?" + url.Values{"cursor": {strconv.Itoa(cursor)}}.Encode()
+	}
+
+	var out []Suppression
+	err := c.do(ctx, http.MethodGet, path, nil, &out, opts)
+
+	return out, err
+}
+
+// DeleteSuppression removes an address from the suppression list.
+func (c *Client) DeleteSuppression(ctx context.Context, id int, opts ...RequestOption) error {
+	return c.do(ctx, http.MethodDelete, "/suppressions/"+strconv.Itoa(id), nil, nil, opts)
+}
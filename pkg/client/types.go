@@ -0,0 +1,117 @@
+package client
+
+import "time"
+
+// Status is the delivery status of an email.
+type Status string
+
+// Email statuses.
+const (
+	Bounced    Status = "bounced"    // Email was returned by the recipient's mail server
+	Cancelled  Status = "cancelled"  // Email was cancelled by an operator
+	Dead       Status = "dead"       // Email exhausted all delivery attempts
+	Failed     Status = "failed"     // Email delivery failed
+	Pending    Status = "pending"    // Email is waiting to be processed
+	Processing Status = "processing" // Email is currently being processed
+	Rejected   Status = "rejected"   // Email was permanently rejected by the provider
+	Sent       Status = "sent"       // Email was successfully sent
+	Suppressed Status = "suppressed" // Email was not sent because the recipient is suppressed
+)
+
+// Email is a queued email.
+type Email struct {
+	ID           int    `json:"id"`            // Unique identifier
+	To           string `json:"to_address"`    // Recipient email address
+	Subject      string `json:"subject"`       // Email subject
+	Body         string `json:"body"`          // Plain text body
+	Status       Status `json:"status"`        // Current status of the email
+	Attempts     int    `json:"attempts"`      // Number of delivery attempts
+	MessageID    string `json:"message_id"`    // Message-ID header value
+	Provider     string `json:"provider"`      // Provider to deliver through, empty means routing
+	SentProvider string `json:"sent_provider"` // Provider that accepted the email
+	From         string `json:"from_address"`  // From address
+	FromName     string `json:"from_name"`     // Display name of the From header
+	ReplyTo      string `json:"reply_to"`      // Reply-To address
+	HTML         string `json:"html"`          // HTML body
+	Track        bool   `json:"track"`         // Whether opens and clicks of the HTML body are tracked
+	Category     string `json:"category"`      // List or category of a bulk email
+	RequestID    string `json:"request_id"`    // X-Request-ID of the request that created the email
+}
+
+// CreateEmail is an email to queue.
+type CreateEmail struct {
+	To       string `json:"to_address"`         // Recipient email address
+	Subject  string `json:"subject"`            // Email subject
+	Body     string `json:"body"`               // Plain text body
+	Provider string `json:"provider,omitempty"` // Provider to deliver through, empty means routing
+	From     string `json:"from,omitempty"`     // Verified identity to send from, empty means the default one
+	HTML     string `json:"html,omitempty"`     // Optional HTML body
+	Track    bool   `json:"track,omitempty"`    // Track opens and clicks of the HTML body
+	Category string `json:"category,omitempty"` // List or category of a bulk email, enables one-click unsubscribe
+}
+
+// EmailFilter selects emails for bulk operations. Empty fields are ignored, at least one must be set.
+type EmailFilter struct {
+	IDs    []int  `json:"ids,omitempty"`        // Email identifiers
+	Status Status `json:"status,omitempty"`     // Current status
+	To     string `json:"to_address,omitempty"` // Recipient email address
+}
+
+// BatchResult is the outcome of queueing one email of a batch, either the email or the error.
+type BatchResult struct {
+	Email *Email `json:"email"` // Queued email
+	Error *Error `json:"error"` // Reason the email was not queued
+}
+
+// Stats aggregates email statuses and engagement.
+type Stats struct {
+	Statuses     map[Status]int64 `json:"statuses"`      // Number of emails per status
+	Opens        int64            `json:"opens"`         // Total number of opens
+	UniqueOpens  int64            `json:"unique_opens"`  // Number of emails opened at least once
+	Clicks       int64            `json:"clicks"`        // Total number of clicks
+	UniqueClicks int64            `json:"unique_clicks"` // Number of emails with at least one click
+}
+
+// SuppressionReason explains why an address is suppressed.
+type SuppressionReason string
+
+// Suppression reasons.
+const (
+	Bounce      SuppressionReason = "bounce"      // Address hard-bounced
+	Complaint   SuppressionReason = "complaint"   // Recipient marked a message as spam
+	Unsubscribe SuppressionReason = "unsubscribe" // Recipient unsubscribed
+	Manual      SuppressionReason = "manual"      // Address was added by an operator
+)
+
+// Suppression is an address that must not receive emails.
+type Suppression struct {
+	ID        int               `json:"id"`         // Unique identifier
+	Address   string            `json:"address"`    // Suppressed email address
+	Reason    SuppressionReason `json:"reason"`     // Why the address is suppressed
+	ExpiresAt *time.Time        `json:"expires_at"` // When the suppression ends, nil means never
+	Category  string            `json:"category"`   // List or category the address is suppressed for, empty means all
+	CreatedAt time.Time         `json:"created_at"` // When the address was suppressed
+}
+
+// CreateSuppression is an address to suppress.
+type CreateSuppression struct {
+	Address   string            `json:"address"`              // Email address to suppress
+	Reason    SuppressionReason `json:"reason"`               // Why the address is suppressed
+	ExpiresAt *time.Time        `json:"expires_at,omitempty"` // When the suppression ends, nil means never
+	Category  string            `json:"category,omitempty"`   // List or category to suppress the address for
+}
+
+// batchRequest is the request body of batch sends.
+type batchRequest struct {
+	Emails []CreateEmail `json:"emails"`
+}
+
+// batchResponse is the response body of batch sends.
+type batchResponse struct {
+	Results []BatchResult `json:"results"`
+}
+
+// bulkResult is the response body of bulk operations.
+type bulkResult struct {
+	Updated int64 `json:"updated"`
+}